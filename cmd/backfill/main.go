@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/marketdata"
+	"github.com/joho/godotenv"
+)
+
+// main baixa da Binance o histórico de candles dos pares informados e o grava no banco.
+//
+// Exemplos:
+//
+//	go run ./cmd/backfill -symbols BTCUSDT -interval 1h -from 2024-01-01
+//	go run ./cmd/backfill -symbols BTCUSDT,ETHUSDT -interval 15m -from 2024-01-01 -to 2024-07-01
+//
+// Apenas os períodos ainda sem candles no banco são baixados; ao final, os
+// períodos que continuaram sem candles (ex: manutenção da corretora) são listados.
+// O banco e a URL da API usam as mesmas variáveis do bot (DB_*, API_URL),
+// lidas do ambiente ou do arquivo .env; as credenciais não são necessárias.
+func main() {
+	// O arquivo .env é opcional; as variáveis podem vir do ambiente (ex: Docker)
+	_ = godotenv.Load()
+
+	symbols := flag.String("symbols", os.Getenv("SYMBOLS"), "pares separados por vírgula (padrão: SYMBOLS)")
+	interval := flag.String("interval", envOr("INTERVAL", "15m"), "intervalo dos candles (padrão: INTERVAL)")
+	from := flag.String("from", "", "data inicial em UTC (AAAA-MM-DD)")
+	to := flag.String("to", "", "data final em UTC, exclusiva (AAAA-MM-DD; padrão: agora)")
+	flag.Parse()
+
+	if *symbols == "" || *from == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if !exchange.ValidInterval(*interval) {
+		log.Fatalf("Intervalo inválido: %s", *interval)
+	}
+	start, err := time.Parse(time.DateOnly, *from)
+	if err != nil {
+		log.Fatal("Data inicial inválida:", err)
+	}
+	// Candles ainda em formação não são gravados, então o período termina no máximo agora
+	end := time.Now().UTC()
+	if *to != "" {
+		date, err := time.Parse(time.DateOnly, *to)
+		if err != nil {
+			log.Fatal("Data final inválida:", err)
+		}
+		if date.Before(end) {
+			end = date
+		}
+	}
+	if !start.Before(end) {
+		log.Fatal("A data inicial deve ser anterior à data final")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store, err := database.Initialize(ctx)
+	if err != nil {
+		log.Fatal("Erro ao inicializar banco de dados:", err)
+	}
+	defer store.Close()
+
+	ex := exchange.NewBinance(envOr("API_URL", "https://api.binance.com"), "", "")
+	for _, symbol := range strings.Split(*symbols, ",") {
+		symbol = strings.TrimSpace(symbol)
+		result, err := marketdata.Backfill(ctx, ex, store, symbol, *interval, start, end, os.Stdout)
+		if err != nil {
+			log.Fatalf("Erro ao baixar o histórico de %s: %v", symbol, err)
+		}
+
+		fmt.Printf("%s: %d candles gravados em %d requisições\n", symbol, result.Saved, result.Requests)
+		for _, gap := range result.Gaps {
+			fmt.Printf("%s: sem candles de %s a %s\n", symbol, gap.Start.Format(time.DateTime), gap.End.Format(time.DateTime))
+		}
+	}
+}
+
+// envOr retorna a variável de ambiente key, ou fallback se ela não estiver definida.
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
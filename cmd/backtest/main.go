@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/backtest"
+	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/marketdata"
+	"github.com/brunossouza/crypto_bot/internal/strategy"
+	"github.com/joho/godotenv"
+)
+
+// main executa um backtest offline de uma estratégia registrada sobre candles em CSV
+// ou sobre o histórico gravado no banco (ver cmd/backfill).
+//
+// Exemplos:
+//
+//	go run ./cmd/backtest -csv BTCUSDT-15m-2024-01.csv -strategy combined -params "rsi_period=14,sma_period=20"
+//	go run ./cmd/backtest -symbol BTCUSDT -interval 15m -from 2024-01-01 -to 2024-07-01
+//
+// O resultado exibe as operações simuladas e um resumo. Com -equity, a curva
+// de patrimônio é gravada em um arquivo CSV para análise externa. A quantidade
+// de candles avaliados a cada barra tem como padrão a mesma configuração LOOKBACK
+// do bot, lida do ambiente ou do arquivo .env.
+func main() {
+	// O arquivo .env é opcional; as variáveis podem vir do ambiente (ex: Docker)
+	_ = godotenv.Load()
+
+	defaultLookback, err := config.LoadLookback()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	csvPath := flag.String("csv", "", "arquivo CSV com os candles (formato da Binance)")
+	strategyName := flag.String("strategy", "combined", "estratégia registrada ("+strings.Join(strategy.Names(), ", ")+")")
+	strategyParams := flag.String("params", "", "parâmetros da estratégia no formato chave=valor,chave=valor")
+	lookback := flag.Int("lookback", defaultLookback, "quantidade de candles avaliados a cada barra (padrão: LOOKBACK)")
+	balance := flag.Float64("balance", 1000, "saldo inicial no ativo de cotação")
+	quantity := flag.Float64("qty", 0.001, "quantidade do ativo base por ordem")
+	fee := flag.Float64("fee", 0.1, "taxa por execução em porcentagem")
+	equityPath := flag.String("equity", "", "arquivo CSV de saída para a curva de patrimônio")
+	symbol := flag.String("symbol", "", "par lido do histórico no banco, quando -csv não é informado")
+	interval := flag.String("interval", "15m", "intervalo dos candles lidos do banco")
+	from := flag.String("from", "", "data inicial em UTC dos candles lidos do banco (AAAA-MM-DD)")
+	to := flag.String("to", "", "data final em UTC, exclusiva, dos candles lidos do banco (AAAA-MM-DD; padrão: agora)")
+	flag.Parse()
+
+	var candles []exchange.Candlestick
+	switch {
+	case *csvPath != "":
+		candles, err = backtest.LoadCSVFile(*csvPath)
+	case *symbol != "":
+		candles, err = loadStored(*symbol, *interval, *from, *to)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal("Erro ao carregar candles:", err)
+	}
+
+	params, err := strategy.ParseParams(*strategyParams)
+	if err != nil {
+		log.Fatal("Erro ao ler parâmetros:", err)
+	}
+	s, err := strategy.New(*strategyName, params)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *lookback < s.MinBars() {
+		log.Fatalf("lookback %d menor que o mínimo de %d candles exigido pela estratégia", *lookback, s.MinBars())
+	}
+
+	result, err := backtest.Run(candles, s, backtest.Config{
+		Lookback:       *lookback,
+		InitialBalance: *balance,
+		Quantity:       *quantity,
+		FeePercent:     *fee,
+	})
+	if err != nil {
+		log.Fatal("Erro ao executar backtest:", err)
+	}
+
+	printResult(result, *balance)
+
+	if *equityPath != "" {
+		if err := writeEquity(*equityPath, result.Equity); err != nil {
+			log.Fatal("Erro ao gravar curva de patrimônio:", err)
+		}
+		fmt.Println("Curva de patrimônio gravada em", *equityPath)
+	}
+}
+
+// loadStored lê do banco o histórico de candles do par entre as datas from e to
+// (AAAA-MM-DD, em UTC). A conexão usa as mesmas variáveis DB_* do bot, lidas do
+// ambiente ou do arquivo .env.
+func loadStored(symbol, interval, from, to string) ([]exchange.Candlestick, error) {
+	start := time.Unix(0, 0)
+	if from != "" {
+		var err error
+		if start, err = time.Parse(time.DateOnly, from); err != nil {
+			return nil, fmt.Errorf("data inicial inválida: %w", err)
+		}
+	}
+	end := time.Now()
+	if to != "" {
+		var err error
+		if end, err = time.Parse(time.DateOnly, to); err != nil {
+			return nil, fmt.Errorf("data final inválida: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	store, err := database.Initialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	records, err := store.GetCandles(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("nenhum candle de %s %s no banco; use o comando backfill", symbol, interval)
+	}
+	return marketdata.Candlesticks(records), nil
+}
+
+// printResult exibe as operações e o resumo da simulação.
+func printResult(result *backtest.Result, initialBalance float64) {
+	fmt.Println("Operações:")
+	for i, t := range result.Trades {
+		fmt.Printf("%3d  %s  compra %.2f  ->  %s  venda %.2f  qtd %.6f  PnL %.2f (%.2f%%)\n",
+			i+1, formatTime(t.EntryTime), t.EntryPrice, formatTime(t.ExitTime), t.ExitPrice,
+			t.Quantity, t.PnL, t.PnLPercent)
+	}
+	if result.OpenTrade != nil {
+		fmt.Printf("     %s  compra %.2f  (posição ainda aberta)\n",
+			formatTime(result.OpenTrade.EntryTime), result.OpenTrade.EntryPrice)
+	}
+
+	fmt.Println("")
+	fmt.Println("Candles avaliados:", result.BarsReplayed)
+	fmt.Println("Operações encerradas:", len(result.Trades))
+	fmt.Println("Compras ignoradas por saldo:", result.SkippedBuys)
+	fmt.Printf("Taxa de acerto: %.2f%%\n", result.WinRate)
+	fmt.Printf("Saldo inicial: %.2f\n", initialBalance)
+	fmt.Printf("Patrimônio final: %.2f\n", result.FinalEquity)
+	fmt.Printf("Retorno total: %.2f%%\n", result.TotalReturn)
+	fmt.Printf("Drawdown máximo: %.2f%%\n", result.MaxDrawdown)
+}
+
+// writeEquity grava a curva de patrimônio em CSV (time, equity).
+func writeEquity(path string, equity []backtest.EquityPoint) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"time", "equity"})
+	for _, p := range equity {
+		w.Write([]string{
+			strconv.FormatInt(p.Time, 10),
+			strconv.FormatFloat(p.Equity, 'f', 8, 64),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// formatTime converte um horário em milissegundos para texto em UTC.
+func formatTime(ms int64) string {
+	return time.UnixMilli(ms).UTC().Format("2006-01-02 15:04")
+}
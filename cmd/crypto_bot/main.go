@@ -1,47 +1,142 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
 	"github.com/brunossouza/crypto_bot/internal/trading"
+	"github.com/shopspring/decimal"
 )
 
 // main é o ponto de entrada principal do bot de criptomoedas.
 // Responsabilidades:
 // - Carregar as configurações do sistema através do arquivo .env
 // - Inicializar o módulo de trading com as configurações carregadas
-// - Configurar um temporizador para execução periódica das operações
-// - Manter o bot em execução contínua até ser manualmente interrompido
+// - Agendar as operações para logo após o fechamento de cada candle
+// - Manter o bot em execução contínua até receber SIGINT (CTRL+C) ou SIGTERM (docker stop)
+// - Encerrar de forma limpa, concluindo a avaliação em andamento e fechando o banco
 //
 // Fluxo de execução:
 // 1. Carrega as configurações do arquivo .env
-// 2. Inicializa o módulo de trading
-// 3. Configura um temporizador de 10 segundos
-// 4. Executa a primeira operação de trading
-// 5. Entra em loop infinito, executando operações a cada 10 segundos
+// 2. Inicializa o módulo de trading com o cliente da Binance (ou o simulador no modo paper)
+// 3. Com MARKET_DATA=stream, conecta os streams de klines de cada par
+// 4. No modo live, conecta o user data stream para acompanhar ordens e saldos em tempo real
+// 5. Executa a primeira operação de trading sobre o último candle fechado
+// 6. Entra em loop, executando operações a cada fechamento de candle de INTERVAL, informado pelo stream ou pelo relógio
+// 7. Ao receber um sinal de encerramento, aguarda a avaliação em andamento, sai do loop e fecha o banco
 //
 // Em caso de erro na carga das configurações, o programa é encerrado com log.Fatal
 func main() {
+	// O contexto é cancelado no primeiro SIGINT/SIGTERM; um segundo sinal encerra o processo imediatamente
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	conf, err := config.LoadConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	// Inicializa o pacote de trading com as configurações
-	trading.Initialize(conf)
+	// No modo paper as ordens são simuladas sobre os dados reais da Binance
+	binance := exchange.NewBinance(conf.ApiURL, conf.ApiKey, conf.ApiSecret)
+	binance.RecvWindow = conf.RecvWindow
+	var ex exchange.Exchange = binance
+	if conf.Mode == config.ModePaper {
+		fmt.Println("Modo paper: as ordens serão simuladas")
+		ex = exchange.NewPaper(ex, exchange.PaperConfig{
+			FeePercent:      conf.PaperFeePercent,
+			SlippagePercent: conf.PaperSlippagePercent,
+			QuoteBalance:    decimal.NewFromFloat(conf.PaperQuoteBalance),
+			BaseBalance:     decimal.NewFromFloat(conf.PaperBaseBalance),
+		})
+	}
+
+	// Valida e arredonda as ordens conforme os filtros do par, com as regras em cache por 1 hora
+	ex = exchange.NewFilteredExchange(ex, time.Hour)
 
-	// Cria um temporizador que dispara a cada 10 segundos
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
+	// Abre o banco selecionado em DB_DRIVER e aplica as migrações pendentes
+	store, err := database.Initialize(ctx)
+	if err != nil {
+		log.Fatal("Erro ao inicializar banco de dados:", err)
+	}
+	defer store.Close()
+
+	// Inicializa o pacote de trading com as configurações, a corretora e o banco
+	trading.Initialize(ctx, conf, ex, store)
+
+	// Com o stream de klines, a avaliação começa assim que o candle fecha; sem ele,
+	// alguns segundos após o fechamento, para a corretora consolidar o candle
+	var closes <-chan time.Time
+	delay := candleCloseDelay
+	if conf.MarketData == config.MarketDataStream {
+		closes = trading.StartStreams(ctx, conf.StreamURL)
+		delay = streamFallbackDelay
+	}
+
+	// No modo live, as execuções de ordens e as alterações de saldo são aplicadas ao
+	// banco assim que informadas pelo user data stream, inclusive as de ordens manuais
+	if conf.Mode == config.ModeLive {
+		userStream := exchange.NewUserStream(conf.StreamURL, binance)
+		go userStream.Run(ctx)
+		go trading.HandleUserEvents(ctx, userStream.Events())
+	}
 
 	fmt.Println("Bot iniciado! Pressione CTRL+C para parar")
-	trading.StartTrading()
+	trading.StartTrading(ctx)
+
+	// Executa até o programa ser interrompido
+	for {
+		next, err := exchange.NextCandleClose(conf.Interval, time.Now())
+		if err != nil {
+			log.Print(err)
+			return
+		}
+
+		if !waitCandleClose(ctx, next, next.Add(delay), closes) {
+			fmt.Println("Sinal de encerramento recebido, finalizando...")
+			return
+		}
+
+		// StartTrading só retorna após registrar as ordens enviadas, mesmo se ctx for cancelado
+		trading.StartTrading(ctx)
+		if ctx.Err() != nil {
+			fmt.Println("Sinal de encerramento recebido, finalizando...")
+			return
+		}
+	}
+}
+
+// candleCloseDelay é a espera após o fechamento do candle antes de avaliá-lo
+// quando os candles são obtidos pela API REST.
+const candleCloseDelay = 2 * time.Second
+
+// streamFallbackDelay é a espera após o fechamento do candle pelo sinal do stream
+// de klines; sem o sinal (ex: stream desconectado), o candle é avaliado pela API REST.
+const streamFallbackDelay = 10 * time.Second
 
-	// Executa indefinidamente até o programa ser interrompido
-	for range ticker.C {
-		trading.StartTrading()
+// waitCandleClose aguarda o sinal em closes do fechamento do candle que fecha em next,
+// ou até deadline. Sinais de candles anteriores, já avaliados após deadline, são ignorados;
+// closes pode ser nil. Retorna false se ctx for cancelado antes.
+func waitCandleClose(ctx context.Context, next, deadline time.Time, closes <-chan time.Time) bool {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-timer.C:
+			return true
+		case closedAt := <-closes:
+			if !closedAt.Before(next) {
+				return true
+			}
+		}
 	}
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/joho/godotenv"
+)
+
+// main aplica, desfaz ou lista as migrações do esquema do banco de dados.
+//
+// Exemplos:
+//
+//	go run ./cmd/migrate up            # aplica as migrações pendentes
+//	go run ./cmd/migrate down          # desfaz a última migração aplicada
+//	go run ./cmd/migrate -steps 2 down # desfaz as duas últimas migrações
+//	go run ./cmd/migrate status        # lista as migrações e se estão aplicadas
+//
+// A conexão usa as mesmas variáveis DB_* do bot (incluindo DB_DRIVER), lidas do
+// ambiente ou do arquivo .env. O bot também aplica as migrações pendentes ao iniciar.
+func main() {
+	steps := flag.Int("steps", 1, "quantidade de migrações desfeitas pelo comando down")
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), "Uso: migrate [-steps N] up|down|status")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 || *steps < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// O arquivo .env é opcional; as variáveis podem vir do ambiente (ex: Docker)
+	_ = godotenv.Load()
+
+	store, err := database.Open(ctx)
+	if err != nil {
+		log.Fatal("Erro ao conectar ao banco de dados:", err)
+	}
+	defer store.Close()
+
+	migrator, ok := store.(database.Migrator)
+	if !ok {
+		log.Fatalf("O driver %s não possui esquema para migrar", os.Getenv("DB_DRIVER"))
+	}
+
+	switch command := flag.Arg(0); command {
+	case "up":
+		applied, err := migrator.MigrateUp(ctx)
+		printMigrations("Aplicada", applied)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Nenhuma migração pendente")
+		}
+	case "down":
+		reverted, err := migrator.MigrateDown(ctx, *steps)
+		printMigrations("Desfeita", reverted)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Nenhuma migração aplicada")
+		}
+	case "status":
+		states, err := migrator.MigrationStatus(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, s := range states {
+			status := "pendente"
+			if s.Applied {
+				status = "aplicada em " + s.AppliedAt.Format(time.DateTime)
+			}
+			fmt.Printf("%04d_%-30s %s\n", s.Version, s.Name, status)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Comando desconhecido: %s\n", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// printMigrations exibe as migrações processadas precedidas de action.
+func printMigrations(action string, migrations []database.Migration) {
+	for _, m := range migrations {
+		fmt.Printf("%s: %04d_%s\n", action, m.Version, m.Name)
+	}
+}
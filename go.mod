@@ -5,3 +5,9 @@ go 1.23.6
 require github.com/joho/godotenv v1.5.1
 
 require github.com/lib/pq v1.10.9
+
+require github.com/shopspring/decimal v1.4.0
+
+require github.com/mattn/go-sqlite3 v1.14.24
+
+require github.com/coder/websocket v1.8.12
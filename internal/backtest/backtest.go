@@ -0,0 +1,173 @@
+package backtest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// Strategy é o conjunto mínimo de decisões que o backtest precisa da estratégia.
+// Qualquer strategy.Strategy satisfaz esta interface.
+type Strategy interface {
+	ShouldEnter(prices []float64) (bool, error)
+	ShouldExit(prices []float64) (bool, error)
+}
+
+// Config contém os parâmetros da simulação.
+type Config struct {
+	// Lookback é a quantidade de candles passados à estratégia a cada barra,
+	// equivalente à configuração LOOKBACK usada pelo bot.
+	Lookback int
+	// InitialBalance é o saldo inicial no ativo de cotação (ex: USDT).
+	InitialBalance float64
+	// Quantity é a quantidade do ativo base negociada em cada ordem.
+	Quantity float64
+	// FeePercent é a taxa cobrada sobre o valor de cada execução, em porcentagem.
+	FeePercent float64
+}
+
+// Trade representa uma operação completa (compra seguida de venda).
+type Trade struct {
+	EntryTime  int64   // Horário de fechamento do candle da compra em milissegundos
+	EntryPrice float64 // Preço de execução da compra
+	ExitTime   int64   // Horário de fechamento do candle da venda em milissegundos
+	ExitPrice  float64 // Preço de execução da venda
+	Quantity   float64 // Quantidade negociada
+	Fees       float64 // Taxas pagas na compra e na venda
+	PnL        float64 // Resultado líquido da operação no ativo de cotação
+	PnLPercent float64 // Resultado líquido em porcentagem do valor de entrada
+}
+
+// EquityPoint representa o patrimônio da conta ao final de um candle.
+type EquityPoint struct {
+	Time   int64   // Horário de fechamento do candle em milissegundos
+	Equity float64 // Saldo em cotação somado à posição avaliada pelo fechamento
+}
+
+// Result contém o resultado de uma simulação.
+type Result struct {
+	Trades       []Trade       // Operações encerradas, em ordem cronológica
+	Equity       []EquityPoint // Curva de patrimônio, um ponto por candle simulado
+	OpenTrade    *Trade        // Operação ainda aberta ao final dos dados, se houver
+	FinalEquity  float64       // Patrimônio ao final da simulação
+	TotalReturn  float64       // Retorno total em porcentagem do saldo inicial
+	MaxDrawdown  float64       // Maior queda percentual a partir de um topo da curva
+	WinRate      float64       // Porcentagem de operações com resultado positivo
+	SkippedBuys  int           // Sinais de compra ignorados por falta de saldo
+	BarsReplayed int           // Quantidade de candles em que a estratégia foi avaliada
+}
+
+// Run reproduz os candles barra a barra e simula as ordens geradas pela estratégia
+// Para cada candle a partir de Lookback, a estratégia recebe os preços de fechamento
+// dos últimos Lookback candles, exatamente como StartTrading faz com os dados da API:
+//   - Compra quando ShouldEnter retorna true e não há posição aberta
+//   - Vende quando ShouldExit retorna true e há posição aberta
+//
+// As execuções ocorrem no preço de fechamento do candle avaliado, com a taxa
+// FeePercent aplicada sobre o valor negociado.
+//
+// Retorna erro se os parâmetros forem inválidos, se não houver candles suficientes
+// ou se a estratégia falhar em algum candle.
+func Run(candles []exchange.Candlestick, s Strategy, cfg Config) (*Result, error) {
+	if cfg.Lookback <= 0 {
+		return nil, errors.New("lookback deve ser maior que zero")
+	}
+	if cfg.Quantity <= 0 {
+		return nil, errors.New("quantidade deve ser maior que zero")
+	}
+	if len(candles) < cfg.Lookback {
+		return nil, fmt.Errorf("candles insuficientes: %d disponíveis, %d necessários", len(candles), cfg.Lookback)
+	}
+
+	result := &Result{}
+	cash := cfg.InitialBalance
+	fee := cfg.FeePercent / 100
+	var open *Trade
+	peak := cfg.InitialBalance
+
+	prices := make([]float64, len(candles))
+	for i, c := range candles {
+		prices[i] = c.Close
+	}
+
+	for i := cfg.Lookback - 1; i < len(candles); i++ {
+		window := prices[i+1-cfg.Lookback : i+1]
+		candle := candles[i]
+		price := candle.Close
+		result.BarsReplayed++
+
+		if open == nil {
+			enter, err := s.ShouldEnter(window)
+			if err != nil {
+				return nil, fmt.Errorf("estratégia falhou no candle %d: %w", i, err)
+			}
+			if enter {
+				cost := price * cfg.Quantity
+				entryFee := cost * fee
+				if cost+entryFee <= cash {
+					cash -= cost + entryFee
+					open = &Trade{
+						EntryTime:  candle.CloseTime,
+						EntryPrice: price,
+						Quantity:   cfg.Quantity,
+						Fees:       entryFee,
+					}
+				} else {
+					result.SkippedBuys++
+				}
+			}
+		} else {
+			exit, err := s.ShouldExit(window)
+			if err != nil {
+				return nil, fmt.Errorf("estratégia falhou no candle %d: %w", i, err)
+			}
+			if exit {
+				proceeds := price * open.Quantity
+				exitFee := proceeds * fee
+				cash += proceeds - exitFee
+
+				open.ExitTime = candle.CloseTime
+				open.ExitPrice = price
+				open.Fees += exitFee
+				open.PnL = proceeds - open.EntryPrice*open.Quantity - open.Fees
+				open.PnLPercent = open.PnL / (open.EntryPrice * open.Quantity) * 100
+				result.Trades = append(result.Trades, *open)
+				open = nil
+			}
+		}
+
+		equity := cash
+		if open != nil {
+			equity += price * open.Quantity
+		}
+		result.Equity = append(result.Equity, EquityPoint{Time: candle.CloseTime, Equity: equity})
+
+		if equity > peak {
+			peak = equity
+		}
+		if peak > 0 {
+			if dd := (peak - equity) / peak * 100; dd > result.MaxDrawdown {
+				result.MaxDrawdown = dd
+			}
+		}
+	}
+
+	result.OpenTrade = open
+	result.FinalEquity = result.Equity[len(result.Equity)-1].Equity
+	if cfg.InitialBalance > 0 {
+		result.TotalReturn = (result.FinalEquity - cfg.InitialBalance) / cfg.InitialBalance * 100
+	}
+
+	wins := 0
+	for _, t := range result.Trades {
+		if t.PnL > 0 {
+			wins++
+		}
+	}
+	if len(result.Trades) > 0 {
+		result.WinRate = float64(wins) / float64(len(result.Trades)) * 100
+	}
+
+	return result, nil
+}
@@ -0,0 +1,152 @@
+package backtest
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// thresholdStrategy compra abaixo de buyBelow e vende acima de sellAbove.
+type thresholdStrategy struct {
+	buyBelow  float64
+	sellAbove float64
+	calls     []int
+}
+
+func (s *thresholdStrategy) ShouldEnter(prices []float64) (bool, error) {
+	s.calls = append(s.calls, len(prices))
+	return prices[len(prices)-1] < s.buyBelow, nil
+}
+
+func (s *thresholdStrategy) ShouldExit(prices []float64) (bool, error) {
+	return prices[len(prices)-1] > s.sellAbove, nil
+}
+
+func makeCandles(closes ...float64) []exchange.Candlestick {
+	candles := make([]exchange.Candlestick, len(closes))
+	for i, c := range closes {
+		candles[i] = exchange.Candlestick{
+			OpenTime:  int64(i) * 1000,
+			CloseTime: int64(i)*1000 + 999,
+			Close:     c,
+		}
+	}
+	return candles
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		closes      []float64
+		cfg         Config
+		wantTrades  int
+		wantPnL     float64
+		wantEquity  float64
+		wantOpen    bool
+		wantSkipped int
+	}{
+		{
+			name:       "Should open and close a trade without fees",
+			closes:     []float64{100, 100, 90, 95, 110, 100},
+			cfg:        Config{Lookback: 2, InitialBalance: 1000, Quantity: 1},
+			wantTrades: 1,
+			wantPnL:    20, // compra a 90, vende a 110
+			wantEquity: 1020,
+		},
+		{
+			name:       "Should discount fees from both legs",
+			closes:     []float64{100, 100, 90, 95, 110, 100},
+			cfg:        Config{Lookback: 2, InitialBalance: 1000, Quantity: 1, FeePercent: 1},
+			wantTrades: 1,
+			wantPnL:    20 - 0.9 - 1.1,
+			wantEquity: 1000 + 20 - 0.9 - 1.1,
+		},
+		{
+			name:       "Should keep position open at the end of data",
+			closes:     []float64{100, 100, 90, 95},
+			cfg:        Config{Lookback: 2, InitialBalance: 1000, Quantity: 1},
+			wantTrades: 0,
+			wantEquity: 1000 - 90 + 95,
+			wantOpen:   true,
+		},
+		{
+			name:        "Should skip buys without enough balance",
+			closes:      []float64{100, 100, 90, 95},
+			cfg:         Config{Lookback: 2, InitialBalance: 50, Quantity: 1},
+			wantTrades:  0,
+			wantEquity:  50,
+			wantSkipped: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &thresholdStrategy{buyBelow: 96, sellAbove: 105}
+			result, err := Run(makeCandles(tt.closes...), s, tt.cfg)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+
+			if len(result.Trades) != tt.wantTrades {
+				t.Fatalf("esperado %d operações, obteve %d", tt.wantTrades, len(result.Trades))
+			}
+			if tt.wantTrades > 0 && math.Abs(result.Trades[0].PnL-tt.wantPnL) > 1e-9 {
+				t.Errorf("PnL = %v, esperado %v", result.Trades[0].PnL, tt.wantPnL)
+			}
+			if math.Abs(result.FinalEquity-tt.wantEquity) > 1e-9 {
+				t.Errorf("patrimônio final = %v, esperado %v", result.FinalEquity, tt.wantEquity)
+			}
+			if (result.OpenTrade != nil) != tt.wantOpen {
+				t.Errorf("operação aberta = %v, esperado %v", result.OpenTrade != nil, tt.wantOpen)
+			}
+			if result.SkippedBuys != tt.wantSkipped {
+				t.Errorf("compras ignoradas = %d, esperado %d", result.SkippedBuys, tt.wantSkipped)
+			}
+			if len(result.Equity) != len(tt.closes)-tt.cfg.Lookback+1 {
+				t.Errorf("curva de patrimônio com %d pontos, esperado %d", len(result.Equity), len(tt.closes)-tt.cfg.Lookback+1)
+			}
+		})
+	}
+}
+
+func TestRun_WindowSize(t *testing.T) {
+	s := &thresholdStrategy{buyBelow: 0, sellAbove: math.MaxFloat64}
+	if _, err := Run(makeCandles(1, 2, 3, 4, 5), s, Config{Lookback: 3, Quantity: 1}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	for _, n := range s.calls {
+		if n != 3 {
+			t.Errorf("estratégia recebeu %d preços, esperado 3", n)
+		}
+	}
+}
+
+func TestRun_NotEnoughCandles(t *testing.T) {
+	s := &thresholdStrategy{}
+	if _, err := Run(makeCandles(1, 2), s, Config{Lookback: 3, Quantity: 1}); err == nil {
+		t.Error("esperado erro com candles insuficientes")
+	}
+}
+
+func TestLoadCSV(t *testing.T) {
+	data := `open_time,open,high,low,close,volume,close_time,quote_volume,count,taker_buy_volume,taker_buy_quote_volume,ignore
+1000,1.0,2.0,0.5,1.5,10.0,1999,15.0,3,5.0,7.5,0
+2000,1.5,2.5,1.0,2.0,20.0,2999,40.0,4,10.0,20.0,0
+`
+	candles, err := LoadCSV(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("esperado 2 candles, obteve %d", len(candles))
+	}
+	if candles[1].OpenTime != 2000 || candles[1].Close != 2.0 || candles[1].CloseTime != 2999 {
+		t.Errorf("candle convertido incorretamente: %+v", candles[1])
+	}
+
+	if _, err := LoadCSV(strings.NewReader("1000,abc,2,3,4,5,1999\n")); err == nil {
+		t.Error("esperado erro com valor inválido")
+	}
+}
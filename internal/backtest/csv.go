@@ -0,0 +1,119 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// LoadCSVFile abre o arquivo informado e carrega os candles através de LoadCSV.
+func LoadCSVFile(path string) ([]exchange.Candlestick, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return LoadCSV(f)
+}
+
+// LoadCSV lê candles no formato dos arquivos de dados históricos da Binance
+// (data.binance.vision), com as colunas na mesma ordem retornada por /api/v3/klines:
+// open_time, open, high, low, close, volume, close_time, quote_volume, trades,
+// taker_buy_base_volume, taker_buy_quote_volume, ignore
+//
+// Uma linha de cabeçalho é ignorada se a primeira coluna não for numérica.
+// As colunas a partir de quote_volume são opcionais.
+//
+// Retorna erro se alguma linha possuir menos de 7 colunas ou valores inválidos.
+func LoadCSV(r io.Reader) ([]exchange.Candlestick, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var candles []exchange.Candlestick
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line++
+
+		// Ignora o cabeçalho, se houver
+		if line == 1 {
+			if _, err := strconv.ParseInt(record[0], 10, 64); err != nil {
+				continue
+			}
+		}
+
+		candle, err := parseRecord(record)
+		if err != nil {
+			return nil, fmt.Errorf("linha %d: %w", line, err)
+		}
+		candles = append(candles, candle)
+	}
+
+	return candles, nil
+}
+
+// parseRecord converte uma linha do CSV em um Candlestick.
+func parseRecord(record []string) (exchange.Candlestick, error) {
+	var c exchange.Candlestick
+	if len(record) < 7 {
+		return c, fmt.Errorf("esperado ao menos 7 colunas, obteve %d", len(record))
+	}
+
+	ints := []struct {
+		idx int
+		dst *int64
+	}{
+		{0, &c.OpenTime},
+		{6, &c.CloseTime},
+		{8, &c.NumberOfTrades},
+	}
+	for _, f := range ints {
+		if f.idx >= len(record) {
+			continue
+		}
+		v, err := strconv.ParseInt(record[f.idx], 10, 64)
+		if err != nil {
+			return c, fmt.Errorf("coluna %d: %w", f.idx+1, err)
+		}
+		*f.dst = v
+	}
+
+	floats := []struct {
+		idx int
+		dst *float64
+	}{
+		{1, &c.Open},
+		{2, &c.High},
+		{3, &c.Low},
+		{4, &c.Close},
+		{5, &c.Volume},
+		{7, &c.QuoteAssetVolume},
+		{9, &c.TakerBuyBaseAssetVolume},
+		{10, &c.TakerBuyQuoteAssetVolume},
+		{11, &c.Ignore},
+	}
+	for _, f := range floats {
+		if f.idx >= len(record) {
+			continue
+		}
+		v, err := strconv.ParseFloat(record[f.idx], 64)
+		if err != nil {
+			return c, fmt.Errorf("coluna %d: %w", f.idx+1, err)
+		}
+		*f.dst = v
+	}
+
+	return c, nil
+}
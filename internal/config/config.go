@@ -5,7 +5,9 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/brunossouza/crypto_bot/internal/exchange"
 	"github.com/joho/godotenv"
 )
 
@@ -14,22 +16,105 @@ import (
 type Config struct {
 	// ApiURL é o endpoint base da API da Binance
 	ApiURL string
-	// Symbol é o par de criptomoedas para negociação (ex: BTCUSDT)
-	Symbol string
+	// Symbols são os pares de criptomoedas negociados (ex: BTCUSDT, ETHUSDT)
+	Symbols []string
 	// Period é o intervalo em minutos para análise do mercado
 	Period int
+	// Interval é o intervalo dos candles analisados (ex: 15m, 1h), nos valores aceitos pela Binance
+	Interval string
+	// Lookback é a quantidade de candles fechados passados à estratégia a cada avaliação
+	Lookback int
 	// ApiKey é a chave pública da API da Binance
 	ApiKey string
 	// ApiSecret é a chave privada da API da Binance
 	ApiSecret string
+	// Strategy é o nome da estratégia registrada a ser utilizada (ex: combined, rsi)
+	Strategy string
+	// StrategyParams são os parâmetros da estratégia no formato "chave=valor,chave=valor"
+	StrategyParams string
+	// StopLossPercent é a queda máxima a partir do preço de entrada antes de vender (0 desativa)
+	StopLossPercent float64
+	// TakeProfitPercent é a alta a partir do preço de entrada que encerra a posição (0 desativa)
+	TakeProfitPercent float64
+	// TrailingStopPercent é a queda máxima a partir da máxima desde a entrada (0 desativa)
+	TrailingStopPercent float64
+	// SizingMethod é o método de dimensionamento das compras (fixed_quote, percent_balance, fixed_risk)
+	SizingMethod string
+	// SizingValue é o valor em cotação (fixed_quote) ou a porcentagem do saldo (percent_balance, fixed_risk)
+	SizingValue float64
+	// Mode define se as ordens são enviadas à corretora (ModeLive) ou simuladas (ModePaper)
+	Mode string
+	// PaperFeePercent é a taxa simulada por execução no modo paper, em porcentagem
+	PaperFeePercent float64
+	// PaperSlippagePercent é o deslizamento simulado do preço no modo paper, em porcentagem
+	PaperSlippagePercent float64
+	// PaperQuoteBalance é o saldo inicial simulado do ativo de cotação (ex: USDT)
+	PaperQuoteBalance float64
+	// PaperBaseBalance é o saldo inicial simulado do ativo base (ex: BTC)
+	PaperBaseBalance float64
+	// MaxFailures é a quantidade de avaliações consecutivas com erro que pausa um par (0 nunca pausa)
+	MaxFailures int
+	// FailurePause é o tempo que um par fica pausado após MaxFailures falhas consecutivas
+	FailurePause time.Duration
+	// Reconcile define o tratamento das divergências entre o banco e a conta na corretora
+	// (ReconcileAuto, ReconcileHalt ou ReconcileOff)
+	Reconcile string
+	// ReconcileInterval é o intervalo entre reconciliações após a inicial (0 reconcilia apenas na inicialização)
+	ReconcileInterval time.Duration
+	// ReconcileAck são os pares cujas divergências encontradas na inicialização foram conferidas
+	// pelo operador e devem ser corrigidas mesmo com ReconcileHalt
+	ReconcileAck []string
+	// MarketData define a fonte dos candles: MarketDataStream (WebSocket) ou MarketDataPoll (REST)
+	MarketData string
+	// StreamURL é o endereço base dos streams WebSocket de mercado da Binance
+	StreamURL string
+	// RecvWindow é a validade das requisições assinadas na Binance, contada a partir do timestamp
+	RecvWindow time.Duration
 }
 
+// Modos de operação do bot.
+const (
+	// ModeLive envia as ordens para a corretora.
+	ModeLive = "live"
+	// ModePaper simula as ordens com dados reais de mercado, sem enviá-las.
+	ModePaper = "paper"
+)
+
+// Tratamentos das divergências encontradas na reconciliação com a corretora.
+const (
+	// ReconcileAuto corrige o banco a partir da conta na corretora.
+	ReconcileAuto = "auto"
+	// ReconcileHalt suspende a negociação do par até a confirmação do operador.
+	ReconcileHalt = "halt"
+	// ReconcileOff desativa a reconciliação.
+	ReconcileOff = "off"
+)
+
+// Fontes dos candles usados nas avaliações.
+const (
+	// MarketDataStream mantém os candles em memória a partir do stream de klines
+	// e avalia a estratégia assim que cada candle fecha.
+	MarketDataStream = "stream"
+	// MarketDataPoll obtém os candles pela API REST a cada fechamento de candle.
+	MarketDataPoll = "poll"
+)
+
 // LoadConfig carrega as configurações do arquivo .env e valida os valores obrigatórios.
 // O método verifica:
 // - Se o arquivo .env pode ser carregado
 // - Se as credenciais da API (BINANCE_API_KEY e BINANCE_API_SECRET) estão presentes
-// - Se os parâmetros básicos (API_URL, SYMBOL e PERIOD) estão configurados corretamente
+// - Se os parâmetros básicos (API_URL, SYMBOLS e PERIOD) estão configurados corretamente
+// - SYMBOL é aceito no lugar de SYMBOLS para configurações com um único par
 // - Se o valor de PERIOD é um número inteiro válido
+// - Se INTERVAL (padrão 15m) é um intervalo de kline aceito pela Binance
+// - Se LOOKBACK (padrão 100) está entre 1 e 999
+// - Se MODE é "live" (padrão) ou "paper"; no modo paper as credenciais são opcionais
+// - Se os parâmetros numéricos do modo paper e das regras de saída são válidos
+// - Se MAX_CONSECUTIVE_FAILURES (padrão 5) e FAILURE_PAUSE (padrão 1h) são válidos
+// - Se RECONCILE é "auto", "halt" ou "off" e se RECONCILE_INTERVAL é uma duração válida
+// - RECONCILE tem padrão "halt" no modo live e "auto" no modo paper, cujo saldo simulado não sobrevive a reinícios
+// - Se MARKET_DATA é "stream" (padrão) ou "poll"; STREAM_URL tem padrão wss://stream.binance.com:9443
+// - Se RECV_WINDOW (padrão 5000) está entre 1 e 60000 milissegundos
 //
 // Retorna:
 // - Um ponteiro para Config com as configurações carregadas
@@ -44,24 +129,139 @@ func LoadConfig() (*Config, error) {
 
 	conf := &Config{
 		ApiURL:    os.Getenv("API_URL"),
-		Symbol:    os.Getenv("SYMBOL"),
 		ApiKey:    os.Getenv("BINANCE_API_KEY"),
 		ApiSecret: os.Getenv("BINANCE_API_SECRET"),
+		Interval:  os.Getenv("INTERVAL"),
+		Mode:      strings.ToLower(os.Getenv("MODE")),
+
+		Strategy:       strings.ToLower(os.Getenv("STRATEGY")),
+		StrategyParams: os.Getenv("STRATEGY_PARAMS"),
+		SizingMethod:   strings.ToLower(os.Getenv("SIZING_METHOD")),
+
+		MarketData: strings.ToLower(os.Getenv("MARKET_DATA")),
+		StreamURL:  os.Getenv("STREAM_URL"),
+	}
+	if conf.Strategy == "" {
+		conf.Strategy = "combined"
+	}
+	if conf.Interval == "" {
+		conf.Interval = "15m"
+	}
+	if conf.SizingMethod == "" {
+		conf.SizingMethod = "fixed_quote"
+	}
+	if conf.Mode == "" {
+		conf.Mode = ModeLive
+	}
+	if conf.MarketData == "" {
+		conf.MarketData = MarketDataStream
+	}
+	if conf.StreamURL == "" {
+		conf.StreamURL = "wss://stream.binance.com:9443"
 	}
 
 	var missingVars []string
+	var invalidVars []string
 	if conf.ApiURL == "" {
 		missingVars = append(missingVars, "API_URL")
 	}
-	if conf.Symbol == "" {
-		missingVars = append(missingVars, "SYMBOL")
+	conf.Symbols = parseSymbols(os.Getenv("SYMBOLS"))
+	if len(conf.Symbols) == 0 {
+		conf.Symbols = parseSymbols(os.Getenv("SYMBOL"))
+	}
+	if len(conf.Symbols) == 0 {
+		missingVars = append(missingVars, "SYMBOLS")
 	}
-	if conf.ApiKey == "" {
+	// No modo paper nenhuma requisição assinada é enviada, então as credenciais são opcionais
+	if conf.ApiKey == "" && conf.Mode != ModePaper {
 		missingVars = append(missingVars, "BINANCE_API_KEY")
 	}
-	if conf.ApiSecret == "" {
+	if conf.ApiSecret == "" && conf.Mode != ModePaper {
 		missingVars = append(missingVars, "BINANCE_API_SECRET")
 	}
+	if !exchange.ValidInterval(conf.Interval) {
+		invalidVars = append(invalidVars, "INTERVAL")
+	}
+
+	lookback, err := LoadLookback()
+	if err != nil {
+		invalidVars = append(invalidVars, "LOOKBACK")
+	}
+	conf.Lookback = lookback
+
+	conf.RecvWindow = 5 * time.Second
+	if recvWindowStr := os.Getenv("RECV_WINDOW"); recvWindowStr != "" {
+		recvWindow, err := strconv.Atoi(recvWindowStr)
+		if err != nil || recvWindow < 1 || recvWindow > 60000 {
+			invalidVars = append(invalidVars, "RECV_WINDOW")
+		}
+		conf.RecvWindow = time.Duration(recvWindow) * time.Millisecond
+	}
+
+	conf.MaxFailures = 5
+	if maxFailuresStr := os.Getenv("MAX_CONSECUTIVE_FAILURES"); maxFailuresStr != "" {
+		maxFailures, err := strconv.Atoi(maxFailuresStr)
+		if err != nil || maxFailures < 0 {
+			invalidVars = append(invalidVars, "MAX_CONSECUTIVE_FAILURES")
+		}
+		conf.MaxFailures = maxFailures
+	}
+	conf.FailurePause = time.Hour
+	if pauseStr := os.Getenv("FAILURE_PAUSE"); pauseStr != "" {
+		pause, err := time.ParseDuration(pauseStr)
+		if err != nil || pause <= 0 {
+			invalidVars = append(invalidVars, "FAILURE_PAUSE")
+		}
+		conf.FailurePause = pause
+	}
+
+	if conf.Mode != ModeLive && conf.Mode != ModePaper {
+		invalidVars = append(invalidVars, "MODE")
+	}
+	if conf.MarketData != MarketDataStream && conf.MarketData != MarketDataPoll {
+		invalidVars = append(invalidVars, "MARKET_DATA")
+	}
+
+	conf.Reconcile = strings.ToLower(os.Getenv("RECONCILE"))
+	if conf.Reconcile == "" {
+		conf.Reconcile = ReconcileHalt
+		if conf.Mode == ModePaper {
+			conf.Reconcile = ReconcileAuto
+		}
+	}
+	if conf.Reconcile != ReconcileAuto && conf.Reconcile != ReconcileHalt && conf.Reconcile != ReconcileOff {
+		invalidVars = append(invalidVars, "RECONCILE")
+	}
+	if intervalStr := os.Getenv("RECONCILE_INTERVAL"); intervalStr != "" {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil || interval < 0 {
+			invalidVars = append(invalidVars, "RECONCILE_INTERVAL")
+		}
+		conf.ReconcileInterval = interval
+	}
+	conf.ReconcileAck = parseSymbols(os.Getenv("RECONCILE_ACK"))
+
+	floatVars := []struct {
+		name string
+		def  float64
+		dst  *float64
+	}{
+		{"PAPER_FEE_PERCENT", 0.1, &conf.PaperFeePercent},
+		{"PAPER_SLIPPAGE_PERCENT", 0.05, &conf.PaperSlippagePercent},
+		{"PAPER_QUOTE_BALANCE", 1000, &conf.PaperQuoteBalance},
+		{"PAPER_BASE_BALANCE", 0, &conf.PaperBaseBalance},
+		{"STOP_LOSS_PERCENT", 0, &conf.StopLossPercent},
+		{"TAKE_PROFIT_PERCENT", 0, &conf.TakeProfitPercent},
+		{"TRAILING_STOP_PERCENT", 0, &conf.TrailingStopPercent},
+		{"SIZING_VALUE", 50, &conf.SizingValue},
+	}
+	for _, v := range floatVars {
+		value, err := getEnvFloat(v.name, v.def)
+		if err != nil || value < 0 {
+			invalidVars = append(invalidVars, v.name)
+		}
+		*v.dst = value
+	}
 
 	periodStr := os.Getenv("PERIOD")
 	period, err := strconv.Atoi(periodStr)
@@ -73,8 +273,55 @@ func LoadConfig() (*Config, error) {
 	if len(missingVars) > 0 {
 		return nil, fmt.Errorf("as variáveis obrigatórias estão faltando: %s", strings.Join(missingVars, ", "))
 	}
+	if len(invalidVars) > 0 {
+		return nil, fmt.Errorf("as variáveis possuem valores inválidos: %s", strings.Join(invalidVars, ", "))
+	}
 
 	fmt.Println("Configurações carregadas com sucesso")
 
 	return conf, nil
 }
+
+// LoadLookback lê LOOKBACK, a quantidade de candles fechados passados à estratégia
+// a cada avaliação, usada pelo bot e pelo backtest.
+// Retorna o padrão 100 se a variável não estiver definida, ou erro se o valor não
+// estiver entre 1 e 999: um candle extra é solicitado para descartar o candle em
+// formação, respeitando o limite de 1000 candles por requisição da Binance.
+func LoadLookback() (int, error) {
+	str := os.Getenv("LOOKBACK")
+	if str == "" {
+		return 100, nil
+	}
+	lookback, err := strconv.Atoi(str)
+	if err != nil || lookback < 1 || lookback > 999 {
+		return 0, fmt.Errorf("LOOKBACK inválido: %q (esperado entre 1 e 999)", str)
+	}
+	return lookback, nil
+}
+
+// getEnvFloat lê uma variável de ambiente numérica
+// Retorna o valor padrão def se a variável não estiver definida,
+// ou erro se o valor não puder ser convertido para float64.
+func getEnvFloat(name string, def float64) (float64, error) {
+	str := os.Getenv(name)
+	if str == "" {
+		return def, nil
+	}
+	return strconv.ParseFloat(str, 64)
+}
+
+// parseSymbols converte uma lista separada por vírgulas em pares em letras
+// maiúsculas, ignorando entradas vazias e repetidas.
+func parseSymbols(str string) []string {
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, symbol := range strings.Split(str, ",") {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
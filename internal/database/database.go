@@ -1,17 +1,31 @@
 package database
 
 import (
-	"database/sql"
+	"context"
 	"fmt"
 	"os"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/shopspring/decimal"
 )
 
+// Drivers de armazenamento disponíveis, selecionados pela variável DB_DRIVER.
+const (
+	// DriverPostgres grava os dados em um servidor PostgreSQL (padrão).
+	DriverPostgres = "postgres"
+	// DriverSQLite grava os dados em um arquivo SQLite local, sem servidor externo.
+	DriverSQLite = "sqlite"
+	// DriverMemory mantém os dados apenas em memória; tudo é perdido ao encerrar o bot.
+	DriverMemory = "memory"
+)
+
+// defaultSQLitePath é o arquivo usado pelo driver sqlite quando DB_PATH não é informado.
+const defaultSQLitePath = "crypto_bot.db"
+
 // Order representa um registro de ordem no sistema.
 // Cada ordem contém informações sobre o símbolo, o tipo de operação (Buy/Sell),
-// quantidade, preço e a data de criação.
+// quantidade, preço, o modo de operação (live/paper), a data de criação e o
+// resultado informado pela corretora (identificadores, quantidade executada e taxas).
 type Order struct {
 	// ID é o identificador único da ordem.
 	ID int64 `json:"id"`
@@ -19,162 +33,315 @@ type Order struct {
 	Symbol string `json:"symbol"`
 	// Side indica se a ordem é de compra ou venda.
 	Side string `json:"side"`
-	// Quantity representa a quantidade negociada.
-	Quantity float64 `json:"quantity"`
-	// Price é o valor da ordem.
-	Price float64 `json:"price"`
+	// Quantity representa a quantidade solicitada.
+	Quantity decimal.Decimal `json:"quantity"`
+	// Price é o preço médio de execução, ou o preço de referência se a ordem não foi executada.
+	Price decimal.Decimal `json:"price"`
+	// Mode indica se a ordem foi enviada à corretora ("live") ou simulada ("paper").
+	Mode string `json:"mode"`
+	// Reason indica a regra que motivou a ordem (ex: strategy, stop_loss, take_profit).
+	Reason string `json:"reason"`
+	// ExchangeOrderID é o identificador da ordem na corretora.
+	ExchangeOrderID int64 `json:"exchange_order_id"`
+	// ClientOrderID é o identificador da ordem atribuído pelo cliente.
+	ClientOrderID string `json:"client_order_id"`
+	// Status é o estado da ordem informado pela corretora (ex: FILLED), ou
+	// OrderStatusPending e OrderStatusRejected para ordens sem resposta da corretora.
+	Status string `json:"status"`
+	// ExecutedQty é a quantidade efetivamente executada.
+	ExecutedQty decimal.Decimal `json:"executed_qty"`
+	// CummulativeQuoteQty é o valor total executado no ativo de cotação.
+	CummulativeQuoteQty decimal.Decimal `json:"cummulative_quote_qty"`
+	// Commission é a taxa total cobrada no ativo CommissionAsset.
+	Commission decimal.Decimal `json:"commission"`
+	// CommissionAsset é o ativo em que a taxa foi cobrada.
+	CommissionAsset string `json:"commission_asset"`
+	// Fills são as execuções da ordem; registradas por SaveOrder, mas não carregadas nas consultas.
+	Fills []Fill `json:"fills,omitempty"`
 	// CreatedAt marca o momento em que a ordem foi criada.
 	CreatedAt time.Time `json:"created_at"`
 }
 
-// Position representa a posição atual para um símbolo.
-// Armazena se a posição está aberta e a data da última atualização.
+// Estados das ordens registradas antes do envio à corretora.
+const (
+	// OrderStatusPending indica uma ordem registrada antes do envio, ainda sem resultado conhecido.
+	OrderStatusPending = "PENDING"
+	// OrderStatusRejected indica uma ordem que não foi aceita pela corretora.
+	OrderStatusRejected = "REJECTED"
+)
+
+// Fill representa uma execução de uma ordem informada pela corretora.
+type Fill struct {
+	// TradeID é o identificador da execução na corretora.
+	TradeID int64 `json:"trade_id"`
+	// Price é o preço da execução.
+	Price decimal.Decimal `json:"price"`
+	// Quantity é a quantidade executada.
+	Quantity decimal.Decimal `json:"quantity"`
+	// Commission é a taxa cobrada na execução.
+	Commission decimal.Decimal `json:"commission"`
+	// CommissionAsset é o ativo em que a taxa foi cobrada.
+	CommissionAsset string `json:"commission_asset"`
+}
+
+// Position representa a posição atual para um símbolo em um modo de operação.
+// Armazena se a posição está aberta, o preço de entrada, a quantidade, as taxas
+// da entrada, o maior preço desde a abertura e as datas de abertura, encerramento
+// e última atualização.
 type Position struct {
 	// ID é o identificador único da posição.
 	ID int64 `json:"id"`
 	// Symbol é o ativo relacionado à posição.
 	Symbol string `json:"symbol"`
+	// Mode indica se a posição pertence ao modo "live" ou "paper".
+	Mode string `json:"mode"`
 	// IsOpened indica se a posição está atualmente aberta (true) ou fechada (false).
 	IsOpened bool `json:"is_opened"`
+	// EntryPrice é o preço médio de entrada (zero se desconhecido).
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	// Quantity é a quantidade do ativo base mantida na posição, já descontadas as taxas.
+	Quantity decimal.Decimal `json:"quantity"`
+	// Fees são as taxas da entrada, convertidas para o ativo de cotação.
+	Fees decimal.Decimal `json:"fees"`
+	// HighestPrice é o maior preço observado desde a abertura, acompanhado pelo
+	// trailing stop (zero se desconhecido).
+	HighestPrice decimal.Decimal `json:"highest_price"`
+	// OpenedAt indica o momento da abertura (zero se desconhecido).
+	OpenedAt time.Time `json:"opened_at"`
+	// ClosedAt indica o momento do último encerramento (zero se a posição nunca foi encerrada).
+	ClosedAt time.Time `json:"closed_at"`
 	// UpdatedAt indica o momento da última atualização da posição.
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
-var db *sql.DB
+// ClosedTrade representa uma operação encerrada no histórico de trades:
+// a compra que abriu a posição e a venda que a encerrou.
+type ClosedTrade struct {
+	// ID é o identificador único da operação.
+	ID int64 `json:"id"`
+	// Symbol é o ativo negociado.
+	Symbol string `json:"symbol"`
+	// Mode indica se a operação pertence ao modo "live" ou "paper".
+	Mode string `json:"mode"`
+	// EntryPrice é o preço médio da compra.
+	EntryPrice decimal.Decimal `json:"entry_price"`
+	// ExitPrice é o preço médio da venda.
+	ExitPrice decimal.Decimal `json:"exit_price"`
+	// Quantity é a quantidade vendida.
+	Quantity decimal.Decimal `json:"quantity"`
+	// Fees são as taxas da compra e da venda, no ativo de cotação.
+	Fees decimal.Decimal `json:"fees"`
+	// PnL é o resultado realizado, já descontadas as taxas, no ativo de cotação.
+	PnL decimal.Decimal `json:"pnl"`
+	// PnLPercent é o resultado realizado em porcentagem do custo de entrada.
+	PnLPercent decimal.Decimal `json:"pnl_percent"`
+	// Reason indica a regra que motivou a venda (ex: strategy, stop_loss).
+	Reason string `json:"reason"`
+	// OpenedAt indica o momento da compra.
+	OpenedAt time.Time `json:"opened_at"`
+	// ClosedAt indica o momento da venda.
+	ClosedAt time.Time `json:"closed_at"`
+}
 
-// Initialize estabelece a conexão com o banco de dados PostgreSQL e inicializa as tabelas necessárias.
-// Utiliza as seguintes variáveis de ambiente para configuração:
-//   - DB_HOST: endereço do servidor PostgreSQL
-//   - DB_PORT: porta do servidor PostgreSQL
-//   - DB_USER: usuário do banco de dados
-//   - DB_PASSWORD: senha do usuário
-//   - DB_NAME: nome do banco de dados
-//
-// Retorna erro se:
-//   - Falhar ao estabelecer conexão com o banco
-//   - Falhar ao criar as tabelas necessárias
-func Initialize() error {
-	// Cria a connection string a partir das variáveis de ambiente.
-	connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-	)
-	var err error
-	db, err = sql.Open("postgres", connStr)
-	if err != nil {
-		return err
-	}
+// Candle representa um candle fechado no histórico local de mercado.
+// Cada candle é identificado pelo símbolo, intervalo e horário de abertura.
+// Os valores são float64, como nos candles da corretora, pois alimentam apenas
+// os indicadores e o backtest.
+type Candle struct {
+	// Symbol é o par de moedas (ex: BTCUSDT).
+	Symbol string `json:"symbol"`
+	// Interval é o intervalo do candle (ex: 15m).
+	Interval string `json:"interval"`
+	// OpenTime é o horário de abertura em milissegundos.
+	OpenTime int64 `json:"open_time"`
+	// CloseTime é o horário de fechamento em milissegundos.
+	CloseTime int64 `json:"close_time"`
+	// Open, High, Low e Close são os preços de abertura, máximo, mínimo e fechamento.
+	Open  float64 `json:"open"`
+	High  float64 `json:"high"`
+	Low   float64 `json:"low"`
+	Close float64 `json:"close"`
+	// Volume é o volume negociado no ativo base.
+	Volume float64 `json:"volume"`
+	// QuoteVolume é o volume negociado no ativo de cotação.
+	QuoteVolume float64 `json:"quote_volume"`
+	// Trades é o número de negociações no período.
+	Trades int64 `json:"trades"`
+	// TakerBuyBaseVolume é o volume de compra dos takers no ativo base.
+	TakerBuyBaseVolume float64 `json:"taker_buy_base_volume"`
+	// TakerBuyQuoteVolume é o volume de compra dos takers no ativo de cotação.
+	TakerBuyQuoteVolume float64 `json:"taker_buy_quote_volume"`
+}
+
+// Ações registradas no diário de decisões.
+const (
+	// ActionBuy indica que uma compra foi decidida.
+	ActionBuy = "buy"
+	// ActionSell indica que uma venda foi decidida (pela estratégia ou por uma regra de risco).
+	ActionSell = "sell"
+	// ActionHold indica que a estratégia não sinalizou entrada nem saída.
+	ActionHold = "hold"
+	// ActionSkip indica que o par não foi avaliado (ex: pausado, suspenso ou falha na leitura).
+	ActionSkip = "skip"
+)
 
-	// Declaração das queries de criação das tabelas.
-	createTables := `
-	CREATE TABLE IF NOT EXISTS orders (
-		id SERIAL PRIMARY KEY,
-		symbol TEXT NOT NULL,
-		side TEXT NOT NULL,
-		quantity REAL NOT NULL,
-		price REAL NOT NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-
-	CREATE TABLE IF NOT EXISTS positions (
-		id SERIAL PRIMARY KEY,
-		symbol TEXT UNIQUE NOT NULL,
-		is_opened BOOLEAN NOT NULL,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	);
-	`
-	// Executa as queries para criar as tabelas se estas ainda não existirem.
-	_, err = db.Exec(createTables)
-	return err
+// Decision representa uma avaliação de um par no diário de decisões: os dados
+// considerados, os sinais da estratégia e a ação tomada ou o motivo de não agir.
+type Decision struct {
+	// ID é o identificador único da decisão.
+	ID int64 `json:"id"`
+	// Symbol é o par avaliado.
+	Symbol string `json:"symbol"`
+	// Mode indica se a avaliação pertence ao modo "live" ou "paper".
+	Mode string `json:"mode"`
+	// Strategy é o nome da estratégia avaliada.
+	Strategy string `json:"strategy"`
+	// EvaluatedAt é o momento da avaliação.
+	EvaluatedAt time.Time `json:"evaluated_at"`
+	// CandleTime é a abertura, em milissegundos, do último candle fechado considerado (zero se não lido).
+	CandleTime int64 `json:"candle_time"`
+	// Price é o fechamento do último candle considerado (zero se não lido).
+	Price decimal.Decimal `json:"price"`
+	// Indicators são os valores dos indicadores da estratégia pela chave estável
+	// (ex: rsi, sma, trend_strength). RSI, SMA e tendência também são gravados em colunas próprias.
+	Indicators map[string]float64 `json:"indicators"`
+	// IsOpened indica se havia posição aberta na avaliação.
+	IsOpened bool `json:"is_opened"`
+	// ShouldEnter é o resultado de ShouldEnter, ou nil se a entrada não foi avaliada.
+	ShouldEnter *bool `json:"should_enter,omitempty"`
+	// ShouldExit é o resultado de ShouldExit, ou nil se a saída não foi avaliada.
+	ShouldExit *bool `json:"should_exit,omitempty"`
+	// Action é a ação decidida (ActionBuy, ActionSell, ActionHold ou ActionSkip).
+	Action string `json:"action"`
+	// Reason é a regra que motivou a ação, ou o motivo de não agir.
+	Reason string `json:"reason"`
+	// Error é a falha que impediu a avaliação ou a execução da ação (vazio se não houve falha).
+	Error string `json:"error,omitempty"`
 }
 
-// SaveOrder registra uma nova ordem de compra ou venda no banco de dados.
-// Parâmetros:
-//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
-//   - side: direção da ordem ("BUY" ou "SELL")
-//   - quantity: quantidade do ativo a ser negociado
-//   - price: preço unitário do ativo
+// Store define as operações de persistência do bot: ordens, posições, o
+// histórico de operações encerradas, o histórico de candles e o diário de decisões.
 //
-// Retorna erro se:
-//   - Falhar ao preparar a declaração SQL
-//   - Falhar ao executar a inserção no banco
-func SaveOrder(symbol string, side string, quantity, price float64) error {
-	// Prepara a instrução SQL para inserir a ordem.
-	stmt, err := db.Prepare(`
-		INSERT INTO orders (symbol, side, quantity, price)
-		VALUES ($1, $2, $3, $4)
-	`)
-	if err != nil {
-		return err
-	}
-	defer stmt.Close()
+// Todos os métodos recebem um context.Context; uma operação em andamento é
+// abortada se o contexto for cancelado. As implementações são seguras para uso
+// concorrente pelos Traders de cada par.
+type Store interface {
+	// SaveOrder registra uma ordem e suas execuções (Fills) atomicamente.
+	// São utilizados todos os campos exceto ID e CreatedAt.
+	SaveOrder(ctx context.Context, order Order) error
+	// GetLastOrder retorna a ordem mais recente do símbolo no lado (BUY/SELL) e
+	// modo informados aceita pela corretora, ignorando as ordens sem resultado
+	// (OrderStatusPending) e as não aceitas (OrderStatusRejected), ou nil se não
+	// houver ordem registrada.
+	GetLastOrder(ctx context.Context, symbol string, side string, mode string) (*Order, error)
+	// GetPendingOrders retorna as ordens do símbolo e modo ainda sem resultado da
+	// corretora (OrderStatusPending), da mais antiga para a mais recente.
+	GetPendingOrders(ctx context.Context, symbol string, mode string) ([]Order, error)
+	// GetOrderByExchangeID retorna a ordem do símbolo e modo registrada com o
+	// identificador da corretora, ou nil se não houver ordem registrada.
+	GetOrderByExchangeID(ctx context.Context, symbol string, mode string, exchangeOrderID int64) (*Order, error)
+	// GetOrderByClientID retorna a ordem mais recente do símbolo e modo registrada com o
+	// identificador do cliente, ou nil se não houver ordem registrada.
+	GetOrderByClientID(ctx context.Context, symbol string, mode string, clientOrderID string) (*Order, error)
+	// UpdateOrder atualiza a quantidade, o preço, o identificador da corretora, o estado,
+	// as quantidades executadas e as taxas da ordem identificada por ID e acrescenta as
+	// execuções em Fills atomicamente.
+	UpdateOrder(ctx context.Context, order Order) error
+	// OpenPosition marca a posição do símbolo como aberta com o preço de entrada,
+	// a quantidade e as taxas de pos; existe apenas uma posição por símbolo e modo.
+	// O maior preço da posição passa a ser o preço de entrada.
+	OpenPosition(ctx context.Context, pos Position) error
+	// UpdatePosition atualiza o preço de entrada, a quantidade e as taxas da posição
+	// aberta do símbolo com os de pos, mantendo o horário de abertura.
+	UpdatePosition(ctx context.Context, pos Position) error
+	// UpdateHighestPrice registra price como o maior preço da posição aberta do símbolo.
+	UpdateHighestPrice(ctx context.Context, symbol string, mode string, price decimal.Decimal) error
+	// ClosePosition marca a posição do símbolo como fechada e, se trade não for
+	// nil, registra a operação encerrada atomicamente.
+	ClosePosition(ctx context.Context, symbol string, mode string, trade *ClosedTrade) error
+	// GetOpenPosition retorna a posição aberta do símbolo, ou nil se a posição
+	// estiver fechada ou não existir.
+	GetOpenPosition(ctx context.Context, symbol string, mode string) (*Position, error)
+	// GetPosition informa se existe uma posição aberta para o símbolo.
+	// Retorna (false, nil) se não existir posição.
+	GetPosition(ctx context.Context, symbol string, mode string) (bool, error)
+	// GetRealizedPnL retorna a soma do resultado realizado das operações
+	// encerradas do símbolo e a quantidade de operações.
+	GetRealizedPnL(ctx context.Context, symbol string, mode string) (decimal.Decimal, int, error)
+	// SaveCandles grava os candles atomicamente, substituindo os já registrados
+	// com o mesmo símbolo, intervalo e horário de abertura.
+	SaveCandles(ctx context.Context, candles []Candle) error
+	// GetCandles retorna os candles do símbolo e intervalo abertos entre start
+	// (inclusive) e end (exclusive), do mais antigo para o mais recente.
+	GetCandles(ctx context.Context, symbol string, interval string, start, end time.Time) ([]Candle, error)
+	// SaveDecision registra uma avaliação no diário de decisões.
+	SaveDecision(ctx context.Context, d Decision) error
+	// GetDecisions retorna as últimas limit decisões do símbolo no modo
+	// informado, da mais recente para a mais antiga.
+	GetDecisions(ctx context.Context, symbol string, mode string, limit int) ([]Decision, error)
+	// Close libera a conexão com o banco. Deve ser chamado ao encerrar a aplicação.
+	Close() error
+}
 
-	// Executa a instrução com os parâmetros passados.
-	_, err = stmt.Exec(symbol, side, quantity, price)
-	return err
+// Migrator é implementado pelos Stores com esquema versionado (PostgreSQL e SQLite).
+// O Store em memória não possui esquema e não implementa esta interface.
+type Migrator interface {
+	// MigrateUp aplica as migrações pendentes e retorna as migrações aplicadas.
+	MigrateUp(ctx context.Context) ([]Migration, error)
+	// MigrateDown desfaz as últimas steps migrações e retorna as migrações desfeitas.
+	MigrateDown(ctx context.Context, steps int) ([]Migration, error)
+	// MigrationStatus retorna todas as migrações conhecidas e se cada uma está aplicada.
+	MigrationStatus(ctx context.Context) ([]MigrationState, error)
 }
 
-// UpdatePosition atualiza ou cria uma nova posição para um determinado símbolo no banco de dados.
-// Utiliza a cláusula ON CONFLICT para garantir que existe apenas uma posição por símbolo.
-// Parâmetros:
-//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
-//   - isOpened: true se a posição está aberta, false se fechada
+// Initialize abre o Store selecionado em DB_DRIVER e aplica as migrações
+// pendentes do esquema (ver Migrator). Utiliza as variáveis de ambiente descritas em Open.
 //
 // Retorna erro se:
-//   - Falhar ao preparar a declaração SQL
-//   - Falhar ao executar a atualização/inserção no banco
-func UpdatePosition(symbol string, isOpened bool) error {
-	// Prepara a instrução SQL que insere uma nova posição ou atualiza a existente.
-	stmt, err := db.Prepare(`
-		INSERT INTO positions (symbol, is_opened, updated_at)
-		VALUES ($1, $2, CURRENT_TIMESTAMP)
-		ON CONFLICT (symbol)
-		DO UPDATE SET is_opened = $3, updated_at = CURRENT_TIMESTAMP
-	`)
+//   - Falhar ao estabelecer conexão com o banco
+//   - Falhar ao aplicar alguma migração
+func Initialize(ctx context.Context) (Store, error) {
+	store, err := Open(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer stmt.Close()
-
-	// Executa a instrução com os parâmetros fornecidos.
-	_, err = stmt.Exec(symbol, isOpened, isOpened)
-	return err
-}
-
-// GetPosition consulta o estado atual da posição para um determinado símbolo.
-// Parâmetros:
-//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
-//
-// Retorna:
-//   - bool: true se existe uma posição aberta, false se fechada ou inexistente
-//   - error: erro em caso de falha na consulta ao banco
-//
-// Comportamento especial:
-//   - Se não existir posição para o símbolo, retorna (false, nil)
-//   - Se ocorrer erro na consulta, retorna (false, erro)
-func GetPosition(symbol string) (bool, error) {
-	var isOpened bool
-	// Executa a consulta e mapeia o resultado para a variável isOpened.
-	err := db.QueryRow(`
-		SELECT is_opened FROM positions
-		WHERE symbol = $1
-	`, symbol).Scan(&isOpened)
-
-	// Se não houver linha, retorna false sem erro.
-	if err == sql.ErrNoRows {
-		return false, nil
+	if m, ok := store.(Migrator); ok {
+		if _, err := m.MigrateUp(ctx); err != nil {
+			store.Close()
+			return nil, err
+		}
 	}
-	return isOpened, err
+	return store, nil
 }
 
-// Close finaliza a conexão com o banco de dados de forma segura.
-// Deve ser chamado quando a aplicação for encerrada para liberar recursos.
-// É seguro chamar mesmo se a conexão não estiver inicializada (db == nil).
-func Close() {
-	if db != nil {
-		db.Close()
+// Open abre o Store selecionado na variável de ambiente DB_DRIVER, sem alterar o esquema.
+// Utiliza as seguintes variáveis de ambiente para configuração:
+//   - DB_DRIVER: postgres (padrão), sqlite ou memory
+//   - DB_HOST, DB_PORT, DB_USER, DB_PASSWORD, DB_NAME: conexão com o PostgreSQL
+//   - DB_PATH: arquivo do banco SQLite (padrão crypto_bot.db)
+//
+// Retorna erro se o driver for desconhecido ou se a conexão não puder ser estabelecida.
+func Open(ctx context.Context) (Store, error) {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", DriverPostgres:
+		// Cria a connection string a partir das variáveis de ambiente.
+		connStr := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
+			os.Getenv("DB_HOST"),
+			os.Getenv("DB_PORT"),
+			os.Getenv("DB_USER"),
+			os.Getenv("DB_PASSWORD"),
+			os.Getenv("DB_NAME"),
+		)
+		return openPostgres(ctx, connStr)
+	case DriverSQLite:
+		path := os.Getenv("DB_PATH")
+		if path == "" {
+			path = defaultSQLitePath
+		}
+		return openSQLite(ctx, path)
+	case DriverMemory:
+		return NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("DB_DRIVER inválido: %s (use %s, %s ou %s)", driver, DriverPostgres, DriverSQLite, DriverMemory)
 	}
 }
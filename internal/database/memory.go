@@ -0,0 +1,379 @@
+package database
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// memoryStore implementa Store mantendo os registros apenas em memória.
+// Útil em testes e em execuções descartáveis (ex: modo paper); os dados são
+// perdidos quando o processo é encerrado.
+type memoryStore struct {
+	mu        sync.Mutex
+	orders    []Order
+	positions map[positionKey]*Position
+	trades    []ClosedTrade
+	candles   map[candleKey]Candle
+	decisions []Decision
+	nextID    int64
+}
+
+// positionKey identifica a posição de um símbolo em um modo de operação.
+type positionKey struct {
+	symbol string
+	mode   string
+}
+
+// candleKey identifica um candle pelo símbolo, intervalo e horário de abertura.
+type candleKey struct {
+	symbol   string
+	interval string
+	openTime int64
+}
+
+// NewMemoryStore cria um Store vazio mantido em memória.
+func NewMemoryStore() Store {
+	return &memoryStore{
+		positions: make(map[positionKey]*Position),
+		candles:   make(map[candleKey]Candle),
+	}
+}
+
+// id retorna o próximo identificador dos registros. Deve ser chamado com mu bloqueado.
+func (s *memoryStore) id() int64 {
+	s.nextID++
+	return s.nextID
+}
+
+// SaveOrder registra a ordem com um novo ID e a data atual.
+func (s *memoryStore) SaveOrder(ctx context.Context, order Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order.ID = s.id()
+	order.Fills = slices.Clone(order.Fills)
+	order.CreatedAt = time.Now().UTC()
+	s.orders = append(s.orders, order)
+	return nil
+}
+
+// GetLastOrder retorna a ordem mais recente do símbolo no lado e modo informados,
+// ignorando as ordens sem resultado (OrderStatusPending) e as não aceitas pela
+// corretora (OrderStatusRejected).
+// As execuções (Fills) não são retornadas, como nos bancos SQL.
+func (s *memoryStore) GetLastOrder(ctx context.Context, symbol string, side string, mode string) (*Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		if o := s.orders[i]; o.Symbol == symbol && o.Side == side && o.Mode == mode &&
+			o.Status != OrderStatusPending && o.Status != OrderStatusRejected {
+			o.Fills = nil
+			return &o, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetPendingOrders retorna as ordens do símbolo ainda sem resultado da corretora (OrderStatusPending).
+// As execuções (Fills) não são retornadas, como nos bancos SQL.
+func (s *memoryStore) GetPendingOrders(ctx context.Context, symbol string, mode string) ([]Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var orders []Order
+	for _, o := range s.orders {
+		if o.Symbol == symbol && o.Mode == mode && o.Status == OrderStatusPending {
+			o.Fills = nil
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+// GetOrderByExchangeID retorna a ordem do símbolo registrada com o identificador da corretora.
+// As execuções (Fills) não são retornadas, como nos bancos SQL.
+func (s *memoryStore) GetOrderByExchangeID(ctx context.Context, symbol string, mode string, exchangeOrderID int64) (*Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		if o := s.orders[i]; o.Symbol == symbol && o.Mode == mode && o.ExchangeOrderID == exchangeOrderID {
+			o.Fills = nil
+			return &o, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetOrderByClientID retorna a ordem mais recente do símbolo registrada com o identificador do cliente.
+// As execuções (Fills) não são retornadas, como nos bancos SQL.
+func (s *memoryStore) GetOrderByClientID(ctx context.Context, symbol string, mode string, clientOrderID string) (*Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.orders) - 1; i >= 0; i-- {
+		if o := s.orders[i]; o.Symbol == symbol && o.Mode == mode && o.ClientOrderID == clientOrderID {
+			o.Fills = nil
+			return &o, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateOrder atualiza o resultado da ordem registrada com order.ID e acrescenta suas novas execuções.
+func (s *memoryStore) UpdateOrder(ctx context.Context, order Order) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.orders {
+		if o := &s.orders[i]; o.ID == order.ID {
+			o.Quantity = order.Quantity
+			o.Price = order.Price
+			o.ExchangeOrderID = order.ExchangeOrderID
+			o.Status = order.Status
+			o.ExecutedQty = order.ExecutedQty
+			o.CummulativeQuoteQty = order.CummulativeQuoteQty
+			o.Commission = order.Commission
+			o.CommissionAsset = order.CommissionAsset
+			o.Fills = append(slices.Clone(o.Fills), order.Fills...)
+			return nil
+		}
+	}
+	return fmt.Errorf("ordem %d não encontrada", order.ID)
+}
+
+// OpenPosition marca a posição do símbolo como aberta, criando-a se necessário.
+func (s *memoryStore) OpenPosition(ctx context.Context, pos Position) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	p := s.position(pos.Symbol, pos.Mode)
+	p.IsOpened = true
+	p.EntryPrice = pos.EntryPrice
+	p.Quantity = pos.Quantity
+	p.Fees = pos.Fees
+	p.HighestPrice = pos.EntryPrice
+	p.OpenedAt = now
+	p.ClosedAt = time.Time{}
+	p.UpdatedAt = now
+	return nil
+}
+
+// UpdatePosition atualiza a posição aberta do símbolo, mantendo o horário de abertura.
+func (s *memoryStore) UpdatePosition(ctx context.Context, pos Position) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.position(pos.Symbol, pos.Mode)
+	if !p.IsOpened {
+		return nil
+	}
+	p.EntryPrice = pos.EntryPrice
+	p.Quantity = pos.Quantity
+	p.Fees = pos.Fees
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// UpdateHighestPrice registra o maior preço da posição aberta do símbolo.
+func (s *memoryStore) UpdateHighestPrice(ctx context.Context, symbol string, mode string, price decimal.Decimal) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := s.position(symbol, mode)
+	if !p.IsOpened {
+		return nil
+	}
+	p.HighestPrice = price
+	p.UpdatedAt = time.Now().UTC()
+	return nil
+}
+
+// ClosePosition marca a posição do símbolo como fechada e registra trade, se não for nil.
+func (s *memoryStore) ClosePosition(ctx context.Context, symbol string, mode string, trade *ClosedTrade) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now().UTC()
+	if trade != nil {
+		t := *trade
+		t.ID = s.id()
+		t.Symbol = symbol
+		t.Mode = mode
+		t.ClosedAt = now
+		s.trades = append(s.trades, t)
+	}
+
+	p := s.position(symbol, mode)
+	p.IsOpened = false
+	p.ClosedAt = now
+	p.UpdatedAt = now
+	return nil
+}
+
+// position retorna a posição do símbolo, criando-a fechada se não existir.
+// Deve ser chamado com mu bloqueado.
+func (s *memoryStore) position(symbol, mode string) *Position {
+	key := positionKey{symbol: symbol, mode: mode}
+	p, ok := s.positions[key]
+	if !ok {
+		p = &Position{ID: s.id(), Symbol: symbol, Mode: mode}
+		s.positions[key] = p
+	}
+	return p
+}
+
+// GetOpenPosition retorna uma cópia da posição aberta do símbolo, ou nil.
+func (s *memoryStore) GetOpenPosition(ctx context.Context, symbol string, mode string) (*Position, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.positions[positionKey{symbol: symbol, mode: mode}]
+	if !ok || !p.IsOpened {
+		return nil, nil
+	}
+	pos := *p
+	return &pos, nil
+}
+
+// GetPosition informa se existe uma posição aberta para o símbolo.
+func (s *memoryStore) GetPosition(ctx context.Context, symbol string, mode string) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.positions[positionKey{symbol: symbol, mode: mode}]
+	return ok && p.IsOpened, nil
+}
+
+// GetRealizedPnL soma o resultado das operações encerradas do símbolo.
+func (s *memoryStore) GetRealizedPnL(ctx context.Context, symbol string, mode string) (decimal.Decimal, int, error) {
+	if err := ctx.Err(); err != nil {
+		return decimal.Zero, 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pnl := decimal.Zero
+	count := 0
+	for _, t := range s.trades {
+		if t.Symbol == symbol && t.Mode == mode {
+			pnl = pnl.Add(t.PnL)
+			count++
+		}
+	}
+	return pnl, count, nil
+}
+
+// SaveCandles grava os candles, substituindo os já registrados com a mesma chave.
+func (s *memoryStore) SaveCandles(ctx context.Context, candles []Candle) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, c := range candles {
+		s.candles[candleKey{symbol: c.Symbol, interval: c.Interval, openTime: c.OpenTime}] = c
+	}
+	return nil
+}
+
+// GetCandles retorna os candles do símbolo e intervalo abertos em [start, end), ordenados pela abertura.
+func (s *memoryStore) GetCandles(ctx context.Context, symbol string, interval string, start, end time.Time) ([]Candle, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to := start.UnixMilli(), end.UnixMilli()
+	var candles []Candle
+	for k, c := range s.candles {
+		if k.symbol == symbol && k.interval == interval && k.openTime >= from && k.openTime < to {
+			candles = append(candles, c)
+		}
+	}
+	slices.SortFunc(candles, func(a, b Candle) int { return cmp.Compare(a.OpenTime, b.OpenTime) })
+	return candles, nil
+}
+
+// SaveDecision registra uma avaliação no diário de decisões.
+func (s *memoryStore) SaveDecision(ctx context.Context, d Decision) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	d.ID = s.id()
+	d.Indicators = maps.Clone(d.Indicators)
+	s.decisions = append(s.decisions, d)
+	return nil
+}
+
+// GetDecisions retorna as últimas limit decisões do símbolo, da mais recente para a mais antiga.
+func (s *memoryStore) GetDecisions(ctx context.Context, symbol string, mode string, limit int) ([]Decision, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var decisions []Decision
+	for i := len(s.decisions) - 1; i >= 0 && len(decisions) < limit; i-- {
+		if d := s.decisions[i]; d.Symbol == symbol && d.Mode == mode {
+			decisions = append(decisions, d)
+		}
+	}
+	return decisions, nil
+}
+
+// Close não libera recursos; os dados permanecem disponíveis até o fim do processo.
+func (s *memoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,273 @@
+package database
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// migrationFiles contém as migrações do esquema, embutidas no binário, em um
+// diretório por banco (migrations/postgres e migrations/sqlite).
+// Cada migração é um par de arquivos NNNN_nome.up.sql e NNNN_nome.down.sql.
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// migrationLockID identifica o advisory lock que serializa a aplicação de
+// migrações por processos concorrentes (ex: o bot e o comando migrate) no
+// PostgreSQL. No SQLite, a transação iniciada com BEGIN IMMEDIATE já as serializa.
+const migrationLockID = 7_412_563_901
+
+// migrationName reconhece os nomes dos arquivos de migração.
+var migrationName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration representa uma alteração versionada do esquema do banco.
+type Migration struct {
+	// Version é o número sequencial da migração.
+	Version int `json:"version"`
+	// Name é a descrição da migração, extraída do nome do arquivo.
+	Name string `json:"name"`
+	// Up contém o SQL que aplica a migração.
+	Up string `json:"-"`
+	// Down contém o SQL que desfaz a migração.
+	Down string `json:"-"`
+}
+
+// MigrationState representa uma migração e se ela está aplicada no banco.
+type MigrationState struct {
+	Migration
+	// Applied indica se a migração está aplicada.
+	Applied bool `json:"applied"`
+	// AppliedAt indica o momento em que a migração foi aplicada (zero se pendente).
+	AppliedAt time.Time `json:"applied_at"`
+}
+
+// loadMigrations lê as migrações de fsys, ordenadas pela versão.
+// Retorna erro se um arquivo tiver nome inválido, se uma versão estiver
+// duplicada ou se faltar o arquivo up ou down de alguma migração.
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		match := migrationName.FindStringSubmatch(path.Base(file))
+		if match == nil {
+			return nil, fmt.Errorf("nome de migração inválido %q: esperado NNNN_nome.up.sql ou NNNN_nome.down.sql", file)
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("versão de migração inválida %q", file)
+		}
+		content, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("versão de migração %d duplicada: %s e %s", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migração %04d_%s sem arquivo up ou down", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return a.Version - b.Version })
+	return migrations, nil
+}
+
+// embeddedMigrations retorna as migrações embutidas no binário para o banco driver.
+func embeddedMigrations(driver string) ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, path.Join("migrations", driver))
+	if err != nil {
+		return nil, err
+	}
+	return loadMigrations(sub)
+}
+
+// MigrateUp aplica, em ordem, as migrações ainda não registradas em schema_migrations.
+// Cada migração é aplicada em sua própria transação.
+//
+// Retorna as migrações aplicadas, ou erro se alguma falhar; as migrações
+// anteriores à que falhou permanecem aplicadas.
+func (s *sqlStore) MigrateUp(ctx context.Context) ([]Migration, error) {
+	migrations, err := embeddedMigrations(s.driver)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		ok, err := s.runMigration(ctx, m, true)
+		if err != nil {
+			return done, fmt.Errorf("erro ao aplicar a migração %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if ok {
+			done = append(done, m)
+		}
+	}
+	return done, nil
+}
+
+// MigrateDown desfaz as últimas steps migrações aplicadas, da mais recente para a mais antiga.
+//
+// Retorna as migrações desfeitas, ou erro se alguma falhar ou se uma migração
+// aplicada no banco não existir nesta versão do bot.
+func (s *sqlStore) MigrateDown(ctx context.Context, steps int) ([]Migration, error) {
+	migrations, err := embeddedMigrations(s.driver)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+	slices.Reverse(versions)
+
+	var done []Migration
+	for _, v := range versions[:min(steps, len(versions))] {
+		i := slices.IndexFunc(migrations, func(m Migration) bool { return m.Version == v })
+		if i < 0 {
+			return done, fmt.Errorf("a migração %d aplicada no banco não existe nesta versão", v)
+		}
+		m := migrations[i]
+		ok, err := s.runMigration(ctx, m, false)
+		if err != nil {
+			return done, fmt.Errorf("erro ao desfazer a migração %04d_%s: %w", m.Version, m.Name, err)
+		}
+		if ok {
+			done = append(done, m)
+		}
+	}
+	return done, nil
+}
+
+// MigrationStatus retorna todas as migrações conhecidas e se cada uma está aplicada no banco.
+func (s *sqlStore) MigrationStatus(ctx context.Context) ([]MigrationState, error) {
+	migrations, err := embeddedMigrations(s.driver)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.appliedMigrations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, len(migrations))
+	for i, m := range migrations {
+		appliedAt, ok := applied[m.Version]
+		states[i] = MigrationState{Migration: m, Applied: ok, AppliedAt: appliedAt}
+	}
+	return states, nil
+}
+
+// appliedMigrations cria a tabela schema_migrations, se necessário, e retorna as
+// versões registradas com o momento em que foram aplicadas.
+func (s *sqlStore) appliedMigrations(ctx context.Context) (map[int]time.Time, error) {
+	_, err := s.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// runMigration aplica (up) ou desfaz (!up) a migração m em uma transação,
+// registrando a alteração em schema_migrations.
+// Retorna false se outro processo já tiver feito a mesma alteração.
+func (s *sqlStore) runMigration(ctx context.Context, m Migration, up bool) (bool, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	// Desfaz a transação se ela não for confirmada.
+	defer tx.Rollback()
+
+	if s.driver == DriverPostgres {
+		// O lock é liberado ao fim da transação.
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return false, err
+		}
+	}
+	var applied bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)
+	`, m.Version).Scan(&applied)
+	if err != nil {
+		return false, err
+	}
+	if applied == up {
+		return false, nil
+	}
+
+	script, record := m.Down, `DELETE FROM schema_migrations WHERE version = $1`
+	args := []any{m.Version}
+	if up {
+		script, record = m.Up, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`
+		args = append(args, m.Name)
+	}
+	// Sem parâmetros, o script é enviado de uma vez e pode conter vários comandos.
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		return false, err
+	}
+	if _, err := tx.ExecContext(ctx, record, args...); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
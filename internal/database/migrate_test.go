@@ -0,0 +1,143 @@
+package database
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		want     []int
+		wantName string
+		wantErr  bool
+	}{
+		{
+			name: "Should sort migrations by version",
+			files: fstest.MapFS{
+				"0010_add_trades.up.sql":      file("CREATE TABLE trades ();"),
+				"0010_add_trades.down.sql":    file("DROP TABLE trades;"),
+				"0002_add_orders.up.sql":      file("CREATE TABLE orders ();"),
+				"0002_add_orders.down.sql":    file("DROP TABLE orders;"),
+				"0001_create_schema.up.sql":   file("CREATE TABLE positions ();"),
+				"0001_create_schema.down.sql": file("DROP TABLE positions;"),
+			},
+			want:     []int{1, 2, 10},
+			wantName: "create_schema",
+		},
+		{
+			name: "Should fail when the down file is missing",
+			files: fstest.MapFS{
+				"0001_create_schema.up.sql": file("CREATE TABLE positions ();"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Should fail on duplicate versions",
+			files: fstest.MapFS{
+				"0001_create_schema.up.sql":   file("CREATE TABLE positions ();"),
+				"0001_create_schema.down.sql": file("DROP TABLE positions;"),
+				"0001_add_orders.up.sql":      file("CREATE TABLE orders ();"),
+				"0001_add_orders.down.sql":    file("DROP TABLE orders;"),
+			},
+			wantErr: true,
+		},
+		{
+			name: "Should fail on invalid file names",
+			files: fstest.MapFS{
+				"create_schema.sql": file("CREATE TABLE positions ();"),
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("loadMigrations() erro = %v, esperado erro: %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if len(migrations) != len(tt.want) {
+				t.Fatalf("esperadas %d migrações, obteve %d", len(tt.want), len(migrations))
+			}
+			for i, m := range migrations {
+				if m.Version != tt.want[i] {
+					t.Errorf("migração %d tem versão %d, esperado %d", i, m.Version, tt.want[i])
+				}
+			}
+			if migrations[0].Name != tt.wantName || migrations[0].Up == "" || migrations[0].Down == "" {
+				t.Errorf("migração carregada incorretamente: %+v", migrations[0])
+			}
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	for _, driver := range []string{DriverPostgres, DriverSQLite} {
+		t.Run(driver, func(t *testing.T) {
+			migrations, err := embeddedMigrations(driver)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(migrations) == 0 {
+				t.Fatal("nenhuma migração embutida")
+			}
+			// As versões devem ser sequenciais, sem lacunas
+			for i, m := range migrations {
+				if m.Version != i+1 {
+					t.Errorf("migração %s tem versão %d, esperado %d", m.Name, m.Version, i+1)
+				}
+			}
+		})
+	}
+}
+
+func TestSQLiteMigrations(t *testing.T) {
+	ctx := context.Background()
+	s, err := openSQLite(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	migrations, err := embeddedMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied, err := s.MigrateUp(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != len(migrations) {
+		t.Fatalf("aplicadas %d migrações, esperado %d", len(applied), len(migrations))
+	}
+	if applied, err = s.MigrateUp(ctx); err != nil || len(applied) != 0 {
+		t.Fatalf("MigrateUp repetido aplicou %d migrações, erro: %v", len(applied), err)
+	}
+
+	reverted, err := s.MigrateDown(ctx, len(migrations))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reverted) != len(migrations) {
+		t.Fatalf("desfeitas %d migrações, esperado %d", len(reverted), len(migrations))
+	}
+	states, err := s.MigrationStatus(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range states {
+		if st.Applied {
+			t.Errorf("migração %04d_%s ainda aplicada", st.Version, st.Name)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+-- Esquema original do bot. IF NOT EXISTS permite adotar bancos criados antes
+-- das migrações, quando as tabelas eram criadas na inicialização.
+CREATE TABLE IF NOT EXISTS orders (
+	id SERIAL PRIMARY KEY,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	quantity REAL NOT NULL,
+	price REAL NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS positions (
+	id SERIAL PRIMARY KEY,
+	symbol TEXT UNIQUE NOT NULL,
+	is_opened BOOLEAN NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,9 @@
+-- O esquema anterior mantém uma única posição por símbolo: as posições
+-- do modo paper são descartadas.
+DELETE FROM positions WHERE mode <> 'live';
+DROP INDEX IF EXISTS positions_symbol_mode_key;
+ALTER TABLE positions ADD CONSTRAINT positions_symbol_key UNIQUE (symbol);
+ALTER TABLE positions DROP COLUMN IF EXISTS mode;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS reason;
+ALTER TABLE orders DROP COLUMN IF EXISTS mode;
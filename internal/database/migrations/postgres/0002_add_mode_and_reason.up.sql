@@ -0,0 +1,9 @@
+-- Separa as ordens e posições do modo paper das do modo live e registra o motivo
+-- de cada ordem. As colunas podem já existir em bancos criados antes das migrações.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'live';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS mode TEXT NOT NULL DEFAULT 'live';
+
+-- Uma posição por símbolo em cada modo
+ALTER TABLE positions DROP CONSTRAINT IF EXISTS positions_symbol_key;
+CREATE UNIQUE INDEX IF NOT EXISTS positions_symbol_mode_key ON positions (symbol, mode);
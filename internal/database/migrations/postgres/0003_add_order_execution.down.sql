@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS order_fills;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS commission_asset;
+ALTER TABLE orders DROP COLUMN IF EXISTS commission;
+ALTER TABLE orders DROP COLUMN IF EXISTS cummulative_quote_qty;
+ALTER TABLE orders DROP COLUMN IF EXISTS executed_qty;
+ALTER TABLE orders DROP COLUMN IF EXISTS status;
+ALTER TABLE orders DROP COLUMN IF EXISTS client_order_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS exchange_order_id;
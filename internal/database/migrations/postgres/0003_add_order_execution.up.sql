@@ -0,0 +1,19 @@
+-- Resultado da ordem informado pela corretora: identificadores, quantidade
+-- executada, taxas e as execuções individuais.
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS exchange_order_id BIGINT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS client_order_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS executed_qty REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS cummulative_quote_qty REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS commission REAL NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS commission_asset TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS order_fills (
+	id SERIAL PRIMARY KEY,
+	order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	trade_id BIGINT NOT NULL,
+	price REAL NOT NULL,
+	quantity REAL NOT NULL,
+	commission REAL NOT NULL,
+	commission_asset TEXT NOT NULL
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS trades;
+
+ALTER TABLE positions DROP COLUMN IF EXISTS closed_at;
+ALTER TABLE positions DROP COLUMN IF EXISTS opened_at;
+ALTER TABLE positions DROP COLUMN IF EXISTS fees;
+ALTER TABLE positions DROP COLUMN IF EXISTS quantity;
+ALTER TABLE positions DROP COLUMN IF EXISTS entry_price;
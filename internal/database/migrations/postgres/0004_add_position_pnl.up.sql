@@ -0,0 +1,22 @@
+-- Preço de entrada, quantidade e taxas da posição, e o histórico de operações
+-- encerradas usado no cálculo do PnL realizado.
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS entry_price REAL NOT NULL DEFAULT 0;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS quantity REAL NOT NULL DEFAULT 0;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS fees REAL NOT NULL DEFAULT 0;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS opened_at TIMESTAMP;
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS trades (
+	id SERIAL PRIMARY KEY,
+	symbol TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	entry_price REAL NOT NULL,
+	exit_price REAL NOT NULL,
+	quantity REAL NOT NULL,
+	fees REAL NOT NULL,
+	pnl REAL NOT NULL,
+	pnl_percent REAL NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	opened_at TIMESTAMP,
+	closed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,25 @@
+-- Volta para REAL, com a perda de precisão correspondente.
+ALTER TABLE trades
+	ALTER COLUMN entry_price TYPE REAL,
+	ALTER COLUMN exit_price TYPE REAL,
+	ALTER COLUMN quantity TYPE REAL,
+	ALTER COLUMN fees TYPE REAL,
+	ALTER COLUMN pnl TYPE REAL,
+	ALTER COLUMN pnl_percent TYPE REAL;
+
+ALTER TABLE positions
+	ALTER COLUMN entry_price TYPE REAL,
+	ALTER COLUMN quantity TYPE REAL,
+	ALTER COLUMN fees TYPE REAL;
+
+ALTER TABLE order_fills
+	ALTER COLUMN price TYPE REAL,
+	ALTER COLUMN quantity TYPE REAL,
+	ALTER COLUMN commission TYPE REAL;
+
+ALTER TABLE orders
+	ALTER COLUMN quantity TYPE REAL,
+	ALTER COLUMN price TYPE REAL,
+	ALTER COLUMN executed_qty TYPE REAL,
+	ALTER COLUMN cummulative_quote_qty TYPE REAL,
+	ALTER COLUMN commission TYPE REAL;
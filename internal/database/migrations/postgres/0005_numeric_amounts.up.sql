@@ -0,0 +1,27 @@
+-- REAL guarda apenas ~6 dígitos significativos, truncando preços de BTC e
+-- quantidades pequenas. NUMERIC armazena os valores exatos informados pela
+-- corretora; valores já gravados mantêm a precisão com que foram registrados.
+ALTER TABLE orders
+	ALTER COLUMN quantity TYPE NUMERIC,
+	ALTER COLUMN price TYPE NUMERIC,
+	ALTER COLUMN executed_qty TYPE NUMERIC,
+	ALTER COLUMN cummulative_quote_qty TYPE NUMERIC,
+	ALTER COLUMN commission TYPE NUMERIC;
+
+ALTER TABLE order_fills
+	ALTER COLUMN price TYPE NUMERIC,
+	ALTER COLUMN quantity TYPE NUMERIC,
+	ALTER COLUMN commission TYPE NUMERIC;
+
+ALTER TABLE positions
+	ALTER COLUMN entry_price TYPE NUMERIC,
+	ALTER COLUMN quantity TYPE NUMERIC,
+	ALTER COLUMN fees TYPE NUMERIC;
+
+ALTER TABLE trades
+	ALTER COLUMN entry_price TYPE NUMERIC,
+	ALTER COLUMN exit_price TYPE NUMERIC,
+	ALTER COLUMN quantity TYPE NUMERIC,
+	ALTER COLUMN fees TYPE NUMERIC,
+	ALTER COLUMN pnl TYPE NUMERIC,
+	ALTER COLUMN pnl_percent TYPE NUMERIC;
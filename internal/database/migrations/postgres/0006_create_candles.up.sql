@@ -0,0 +1,18 @@
+-- Histórico local de candles fechados, gravado a cada avaliação e pelo comando backfill.
+-- Os horários são os da Binance, em milissegundos desde a época Unix.
+CREATE TABLE IF NOT EXISTS candles (
+	symbol TEXT NOT NULL,
+	"interval" TEXT NOT NULL,
+	open_time BIGINT NOT NULL,
+	close_time BIGINT NOT NULL,
+	open DOUBLE PRECISION NOT NULL,
+	high DOUBLE PRECISION NOT NULL,
+	low DOUBLE PRECISION NOT NULL,
+	close DOUBLE PRECISION NOT NULL,
+	volume DOUBLE PRECISION NOT NULL,
+	quote_volume DOUBLE PRECISION NOT NULL,
+	trades BIGINT NOT NULL,
+	taker_buy_base_volume DOUBLE PRECISION NOT NULL,
+	taker_buy_quote_volume DOUBLE PRECISION NOT NULL,
+	PRIMARY KEY (symbol, "interval", open_time)
+);
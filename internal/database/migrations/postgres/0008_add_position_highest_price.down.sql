@@ -0,0 +1 @@
+ALTER TABLE positions DROP COLUMN IF EXISTS highest_price;
@@ -0,0 +1,3 @@
+-- Maior preço observado desde a abertura da posição, usado pelo trailing stop;
+-- gravado no banco para que a máxima não se perca ao reiniciar o bot.
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS highest_price NUMERIC NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS trades;
+DROP TABLE IF EXISTS positions;
+DROP TABLE IF EXISTS order_fills;
+DROP TABLE IF EXISTS orders;
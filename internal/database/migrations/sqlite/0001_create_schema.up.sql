@@ -0,0 +1,59 @@
+-- Esquema completo do SQLite, equivalente às migrações 0001 a 0005 do PostgreSQL.
+-- Os valores monetários são gravados como TEXT: com afinidade numérica o SQLite
+-- os converteria para ponto flutuante, perdendo a precisão dos decimais.
+CREATE TABLE IF NOT EXISTS orders (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	side TEXT NOT NULL,
+	quantity TEXT NOT NULL,
+	price TEXT NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'live',
+	reason TEXT NOT NULL DEFAULT '',
+	exchange_order_id INTEGER NOT NULL DEFAULT 0,
+	client_order_id TEXT NOT NULL DEFAULT '',
+	status TEXT NOT NULL DEFAULT '',
+	executed_qty TEXT NOT NULL DEFAULT '0',
+	cummulative_quote_qty TEXT NOT NULL DEFAULT '0',
+	commission TEXT NOT NULL DEFAULT '0',
+	commission_asset TEXT NOT NULL DEFAULT '',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS order_fills (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	order_id INTEGER NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+	trade_id INTEGER NOT NULL,
+	price TEXT NOT NULL,
+	quantity TEXT NOT NULL,
+	commission TEXT NOT NULL,
+	commission_asset TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS positions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'live',
+	is_opened BOOLEAN NOT NULL,
+	entry_price TEXT NOT NULL DEFAULT '0',
+	quantity TEXT NOT NULL DEFAULT '0',
+	fees TEXT NOT NULL DEFAULT '0',
+	opened_at TIMESTAMP,
+	closed_at TIMESTAMP,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (symbol, mode)
+);
+
+CREATE TABLE IF NOT EXISTS trades (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	entry_price TEXT NOT NULL,
+	exit_price TEXT NOT NULL,
+	quantity TEXT NOT NULL,
+	fees TEXT NOT NULL,
+	pnl TEXT NOT NULL,
+	pnl_percent TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	opened_at TIMESTAMP,
+	closed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
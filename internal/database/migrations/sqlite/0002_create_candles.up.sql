@@ -0,0 +1,18 @@
+-- Histórico local de candles fechados, gravado a cada avaliação e pelo comando backfill.
+-- Os horários são os da Binance, em milissegundos desde a época Unix.
+CREATE TABLE IF NOT EXISTS candles (
+	symbol TEXT NOT NULL,
+	"interval" TEXT NOT NULL,
+	open_time INTEGER NOT NULL,
+	close_time INTEGER NOT NULL,
+	open REAL NOT NULL,
+	high REAL NOT NULL,
+	low REAL NOT NULL,
+	close REAL NOT NULL,
+	volume REAL NOT NULL,
+	quote_volume REAL NOT NULL,
+	trades INTEGER NOT NULL,
+	taker_buy_base_volume REAL NOT NULL,
+	taker_buy_quote_volume REAL NOT NULL,
+	PRIMARY KEY (symbol, "interval", open_time)
+);
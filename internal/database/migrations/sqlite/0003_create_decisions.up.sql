@@ -0,0 +1,23 @@
+-- Diário de decisões: uma linha por avaliação de cada par, com o preço, os
+-- indicadores, os sinais da estratégia e a ação tomada ou o motivo de não agir.
+CREATE TABLE IF NOT EXISTS decisions (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	symbol TEXT NOT NULL,
+	mode TEXT NOT NULL,
+	strategy TEXT NOT NULL,
+	evaluated_at TIMESTAMP NOT NULL,
+	candle_time INTEGER NOT NULL DEFAULT 0,
+	price TEXT NOT NULL DEFAULT '0',
+	rsi REAL,
+	sma REAL,
+	trend_strength REAL,
+	indicators TEXT NOT NULL DEFAULT '{}',
+	is_opened BOOLEAN NOT NULL,
+	should_enter BOOLEAN,
+	should_exit BOOLEAN,
+	action TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	error TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX IF NOT EXISTS decisions_symbol_mode_evaluated_at_idx ON decisions (symbol, mode, evaluated_at);
@@ -0,0 +1 @@
+ALTER TABLE positions DROP COLUMN highest_price;
@@ -0,0 +1,597 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/shopspring/decimal"
+)
+
+// sqlStore implementa Store sobre um banco SQL (PostgreSQL ou SQLite).
+// As consultas usam parâmetros posicionais $N, aceitos pelos dois bancos, e
+// cada parâmetro aparece pela primeira vez na ordem da sua numeração.
+type sqlStore struct {
+	db *sql.DB
+	// driver identifica o banco e o diretório de suas migrações (DriverPostgres ou DriverSQLite).
+	driver string
+}
+
+// openPostgres conecta ao servidor PostgreSQL descrito em connStr.
+func openPostgres(ctx context.Context, connStr string) (*sqlStore, error) {
+	db, err := sql.Open("postgres", connStr)
+	if err != nil {
+		return nil, err
+	}
+	// sql.Open não conecta; o ping valida a configuração antes do uso.
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, driver: DriverPostgres}, nil
+}
+
+// openSQLite abre (ou cria) o banco SQLite no arquivo path.
+// As transações são iniciadas com BEGIN IMMEDIATE, que serializa as escritas
+// entre processos (ex: o bot e o comando migrate), e uma escrita concorrente
+// aguarda até 5 segundos pelo lock do arquivo.
+func openSQLite(ctx context.Context, path string) (*sqlStore, error) {
+	dsn := fmt.Sprintf("file:%s?_txlock=immediate&_busy_timeout=5000&_foreign_keys=on", path)
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+	// O SQLite permite um único escritor; uma conexão evita disputas entre os Traders.
+	db.SetMaxOpenConns(1)
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &sqlStore{db: db, driver: DriverSQLite}, nil
+}
+
+// SaveOrder registra uma nova ordem de compra ou venda no banco de dados.
+// A ordem e suas execuções (Fills) são gravadas na mesma transação.
+// Parâmetros:
+//   - order: ordem a ser registrada; são utilizados todos os campos exceto ID e CreatedAt
+//
+// Retorna erro se:
+//   - Falhar ao iniciar ou confirmar a transação
+//   - Falhar ao inserir a ordem ou alguma de suas execuções
+func (s *sqlStore) SaveOrder(ctx context.Context, order Order) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Desfaz a transação se ela não for confirmada.
+	defer tx.Rollback()
+
+	var id int64
+	err = tx.QueryRowContext(ctx, `
+		INSERT INTO orders (symbol, side, quantity, price, mode, reason, exchange_order_id, client_order_id,
+			status, executed_qty, cummulative_quote_qty, commission, commission_asset)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id
+	`, order.Symbol, order.Side, order.Quantity, order.Price, order.Mode, order.Reason, order.ExchangeOrderID,
+		order.ClientOrderID, order.Status, order.ExecutedQty, order.CummulativeQuoteQty, order.Commission,
+		order.CommissionAsset).Scan(&id)
+	if err != nil {
+		return err
+	}
+
+	for _, f := range order.Fills {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_fills (order_id, trade_id, price, quantity, commission, commission_asset)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, id, f.TradeID, f.Price, f.Quantity, f.Commission, f.CommissionAsset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetLastOrder consulta a ordem mais recente de um símbolo em um determinado lado,
+// ignorando as ordens sem resultado (OrderStatusPending) e as não aceitas pela
+// corretora (OrderStatusRejected).
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - side: direção da ordem ("BUY" ou "SELL")
+//   - mode: modo de operação da ordem ("live" ou "paper")
+//
+// Retorna:
+//   - *Order: a ordem encontrada, ou nil se não houver ordem registrada
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetLastOrder(ctx context.Context, symbol string, side string, mode string) (*Order, error) {
+	return scanOrder(s.db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE symbol = $1 AND side = $2 AND mode = $3 AND status NOT IN ($4, $5)
+		ORDER BY created_at DESC, id DESC
+		LIMIT 1
+	`, symbol, side, mode, OrderStatusPending, OrderStatusRejected))
+}
+
+// GetPendingOrders consulta as ordens de um símbolo registradas antes do envio e
+// ainda sem resultado da corretora (OrderStatusPending).
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da ordem ("live" ou "paper")
+//
+// Retorna:
+//   - []Order: as ordens encontradas, da mais antiga para a mais recente
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetPendingOrders(ctx context.Context, symbol string, mode string) ([]Order, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE symbol = $1 AND mode = $2 AND status = $3
+		ORDER BY id
+	`, symbol, mode, OrderStatusPending)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var orders []Order
+	for rows.Next() {
+		order, err := scanOrder(rows)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, *order)
+	}
+	return orders, rows.Err()
+}
+
+// GetOrderByExchangeID consulta a ordem registrada com o identificador da corretora.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da ordem ("live" ou "paper")
+//   - exchangeOrderID: identificador da ordem na corretora
+//
+// Retorna:
+//   - *Order: a ordem encontrada, ou nil se não houver ordem registrada
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetOrderByExchangeID(ctx context.Context, symbol string, mode string, exchangeOrderID int64) (*Order, error) {
+	return scanOrder(s.db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE symbol = $1 AND mode = $2 AND exchange_order_id = $3
+		ORDER BY id DESC
+		LIMIT 1
+	`, symbol, mode, exchangeOrderID))
+}
+
+// GetOrderByClientID consulta a ordem mais recente registrada com o identificador do cliente.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da ordem ("live" ou "paper")
+//   - clientOrderID: identificador atribuído pelo cliente no envio da ordem
+//
+// Retorna:
+//   - *Order: a ordem encontrada, ou nil se não houver ordem registrada
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetOrderByClientID(ctx context.Context, symbol string, mode string, clientOrderID string) (*Order, error) {
+	return scanOrder(s.db.QueryRowContext(ctx, `
+		SELECT `+orderColumns+`
+		FROM orders
+		WHERE symbol = $1 AND mode = $2 AND client_order_id = $3
+		ORDER BY id DESC
+		LIMIT 1
+	`, symbol, mode, clientOrderID))
+}
+
+// orderColumns são as colunas de orders lidas por scanOrder, na ordem esperada.
+const orderColumns = `id, symbol, side, quantity, price, mode, reason, exchange_order_id, client_order_id,
+			status, executed_qty, cummulative_quote_qty, commission, commission_asset, created_at`
+
+// scanOrder lê uma ordem consultada com orderColumns.
+// Retorna nil, sem erro, se a consulta não encontrou nenhuma ordem.
+func scanOrder(row interface{ Scan(dest ...any) error }) (*Order, error) {
+	var order Order
+	err := row.Scan(&order.ID, &order.Symbol, &order.Side, &order.Quantity,
+		&order.Price, &order.Mode, &order.Reason, &order.ExchangeOrderID, &order.ClientOrderID,
+		&order.Status, &order.ExecutedQty, &order.CummulativeQuoteQty, &order.Commission,
+		&order.CommissionAsset, &order.CreatedAt)
+
+	// Se não houver linha, retorna nil sem erro.
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &order, nil
+}
+
+// UpdateOrder atualiza o resultado de uma ordem já registrada (ex: execuções
+// informadas depois da criação) e acrescenta as novas execuções (Fills) na mesma transação.
+// Parâmetros:
+//   - order: ordem identificada por ID; são utilizados Quantity, Price, ExchangeOrderID,
+//     Status, ExecutedQty, CummulativeQuoteQty, Commission, CommissionAsset e Fills
+//
+// Retorna erro se a ordem não existir ou se alguma gravação falhar.
+func (s *sqlStore) UpdateOrder(ctx context.Context, order Order) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Desfaz a transação se ela não for confirmada.
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+		UPDATE orders
+		SET quantity = $1, price = $2, exchange_order_id = $3, status = $4, executed_qty = $5,
+			cummulative_quote_qty = $6, commission = $7, commission_asset = $8
+		WHERE id = $9
+	`, order.Quantity, order.Price, order.ExchangeOrderID, order.Status, order.ExecutedQty,
+		order.CummulativeQuoteQty, order.Commission, order.CommissionAsset, order.ID)
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return fmt.Errorf("ordem %d não encontrada", order.ID)
+	}
+
+	for _, f := range order.Fills {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO order_fills (order_id, trade_id, price, quantity, commission, commission_asset)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, order.ID, f.TradeID, f.Price, f.Quantity, f.Commission, f.CommissionAsset)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// OpenPosition marca a posição de um símbolo como aberta, registrando o preço de
+// entrada, a quantidade e as taxas. Utiliza a cláusula ON CONFLICT para garantir
+// que existe apenas uma posição por símbolo e modo.
+// Parâmetros:
+//   - pos: posição aberta; são utilizados os campos Symbol, Mode, EntryPrice, Quantity e Fees
+//
+// A data de abertura é a data atual do banco, a data de encerramento é apagada e o
+// maior preço passa a ser o preço de entrada.
+func (s *sqlStore) OpenPosition(ctx context.Context, pos Position) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO positions (symbol, mode, is_opened, entry_price, quantity, fees, highest_price, opened_at, closed_at, updated_at)
+		VALUES ($1, $2, TRUE, $3, $4, $5, $3, CURRENT_TIMESTAMP, NULL, CURRENT_TIMESTAMP)
+		ON CONFLICT (symbol, mode)
+		DO UPDATE SET is_opened = TRUE, entry_price = $3, quantity = $4, fees = $5, highest_price = $3,
+			opened_at = CURRENT_TIMESTAMP, closed_at = NULL, updated_at = CURRENT_TIMESTAMP
+	`, pos.Symbol, pos.Mode, pos.EntryPrice, pos.Quantity, pos.Fees)
+	return err
+}
+
+// UpdatePosition atualiza o preço de entrada, a quantidade e as taxas da posição aberta
+// de um símbolo (ex: após a venda de parte da posição), mantendo o horário de abertura.
+// Uma posição fechada ou inexistente não é alterada.
+func (s *sqlStore) UpdatePosition(ctx context.Context, pos Position) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE positions
+		SET entry_price = $1, quantity = $2, fees = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE symbol = $4 AND mode = $5 AND is_opened = TRUE
+	`, pos.EntryPrice, pos.Quantity, pos.Fees, pos.Symbol, pos.Mode)
+	return err
+}
+
+// UpdateHighestPrice registra o maior preço observado desde a abertura da posição
+// aberta de um símbolo, acompanhado pelo trailing stop.
+// Uma posição fechada ou inexistente não é alterada.
+func (s *sqlStore) UpdateHighestPrice(ctx context.Context, symbol string, mode string, price decimal.Decimal) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE positions
+		SET highest_price = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE symbol = $2 AND mode = $3 AND is_opened = TRUE
+	`, price, symbol, mode)
+	return err
+}
+
+// ClosePosition marca a posição de um símbolo como fechada e, se trade não for nil,
+// registra a operação encerrada no histórico de trades na mesma transação.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da posição ("live" ou "paper")
+//   - trade: operação encerrada, ou nil se o resultado não puder ser calculado
+//
+// A data de encerramento é a data atual do banco.
+func (s *sqlStore) ClosePosition(ctx context.Context, symbol string, mode string, trade *ClosedTrade) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Desfaz a transação se ela não for confirmada.
+	defer tx.Rollback()
+
+	if trade != nil {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO trades (symbol, mode, entry_price, exit_price, quantity, fees, pnl, pnl_percent, reason, opened_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		`, symbol, mode, trade.EntryPrice, trade.ExitPrice, trade.Quantity, trade.Fees, trade.PnL,
+			trade.PnLPercent, trade.Reason, nullTime(trade.OpenedAt))
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO positions (symbol, mode, is_opened, closed_at, updated_at)
+		VALUES ($1, $2, FALSE, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
+		ON CONFLICT (symbol, mode)
+		DO UPDATE SET is_opened = FALSE, closed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+	`, symbol, mode)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// GetOpenPosition consulta a posição aberta de um determinado símbolo.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da posição ("live" ou "paper")
+//
+// Retorna:
+//   - *Position: a posição aberta, ou nil se a posição estiver fechada ou não existir
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetOpenPosition(ctx context.Context, symbol string, mode string) (*Position, error) {
+	var pos Position
+	var openedAt, closedAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id, symbol, mode, is_opened, entry_price, quantity, fees, highest_price, opened_at, closed_at, updated_at
+		FROM positions
+		WHERE symbol = $1 AND mode = $2 AND is_opened
+	`, symbol, mode).Scan(&pos.ID, &pos.Symbol, &pos.Mode, &pos.IsOpened, &pos.EntryPrice,
+		&pos.Quantity, &pos.Fees, &pos.HighestPrice, &openedAt, &closedAt, &pos.UpdatedAt)
+
+	// Se não houver linha, retorna nil sem erro.
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	pos.OpenedAt = openedAt.Time
+	pos.ClosedAt = closedAt.Time
+	return &pos, nil
+}
+
+// GetRealizedPnL retorna a soma do resultado realizado das operações encerradas de um símbolo.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação ("live" ou "paper")
+//
+// Retorna:
+//   - decimal.Decimal: resultado acumulado no ativo de cotação (zero se não houver operações)
+//   - int: quantidade de operações encerradas
+//   - error: erro em caso de falha na consulta ao banco
+func (s *sqlStore) GetRealizedPnL(ctx context.Context, symbol string, mode string) (decimal.Decimal, int, error) {
+	// A soma é feita aqui, e não com SUM, pois o SQLite converteria os valores
+	// para ponto flutuante.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT pnl FROM trades
+		WHERE symbol = $1 AND mode = $2
+	`, symbol, mode)
+	if err != nil {
+		return decimal.Zero, 0, err
+	}
+	defer rows.Close()
+
+	pnl := decimal.Zero
+	count := 0
+	for rows.Next() {
+		var value decimal.Decimal
+		if err := rows.Scan(&value); err != nil {
+			return decimal.Zero, 0, err
+		}
+		pnl = pnl.Add(value)
+		count++
+	}
+	return pnl, count, rows.Err()
+}
+
+// SaveCandles grava os candles em uma transação. Utiliza a cláusula ON CONFLICT
+// para atualizar os candles já registrados, tornando a gravação idempotente.
+// Parâmetros:
+//   - candles: candles fechados a serem gravados
+//
+// Retorna erro se falhar ao gravar algum candle; nesse caso nenhum é gravado.
+func (s *sqlStore) SaveCandles(ctx context.Context, candles []Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	// Desfaz a transação se ela não for confirmada.
+	defer tx.Rollback()
+
+	stmt, err := tx.PrepareContext(ctx, `
+		INSERT INTO candles (symbol, "interval", open_time, close_time, open, high, low, close, volume,
+			quote_volume, trades, taker_buy_base_volume, taker_buy_quote_volume)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (symbol, "interval", open_time)
+		DO UPDATE SET close_time = $4, open = $5, high = $6, low = $7, close = $8, volume = $9,
+			quote_volume = $10, trades = $11, taker_buy_base_volume = $12, taker_buy_quote_volume = $13
+	`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, c := range candles {
+		_, err := stmt.ExecContext(ctx, c.Symbol, c.Interval, c.OpenTime, c.CloseTime, c.Open, c.High, c.Low,
+			c.Close, c.Volume, c.QuoteVolume, c.Trades, c.TakerBuyBaseVolume, c.TakerBuyQuoteVolume)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCandles consulta o histórico de candles de um símbolo.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - interval: intervalo dos candles (ex: "15m")
+//   - start, end: período de abertura dos candles, com start inclusive e end exclusive
+//
+// Retorna os candles do mais antigo para o mais recente, ou erro em caso de falha na consulta.
+func (s *sqlStore) GetCandles(ctx context.Context, symbol string, interval string, start, end time.Time) ([]Candle, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT symbol, "interval", open_time, close_time, open, high, low, close, volume,
+			quote_volume, trades, taker_buy_base_volume, taker_buy_quote_volume
+		FROM candles
+		WHERE symbol = $1 AND "interval" = $2 AND open_time >= $3 AND open_time < $4
+		ORDER BY open_time
+	`, symbol, interval, start.UnixMilli(), end.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var candles []Candle
+	for rows.Next() {
+		var c Candle
+		err := rows.Scan(&c.Symbol, &c.Interval, &c.OpenTime, &c.CloseTime, &c.Open, &c.High, &c.Low,
+			&c.Close, &c.Volume, &c.QuoteVolume, &c.Trades, &c.TakerBuyBaseVolume, &c.TakerBuyQuoteVolume)
+		if err != nil {
+			return nil, err
+		}
+		candles = append(candles, c)
+	}
+	return candles, rows.Err()
+}
+
+// SaveDecision registra uma avaliação no diário de decisões.
+// Parâmetros:
+//   - d: decisão registrada; são utilizados todos os campos exceto ID
+//
+// Os indicadores rsi, sma e trend_strength são gravados também em colunas
+// próprias, para facilitar consultas; os demais ficam apenas na coluna indicators (JSON).
+func (s *sqlStore) SaveDecision(ctx context.Context, d Decision) error {
+	indicators, err := json.Marshal(d.Indicators)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO decisions (symbol, mode, strategy, evaluated_at, candle_time, price, rsi, sma, trend_strength,
+			indicators, is_opened, should_enter, should_exit, action, reason, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
+	`, d.Symbol, d.Mode, d.Strategy, d.EvaluatedAt.UTC(), d.CandleTime, d.Price, indicator(d.Indicators, "rsi"),
+		indicator(d.Indicators, "sma"), indicator(d.Indicators, "trend_strength"), string(indicators), d.IsOpened,
+		nullBool(d.ShouldEnter), nullBool(d.ShouldExit), d.Action, d.Reason, d.Error)
+	return err
+}
+
+// GetDecisions consulta o diário de decisões de um símbolo.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação ("live" ou "paper")
+//   - limit: quantidade máxima de decisões retornadas
+//
+// Retorna as decisões da mais recente para a mais antiga, ou erro em caso de falha na consulta.
+func (s *sqlStore) GetDecisions(ctx context.Context, symbol string, mode string, limit int) ([]Decision, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, symbol, mode, strategy, evaluated_at, candle_time, price, indicators, is_opened,
+			should_enter, should_exit, action, reason, error
+		FROM decisions
+		WHERE symbol = $1 AND mode = $2
+		ORDER BY evaluated_at DESC, id DESC
+		LIMIT $3
+	`, symbol, mode, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var decisions []Decision
+	for rows.Next() {
+		var d Decision
+		var indicators string
+		var shouldEnter, shouldExit sql.NullBool
+		err := rows.Scan(&d.ID, &d.Symbol, &d.Mode, &d.Strategy, &d.EvaluatedAt, &d.CandleTime, &d.Price,
+			&indicators, &d.IsOpened, &shouldEnter, &shouldExit, &d.Action, &d.Reason, &d.Error)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(indicators), &d.Indicators); err != nil {
+			return nil, fmt.Errorf("indicadores da decisão %d inválidos: %w", d.ID, err)
+		}
+		d.ShouldEnter = boolPtr(shouldEnter)
+		d.ShouldExit = boolPtr(shouldExit)
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
+
+// indicator retorna o indicador key, ou NULL se a estratégia não o calcula.
+func indicator(indicators map[string]float64, key string) sql.NullFloat64 {
+	v, ok := indicators[key]
+	return sql.NullFloat64{Float64: v, Valid: ok}
+}
+
+// nullBool converte um *bool nil em NULL.
+func nullBool(b *bool) sql.NullBool {
+	if b == nil {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: *b, Valid: true}
+}
+
+// boolPtr converte NULL em um *bool nil.
+func boolPtr(b sql.NullBool) *bool {
+	if !b.Valid {
+		return nil
+	}
+	return &b.Bool
+}
+
+// nullTime converte o valor zero de time.Time em NULL.
+func nullTime(t time.Time) sql.NullTime {
+	return sql.NullTime{Time: t, Valid: !t.IsZero()}
+}
+
+// GetPosition consulta o estado atual da posição para um determinado símbolo.
+// Parâmetros:
+//   - symbol: identificador do par de moedas (ex: "BTCUSDT")
+//   - mode: modo de operação da posição ("live" ou "paper")
+//
+// Retorna:
+//   - bool: true se existe uma posição aberta, false se fechada ou inexistente
+//   - error: erro em caso de falha na consulta ao banco
+//
+// Comportamento especial:
+//   - Se não existir posição para o símbolo, retorna (false, nil)
+//   - Se ocorrer erro na consulta, retorna (false, erro)
+func (s *sqlStore) GetPosition(ctx context.Context, symbol string, mode string) (bool, error) {
+	var isOpened bool
+	// Executa a consulta e mapeia o resultado para a variável isOpened.
+	err := s.db.QueryRowContext(ctx, `
+		SELECT is_opened FROM positions
+		WHERE symbol = $1 AND mode = $2
+	`, symbol, mode).Scan(&isOpened)
+
+	// Se não houver linha, retorna false sem erro.
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return isOpened, err
+}
+
+// Close finaliza a conexão com o banco de dados de forma segura.
+func (s *sqlStore) Close() error {
+	return s.db.Close()
+}
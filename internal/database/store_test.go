@@ -0,0 +1,390 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// dec converte um literal de teste em decimal.Decimal.
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+// testStores retorna os Stores exercitados pelos testes: memória, SQLite e,
+// se DB_TEST_POSTGRES contiver uma connection string, PostgreSQL.
+// Os bancos SQL são criados vazios e com as migrações aplicadas.
+func testStores(t *testing.T) map[string]Store {
+	ctx := context.Background()
+	stores := map[string]Store{DriverMemory: NewMemoryStore()}
+
+	sqlite, err := openSQLite(ctx, filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores[DriverSQLite] = sqlite
+
+	if connStr := os.Getenv("DB_TEST_POSTGRES"); connStr != "" {
+		pg, err := openPostgres(ctx, connStr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Recria o esquema para que os testes partam de tabelas vazias
+		if _, err := pg.MigrateDown(ctx, 1<<30); err != nil {
+			t.Fatal(err)
+		}
+		stores[DriverPostgres] = pg
+	}
+
+	for _, s := range stores {
+		if m, ok := s.(Migrator); ok {
+			if _, err := m.MigrateUp(ctx); err != nil {
+				t.Fatal(err)
+			}
+		}
+		t.Cleanup(func() { s.Close() })
+	}
+	return stores
+}
+
+func TestStore_Orders(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			order, err := s.GetLastOrder(ctx, "BTCUSDT", "BUY", "live")
+			if err != nil || order != nil {
+				t.Fatalf("esperado nenhuma ordem, obteve %+v, erro: %v", order, err)
+			}
+
+			first := Order{Symbol: "BTCUSDT", Side: "BUY", Quantity: dec("0.001"), Price: dec("65000"), Mode: "live"}
+			last := Order{
+				Symbol:              "BTCUSDT",
+				Side:                "BUY",
+				Quantity:            dec("0.00012345"),
+				Price:               dec("65003.064775212636695"),
+				Mode:                "live",
+				Reason:              "strategy",
+				ExchangeOrderID:     42,
+				ClientOrderID:       "abc",
+				Status:              "FILLED",
+				ExecutedQty:         dec("0.00012345"),
+				CummulativeQuoteQty: dec("8.02462835"),
+				Commission:          dec("0.00000012"),
+				CommissionAsset:     "BTC",
+				Fills: []Fill{
+					{TradeID: 7, Price: dec("65003.06"), Quantity: dec("0.00012345"), Commission: dec("0.00000012"), CommissionAsset: "BTC"},
+				},
+			}
+			paper := Order{Symbol: "BTCUSDT", Side: "BUY", Quantity: dec("1"), Price: dec("1"), Mode: "paper"}
+			rejected := Order{Symbol: "BTCUSDT", Side: "BUY", Quantity: dec("0.002"), Price: dec("65100"), Mode: "live", Status: OrderStatusRejected}
+			for _, o := range []Order{first, last, paper, rejected} {
+				if err := s.SaveOrder(ctx, o); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := s.GetLastOrder(ctx, "BTCUSDT", "BUY", "live")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || got.ID == 0 || got.CreatedAt.IsZero() {
+				t.Fatalf("ordem registrada incorretamente: %+v", got)
+			}
+			if !got.Price.Equal(last.Price) || !got.Quantity.Equal(last.Quantity) ||
+				!got.CummulativeQuoteQty.Equal(last.CummulativeQuoteQty) || !got.Commission.Equal(last.Commission) {
+				t.Errorf("valores perderam precisão: %+v", got)
+			}
+			if got.Reason != last.Reason || got.ExchangeOrderID != last.ExchangeOrderID ||
+				got.ClientOrderID != last.ClientOrderID || got.Status != last.Status || got.CommissionAsset != last.CommissionAsset {
+				t.Errorf("campos da ordem diferentes do registrado: %+v", got)
+			}
+		})
+	}
+}
+
+func TestStore_UpdateOrder(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if order, err := s.GetOrderByExchangeID(ctx, "BTCUSDT", "live", 42); err != nil || order != nil {
+				t.Fatalf("esperado nenhuma ordem, obteve %+v, erro: %v", order, err)
+			}
+
+			// Ordem registrada antes do envio, ainda sem o identificador da corretora
+			if err := s.SaveOrder(ctx, Order{
+				Symbol: "BTCUSDT", Side: "BUY", Quantity: dec("0.003"), Price: dec("65000"), Mode: "live",
+				ClientOrderID: "cb_BTCUSDT_b_1", Status: OrderStatusPending,
+			}); err != nil {
+				t.Fatal(err)
+			}
+			pending, err := s.GetOrderByClientID(ctx, "BTCUSDT", "live", "cb_BTCUSDT_b_1")
+			if err != nil || pending == nil || pending.Status != OrderStatusPending {
+				t.Fatalf("ordem não encontrada pelo identificador do cliente: %+v, erro: %v", pending, err)
+			}
+			if other, _ := s.GetOrderByClientID(ctx, "BTCUSDT", "live", "cb_BTCUSDT_b_2"); other != nil {
+				t.Errorf("ordem encontrada com outro identificador do cliente: %+v", other)
+			}
+			if last, _ := s.GetLastOrder(ctx, "BTCUSDT", "BUY", "live"); last != nil {
+				t.Errorf("ordem sem resultado retornada como a última ordem: %+v", last)
+			}
+			if orders, err := s.GetPendingOrders(ctx, "BTCUSDT", "live"); err != nil || len(orders) != 1 || orders[0].ID != pending.ID {
+				t.Errorf("GetPendingOrders() = %+v, %v; esperada a ordem %d", orders, err, pending.ID)
+			}
+			pending.Quantity = dec("0.002")
+			pending.ExchangeOrderID = 42
+			pending.Status = "NEW"
+			if err := s.UpdateOrder(ctx, *pending); err != nil {
+				t.Fatal(err)
+			}
+
+			order, err := s.GetOrderByExchangeID(ctx, "BTCUSDT", "live", 42)
+			if err != nil || order == nil {
+				t.Fatalf("ordem não encontrada pelo identificador da corretora: %+v, erro: %v", order, err)
+			}
+			if orders, err := s.GetPendingOrders(ctx, "BTCUSDT", "live"); err != nil || len(orders) != 0 {
+				t.Errorf("GetPendingOrders() = %+v, %v; esperado nenhuma ordem", orders, err)
+			}
+			if other, _ := s.GetOrderByExchangeID(ctx, "BTCUSDT", "paper", 42); other != nil {
+				t.Error("ordem do modo live encontrada no modo paper")
+			}
+
+			order.Price = dec("65010.5")
+			order.Status = "FILLED"
+			order.ExecutedQty = dec("0.002")
+			order.CummulativeQuoteQty = dec("130.021")
+			order.Commission = dec("0.000002")
+			order.CommissionAsset = "BTC"
+			order.Fills = []Fill{{TradeID: 9, Price: dec("65010.5"), Quantity: dec("0.002"), Commission: dec("0.000002"), CommissionAsset: "BTC"}}
+			if err := s.UpdateOrder(ctx, *order); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.GetOrderByExchangeID(ctx, "BTCUSDT", "live", 42)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Status != "FILLED" || !got.Price.Equal(order.Price) || !got.ExecutedQty.Equal(order.ExecutedQty) ||
+				!got.CummulativeQuoteQty.Equal(order.CummulativeQuoteQty) || !got.Commission.Equal(order.Commission) ||
+				got.CommissionAsset != "BTC" || !got.Quantity.Equal(dec("0.002")) || got.ClientOrderID != "cb_BTCUSDT_b_1" {
+				t.Errorf("ordem atualizada incorretamente: %+v", got)
+			}
+
+			if err := s.UpdateOrder(ctx, Order{ID: 9999}); err == nil {
+				t.Error("atualização de ordem inexistente aceita, esperado erro")
+			}
+		})
+	}
+}
+
+func TestStore_Positions(t *testing.T) {
+	ctx := context.Background()
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			if opened, err := s.GetPosition(ctx, "ETHUSDT", "live"); err != nil || opened {
+				t.Fatalf("esperado posição inexistente, obteve %v, erro: %v", opened, err)
+			}
+
+			pos := Position{Symbol: "ETHUSDT", Mode: "live", EntryPrice: dec("3000.12"), Quantity: dec("0.0499"), Fees: dec("0.15")}
+			if err := s.OpenPosition(ctx, pos); err != nil {
+				t.Fatal(err)
+			}
+			if opened, err := s.GetPosition(ctx, "ETHUSDT", "live"); err != nil || !opened {
+				t.Fatalf("esperado posição aberta, obteve %v, erro: %v", opened, err)
+			}
+			if opened, _ := s.GetPosition(ctx, "ETHUSDT", "paper"); opened {
+				t.Error("posição do modo live aberta no modo paper")
+			}
+
+			got, err := s.GetOpenPosition(ctx, "ETHUSDT", "live")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got == nil || !got.IsOpened || got.OpenedAt.IsZero() || !got.ClosedAt.IsZero() {
+				t.Fatalf("posição aberta incorretamente: %+v", got)
+			}
+			if !got.EntryPrice.Equal(pos.EntryPrice) || !got.Quantity.Equal(pos.Quantity) || !got.Fees.Equal(pos.Fees) ||
+				!got.HighestPrice.Equal(pos.EntryPrice) {
+				t.Errorf("valores da posição diferentes do registrado: %+v", got)
+			}
+
+			// A máxima do trailing stop é mantida pela atualização da posição
+			if err := s.UpdateHighestPrice(ctx, "ETHUSDT", "live", dec("3150.5")); err != nil {
+				t.Fatal(err)
+			}
+
+			// A venda de parte da posição mantém o horário de abertura
+			partial := Position{Symbol: "ETHUSDT", Mode: "live", EntryPrice: dec("3000.12"), Quantity: dec("0.0299"), Fees: dec("0.09")}
+			if err := s.UpdatePosition(ctx, partial); err != nil {
+				t.Fatal(err)
+			}
+			updated, err := s.GetOpenPosition(ctx, "ETHUSDT", "live")
+			if err != nil || updated == nil || !updated.Quantity.Equal(partial.Quantity) || !updated.Fees.Equal(partial.Fees) ||
+				!updated.OpenedAt.Equal(got.OpenedAt) || !updated.HighestPrice.Equal(dec("3150.5")) {
+				t.Errorf("posição atualizada incorretamente: %+v, erro: %v", updated, err)
+			}
+
+			trade := &ClosedTrade{
+				EntryPrice: dec("3000.12"),
+				ExitPrice:  dec("3100"),
+				Quantity:   dec("0.0499"),
+				Fees:       dec("0.3"),
+				PnL:        dec("4.684012"),
+				PnLPercent: dec("3.12"),
+				Reason:     "take_profit",
+				OpenedAt:   got.OpenedAt,
+			}
+			if err := s.ClosePosition(ctx, "ETHUSDT", "live", trade); err != nil {
+				t.Fatal(err)
+			}
+			// Uma posição fechada sem operação calculada não altera o PnL
+			if err := s.ClosePosition(ctx, "ETHUSDT", "live", nil); err != nil {
+				t.Fatal(err)
+			}
+
+			if got, err := s.GetOpenPosition(ctx, "ETHUSDT", "live"); err != nil || got != nil {
+				t.Fatalf("esperado posição fechada, obteve %+v, erro: %v", got, err)
+			}
+			if opened, err := s.GetPosition(ctx, "ETHUSDT", "live"); err != nil || opened {
+				t.Fatalf("esperado posição fechada, obteve %v, erro: %v", opened, err)
+			}
+
+			pnl, count, err := s.GetRealizedPnL(ctx, "ETHUSDT", "live")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if count != 1 || !pnl.Equal(dec("4.684012")) {
+				t.Errorf("GetRealizedPnL() = %v, %d; esperado 4.684012, 1", pnl, count)
+			}
+			if pnl, count, _ := s.GetRealizedPnL(ctx, "ETHUSDT", "paper"); count != 0 || !pnl.IsZero() {
+				t.Errorf("GetRealizedPnL() no modo paper = %v, %d; esperado 0, 0", pnl, count)
+			}
+		})
+	}
+}
+
+func TestStore_Candles(t *testing.T) {
+	ctx := context.Background()
+	candle := func(symbol string, openTime int64, close float64) Candle {
+		return Candle{
+			Symbol: symbol, Interval: "1m", OpenTime: openTime, CloseTime: openTime + 59_999,
+			Open: 1, High: 2, Low: 0.5, Close: close, Volume: 10, QuoteVolume: 15, Trades: 3,
+			TakerBuyBaseVolume: 5, TakerBuyQuoteVolume: 7.5,
+		}
+	}
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			err := s.SaveCandles(ctx, []Candle{
+				candle("BTCUSDT", 120_000, 3),
+				candle("BTCUSDT", 0, 1),
+				candle("BTCUSDT", 60_000, 2),
+				candle("ETHUSDT", 60_000, 9),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Gravar novamente o mesmo candle substitui os valores registrados
+			if err := s.SaveCandles(ctx, []Candle{candle("BTCUSDT", 60_000, 2.5)}); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := s.GetCandles(ctx, "BTCUSDT", "1m", time.UnixMilli(0), time.UnixMilli(120_000))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("esperados 2 candles, obteve %d: %+v", len(got), got)
+			}
+			if got[0].OpenTime != 0 || got[1].OpenTime != 60_000 || got[1].Close != 2.5 {
+				t.Errorf("candles retornados incorretamente: %+v", got)
+			}
+			if got[1] != candle("BTCUSDT", 60_000, 2.5) {
+				t.Errorf("campos do candle diferentes do registrado: %+v", got[1])
+			}
+
+			if got, _ := s.GetCandles(ctx, "BTCUSDT", "15m", time.UnixMilli(0), time.UnixMilli(1_000_000)); len(got) != 0 {
+				t.Errorf("esperado nenhum candle de 15m, obteve %d", len(got))
+			}
+		})
+	}
+}
+
+func TestStore_Decisions(t *testing.T) {
+	ctx := context.Background()
+	evaluatedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	enter := true
+
+	for name, s := range testStores(t) {
+		t.Run(name, func(t *testing.T) {
+			decisions := []Decision{
+				{
+					Symbol: "BTCUSDT", Mode: "paper", Strategy: "rsi", EvaluatedAt: evaluatedAt,
+					Action: ActionSkip, Reason: "pausado", Error: "timeout",
+				},
+				{
+					Symbol: "BTCUSDT", Mode: "paper", Strategy: "combined", EvaluatedAt: evaluatedAt.Add(time.Minute),
+					CandleTime: 1704110400000, Price: dec("42000.12345678"),
+					Indicators:  map[string]float64{"rsi": 28.5, "sma": 41000, "trend_strength": 2.44},
+					ShouldEnter: &enter, Action: ActionBuy, Reason: "strategy",
+				},
+			}
+			for _, d := range decisions {
+				if err := s.SaveDecision(ctx, d); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := s.GetDecisions(ctx, "BTCUSDT", "paper", 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != 2 {
+				t.Fatalf("esperadas 2 decisões, obteve %d", len(got))
+			}
+			last := got[0]
+			if last.ID == 0 || last.Action != ActionBuy || last.Reason != "strategy" || !last.EvaluatedAt.Equal(evaluatedAt.Add(time.Minute)) {
+				t.Errorf("decisão mais recente incorreta: %+v", last)
+			}
+			if !last.Price.Equal(dec("42000.12345678")) || last.CandleTime != 1704110400000 || last.Indicators["rsi"] != 28.5 {
+				t.Errorf("dados da avaliação diferentes do registrado: %+v", last)
+			}
+			if last.ShouldEnter == nil || !*last.ShouldEnter || last.ShouldExit != nil {
+				t.Errorf("sinais da estratégia diferentes do registrado: enter=%v exit=%v", last.ShouldEnter, last.ShouldExit)
+			}
+			if got[1].Error != "timeout" || got[1].ShouldEnter != nil {
+				t.Errorf("decisão sem avaliação registrada incorretamente: %+v", got[1])
+			}
+
+			if got, _ := s.GetDecisions(ctx, "BTCUSDT", "paper", 1); len(got) != 1 {
+				t.Errorf("limite ignorado: obteve %d decisões", len(got))
+			}
+		})
+	}
+}
+
+func TestOpen_InvalidDriver(t *testing.T) {
+	t.Setenv("DB_DRIVER", "mysql")
+	if _, err := Open(context.Background()); err == nil {
+		t.Error("esperado erro para driver desconhecido")
+	}
+}
+
+func TestInitialize_SQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", DriverSQLite)
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "bot.db"))
+
+	s, err := Initialize(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// O esquema deve estar pronto para uso
+	if _, err := s.GetPosition(context.Background(), "BTCUSDT", "live"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,629 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/utils"
+	"github.com/shopspring/decimal"
+)
+
+// httpClient é o cliente HTTP compartilhado pelas instâncias de Binance, reaproveitando
+// as conexões; o timeout limita cada tentativa, incluindo a leitura da resposta.
+var httpClient = &http.Client{Timeout: 10 * time.Second}
+
+// Binance implementa Exchange sobre a API REST da Binance.
+// Requisições GET, que não alteram a conta, são repetidas após falhas de rede,
+// timeouts, erros 5xx e 429, com esperas crescentes entre MinBackoff e MaxBackoff;
+// as demais (ex: envio de ordens) são enviadas uma única vez, para não duplicar a operação.
+// O peso consumido informado pela Binance é acompanhado para aguardar a janela
+// seguinte antes de atingir o limite, e os bloqueios por Retry-After são respeitados.
+// As requisições assinadas usam o horário da Binance, sincronizado periodicamente.
+type Binance struct {
+	Retries          int           // Tentativas adicionais de uma requisição GET
+	MinBackoff       time.Duration // Espera antes da primeira nova tentativa
+	MaxBackoff       time.Duration // Espera máxima entre tentativas
+	RecvWindow       time.Duration // Validade das requisições assinadas na Binance (0 usa o padrão da Binance, 5s)
+	TimeSyncInterval time.Duration // Intervalo entre as sincronizações do relógio com a Binance (0 desativa)
+
+	baseURL   string
+	apiKey    string
+	apiSecret string
+	client    *http.Client
+	limiter   *weightLimiter
+	clock     serverClock
+}
+
+// NewBinance cria um cliente para a API REST da Binance
+// Parâmetros:
+// - baseURL: endpoint base da API (ex: https://api.binance.com)
+// - apiKey: chave pública da API
+// - apiSecret: chave privada usada para assinar as requisições
+func NewBinance(baseURL, apiKey, apiSecret string) *Binance {
+	return &Binance{
+		baseURL:   strings.TrimRight(baseURL, "/"),
+		apiKey:    apiKey,
+		apiSecret: apiSecret,
+		client:    httpClient,
+		limiter:   newWeightLimiter(),
+
+		Retries:          3,
+		MinBackoff:       500 * time.Millisecond,
+		MaxBackoff:       5 * time.Second,
+		TimeSyncInterval: 10 * time.Minute,
+	}
+}
+
+// binanceOrder é o formato JSON de uma ordem retornado pela Binance.
+type binanceOrder struct {
+	Symbol              string `json:"symbol"`
+	OrderID             int64  `json:"orderId"`
+	ClientOrderID       string `json:"clientOrderId"`
+	Side                string `json:"side"`
+	Type                string `json:"type"`
+	Status              string `json:"status"`
+	OrigQty             string `json:"origQty"`
+	ExecutedQty         string `json:"executedQty"`
+	CummulativeQuoteQty string `json:"cummulativeQuoteQty"`
+	TransactTime        int64  `json:"transactTime"`
+	Time                int64  `json:"time"`
+	Fills               []struct {
+		TradeID         int64  `json:"tradeId"`
+		Price           string `json:"price"`
+		Qty             string `json:"qty"`
+		Commission      string `json:"commission"`
+		CommissionAsset string `json:"commissionAsset"`
+	} `json:"fills"`
+}
+
+func (o binanceOrder) toOrder() (*Order, error) {
+	transactTime := o.TransactTime
+	if transactTime == 0 {
+		transactTime = o.Time
+	}
+	var p numberParser
+	order := &Order{
+		Symbol:              o.Symbol,
+		OrderID:             o.OrderID,
+		ClientOrderID:       o.ClientOrderID,
+		Side:                o.Side,
+		Type:                o.Type,
+		Status:              o.Status,
+		OrigQty:             p.decimal(o.OrigQty),
+		ExecutedQty:         p.decimal(o.ExecutedQty),
+		CummulativeQuoteQty: p.decimal(o.CummulativeQuoteQty),
+		TransactTime:        transactTime,
+	}
+	for _, f := range o.Fills {
+		order.Fills = append(order.Fills, Fill{
+			TradeID:         f.TradeID,
+			Price:           p.decimal(f.Price),
+			Qty:             p.decimal(f.Qty),
+			Commission:      p.decimal(f.Commission),
+			CommissionAsset: f.CommissionAsset,
+		})
+	}
+	if p.err != nil {
+		return nil, fmt.Errorf("ordem %d com formato inesperado: %w", o.OrderID, p.err)
+	}
+	return order, nil
+}
+
+// GetKlines obtém os dados históricos de preços do par de moedas especificado
+// Parâmetros:
+// - symbol: par de moedas para obter dados (ex: BTCUSDT)
+// - interval: intervalo de tempo entre cada candle (ex: "15m", "1h", "4h")
+// - limit: quantidade máxima de candles a serem retornados
+//
+// Retorna erro se algum candle da resposta tiver formato inesperado.
+func (b *Binance) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]Candlestick, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", interval)
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	return b.klines(ctx, symbol, params)
+}
+
+// GetKlinesRange obtém os candles do par abertos entre start e end, inclusive
+// Parâmetros:
+// - symbol: par de moedas para obter dados (ex: BTCUSDT)
+// - interval: intervalo de tempo entre cada candle (ex: "15m", "1h", "4h")
+// - start, end: limites do horário de abertura dos candles (startTime e endTime da API)
+// - limit: quantidade máxima de candles a serem retornados (máximo de 1000 na Binance)
+//
+// Para períodos maiores que limit candles, a consulta deve ser paginada a partir
+// do horário do último candle recebido.
+func (b *Binance) GetKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time, limit int) ([]Candlestick, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", interval)
+	params.Add("startTime", fmt.Sprintf("%d", start.UnixMilli()))
+	params.Add("endTime", fmt.Sprintf("%d", end.UnixMilli()))
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	return b.klines(ctx, symbol, params)
+}
+
+// klines consulta /api/v3/klines com params e converte a resposta em candles.
+func (b *Binance) klines(ctx context.Context, symbol string, params url.Values) ([]Candlestick, error) {
+	var rawData [][]interface{}
+	if err := b.publicRequest(ctx, "/api/v3/klines", params, &rawData); err != nil {
+		return nil, err
+	}
+
+	candlesticks := make([]Candlestick, len(rawData))
+	for i, raw := range rawData {
+		c, err := parseKline(raw)
+		if err != nil {
+			return nil, fmt.Errorf("candle %d de %s com formato inesperado: %w", i, symbol, err)
+		}
+		candlesticks[i] = c
+	}
+
+	return candlesticks, nil
+}
+
+// parseKline converte uma linha de /api/v3/klines em Candlestick.
+// Os horários e a quantidade de trades são números JSON; os preços e volumes, strings.
+func parseKline(raw []interface{}) (Candlestick, error) {
+	if len(raw) < 12 {
+		return Candlestick{}, fmt.Errorf("esperados 12 campos, obtidos %d", len(raw))
+	}
+
+	var p numberParser
+	c := Candlestick{
+		OpenTime:                 p.int(raw[0]),
+		Open:                     p.string(raw[1]),
+		High:                     p.string(raw[2]),
+		Low:                      p.string(raw[3]),
+		Close:                    p.string(raw[4]),
+		Volume:                   p.string(raw[5]),
+		CloseTime:                p.int(raw[6]),
+		QuoteAssetVolume:         p.string(raw[7]),
+		NumberOfTrades:           p.int(raw[8]),
+		TakerBuyBaseAssetVolume:  p.string(raw[9]),
+		TakerBuyQuoteAssetVolume: p.string(raw[10]),
+		Ignore:                   p.string(raw[11]),
+	}
+	return c, p.err
+}
+
+// PlaceOrder envia uma nova ordem assinada para /api/v3/order
+// A resposta completa (newOrderRespType=FULL) é solicitada para obter as execuções
+// com preço e taxa de cada uma.
+// Retorna o estado da ordem informado pela Binance ou erro se a ordem for rejeitada.
+func (b *Binance) PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	params := url.Values{}
+	params.Add("symbol", req.Symbol)
+	params.Add("quantity", req.Quantity.String())
+	params.Add("side", req.Side)
+	params.Add("type", req.Type)
+	params.Add("newOrderRespType", "FULL")
+	if req.ClientOrderID != "" {
+		params.Add("newClientOrderId", req.ClientOrderID)
+	}
+
+	var raw binanceOrder
+	if err := b.signedRequest(ctx, http.MethodPost, "/api/v3/order", params, &raw); err != nil {
+		return nil, fmt.Errorf("erro na criação da ordem: %w", err)
+	}
+	return raw.toOrder()
+}
+
+// CancelOrder cancela uma ordem aberta identificada pelo orderId da Binance.
+func (b *Binance) CancelOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("orderId", fmt.Sprintf("%d", orderID))
+
+	var raw binanceOrder
+	if err := b.signedRequest(ctx, http.MethodDelete, "/api/v3/order", params, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao cancelar ordem: %w", err)
+	}
+	return raw.toOrder()
+}
+
+// GetOrder consulta o estado de uma ordem identificada pelo orderId da Binance.
+func (b *Binance) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("orderId", fmt.Sprintf("%d", orderID))
+
+	var raw binanceOrder
+	if err := b.signedRequest(ctx, http.MethodGet, "/api/v3/order", params, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao consultar ordem: %w", err)
+	}
+	return raw.toOrder()
+}
+
+// GetOrderByClientID consulta uma ordem pelo identificador do cliente (origClientOrderId).
+// A resposta não inclui as execuções (Fills).
+func (b *Binance) GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*Order, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("origClientOrderId", clientOrderID)
+
+	var raw binanceOrder
+	if err := b.signedRequest(ctx, http.MethodGet, "/api/v3/order", params, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao consultar ordem %s: %w", clientOrderID, err)
+	}
+	return raw.toOrder()
+}
+
+// GetBalances consulta os saldos da conta através de /api/v3/account.
+// Somente ativos com saldo livre ou bloqueado diferente de zero são retornados.
+func (b *Binance) GetBalances(ctx context.Context) ([]Balance, error) {
+	var account struct {
+		Balances []struct {
+			Asset  string `json:"asset"`
+			Free   string `json:"free"`
+			Locked string `json:"locked"`
+		} `json:"balances"`
+	}
+	if err := b.signedRequest(ctx, http.MethodGet, "/api/v3/account", url.Values{}, &account); err != nil {
+		return nil, fmt.Errorf("erro ao consultar saldos: %w", err)
+	}
+
+	var balances []Balance
+	var p numberParser
+	for _, raw := range account.Balances {
+		balance := Balance{
+			Asset:  raw.Asset,
+			Free:   p.decimal(raw.Free),
+			Locked: p.decimal(raw.Locked),
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("saldo de %s com formato inesperado: %w", raw.Asset, p.err)
+		}
+		if balance.Free.IsZero() && balance.Locked.IsZero() {
+			continue
+		}
+		balances = append(balances, balance)
+	}
+	return balances, nil
+}
+
+// GetExchangeInfo consulta as regras de negociação do par em /api/v3/exchangeInfo.
+func (b *Binance) GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+
+	var info struct {
+		Symbols []struct {
+			Symbol     string `json:"symbol"`
+			Status     string `json:"status"`
+			BaseAsset  string `json:"baseAsset"`
+			QuoteAsset string `json:"quoteAsset"`
+			Filters    []struct {
+				FilterType       string `json:"filterType"`
+				MinQty           string `json:"minQty"`
+				MaxQty           string `json:"maxQty"`
+				StepSize         string `json:"stepSize"`
+				MinPrice         string `json:"minPrice"`
+				MaxPrice         string `json:"maxPrice"`
+				TickSize         string `json:"tickSize"`
+				MinNotional      string `json:"minNotional"`
+				MaxNotional      string `json:"maxNotional"`
+				ApplyToMarket    bool   `json:"applyToMarket"`
+				ApplyMinToMarket bool   `json:"applyMinToMarket"`
+				ApplyMaxToMarket bool   `json:"applyMaxToMarket"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+	if err := b.publicRequest(ctx, "/api/v3/exchangeInfo", params, &info); err != nil {
+		return nil, err
+	}
+
+	for _, s := range info.Symbols {
+		if s.Symbol != symbol {
+			continue
+		}
+
+		info := &SymbolInfo{
+			Symbol:     s.Symbol,
+			Status:     s.Status,
+			BaseAsset:  s.BaseAsset,
+			QuoteAsset: s.QuoteAsset,
+		}
+		var p numberParser
+		for _, f := range s.Filters {
+			switch f.FilterType {
+			case "LOT_SIZE":
+				info.LotSize = LotSizeFilter{
+					MinQty:   p.decimal(f.MinQty),
+					MaxQty:   p.decimal(f.MaxQty),
+					StepSize: p.decimal(f.StepSize),
+				}
+			case "PRICE_FILTER":
+				info.PriceFilter = PriceFilter{
+					MinPrice: p.decimal(f.MinPrice),
+					MaxPrice: p.decimal(f.MaxPrice),
+					TickSize: p.decimal(f.TickSize),
+				}
+			case "MIN_NOTIONAL":
+				info.Notional = NotionalFilter{
+					MinNotional:   p.decimal(f.MinNotional),
+					ApplyToMarket: f.ApplyToMarket,
+				}
+			case "NOTIONAL":
+				info.Notional = NotionalFilter{
+					MinNotional:      p.decimal(f.MinNotional),
+					MaxNotional:      p.decimal(f.MaxNotional),
+					ApplyToMarket:    f.ApplyMinToMarket,
+					ApplyMaxToMarket: f.ApplyMaxToMarket,
+				}
+			}
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("filtros de %s com formato inesperado: %w", symbol, p.err)
+		}
+		return info, nil
+	}
+	return nil, fmt.Errorf("par %s não encontrado em exchangeInfo", symbol)
+}
+
+// GetMyTrades consulta as execuções mais recentes da conta no par em /api/v3/myTrades.
+// Parâmetros:
+// - symbol: par de moedas (ex: BTCUSDT)
+// - limit: quantidade máxima de execuções retornadas (máximo 1000)
+func (b *Binance) GetMyTrades(ctx context.Context, symbol string, limit int) ([]Trade, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("limit", fmt.Sprintf("%d", limit))
+
+	var raw []struct {
+		Symbol          string `json:"symbol"`
+		ID              int64  `json:"id"`
+		OrderID         int64  `json:"orderId"`
+		Price           string `json:"price"`
+		Qty             string `json:"qty"`
+		QuoteQty        string `json:"quoteQty"`
+		Commission      string `json:"commission"`
+		CommissionAsset string `json:"commissionAsset"`
+		Time            int64  `json:"time"`
+		IsBuyer         bool   `json:"isBuyer"`
+	}
+	if err := b.signedRequest(ctx, http.MethodGet, "/api/v3/myTrades", params, &raw); err != nil {
+		return nil, fmt.Errorf("erro ao consultar execuções: %w", err)
+	}
+
+	trades := make([]Trade, len(raw))
+	var p numberParser
+	for i, r := range raw {
+		trades[i] = Trade{
+			Symbol:          r.Symbol,
+			ID:              r.ID,
+			OrderID:         r.OrderID,
+			Price:           p.decimal(r.Price),
+			Qty:             p.decimal(r.Qty),
+			QuoteQty:        p.decimal(r.QuoteQty),
+			Commission:      p.decimal(r.Commission),
+			CommissionAsset: r.CommissionAsset,
+			Time:            r.Time,
+			IsBuyer:         r.IsBuyer,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("execução %d com formato inesperado: %w", r.ID, p.err)
+		}
+	}
+	return trades, nil
+}
+
+// publicRequest executa uma requisição GET sem assinatura e decodifica o JSON da resposta em out.
+// A requisição é abortada se ctx for cancelado.
+func (b *Binance) publicRequest(ctx context.Context, path string, params url.Values, out interface{}) error {
+	endpoint := b.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	return b.do(ctx, func() (*http.Request, error) {
+		return http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	}, out)
+}
+
+// keyedRequest executa uma requisição identificada apenas pela chave da API, sem
+// assinatura, como as de gerenciamento da listen key do user data stream.
+// Os parâmetros seguem na query string.
+func (b *Binance) keyedRequest(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	endpoint := b.baseURL + path
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+	return b.do(ctx, func() (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("X-MBX-APIKEY", b.apiKey)
+		return req, nil
+	}, out)
+}
+
+// signedRequest executa uma requisição autenticada com assinatura HMAC SHA256
+// O timestamp (no horário da Binance), a recvWindow e a assinatura são adicionados
+// aos parâmetros, novamente a cada tentativa. Em requisições POST os parâmetros
+// seguem no corpo; nos demais métodos, na query string.
+// Se a Binance rejeitar o timestamp (-1021), o relógio é sincronizado novamente
+// antes da próxima requisição assinada.
+func (b *Binance) signedRequest(ctx context.Context, method, path string, params url.Values, out interface{}) error {
+	err := b.do(ctx, func() (*http.Request, error) {
+		signed := url.Values{}
+		for k, v := range params {
+			signed[k] = v
+		}
+		if b.RecvWindow > 0 {
+			signed.Set("recvWindow", fmt.Sprintf("%d", b.RecvWindow.Milliseconds()))
+		}
+		signed.Set("timestamp", fmt.Sprintf("%d", b.timestamp(ctx)))
+
+		// Gera a assinatura HMAC SHA256
+		mac := hmac.New(sha256.New, []byte(b.apiSecret))
+		mac.Write([]byte(signed.Encode()))
+		signature := hex.EncodeToString(mac.Sum(nil))
+		signed.Add("signature", signature)
+
+		var req *http.Request
+		var err error
+		if method == http.MethodPost {
+			req, err = http.NewRequestWithContext(ctx, method, b.baseURL+path, strings.NewReader(signed.Encode()))
+			if err == nil {
+				req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+			}
+		} else {
+			req, err = http.NewRequestWithContext(ctx, method, b.baseURL+path+"?"+signed.Encode(), nil)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Add("X-MBX-APIKEY", b.apiKey)
+		return req, nil
+	}, out)
+
+	if errors.Is(err, ErrInvalidTimestamp) {
+		b.resetClock()
+	}
+	return err
+}
+
+// do envia a requisição criada por build, aguardando antes o limitador de peso,
+// e decodifica a resposta JSON em out. Requisições GET são repetidas, criadas
+// novamente por build, enquanto a falha for temporária (ver retryable).
+func (b *Binance) do(ctx context.Context, build func() (*http.Request, error), out interface{}) error {
+	backoff := b.MinBackoff
+	for attempt := 0; ; attempt++ {
+		if err := b.limiter.wait(ctx); err != nil {
+			return err
+		}
+		req, err := build()
+		if err != nil {
+			return err
+		}
+
+		err = b.send(req, out)
+		if err == nil || req.Method != http.MethodGet || attempt >= b.Retries || ctx.Err() != nil || !retryable(err) {
+			return err
+		}
+
+		// Espera aleatória entre metade e o total do intervalo, para que várias
+		// requisições com falha não sejam repetidas ao mesmo tempo
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("Falha na requisição %s: %v; nova tentativa em %s", req.URL.Path, err, wait.Round(time.Millisecond))
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, b.MaxBackoff)
+	}
+}
+
+// send envia a requisição, registra o peso consumido e os bloqueios informados
+// pela Binance, valida o status HTTP e decodifica a resposta JSON em out.
+func (b *Binance) send(req *http.Request, out interface{}) error {
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	b.limiter.update(resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	// Se o status não for 200, retorna o erro informado no corpo
+	if resp.StatusCode != http.StatusOK {
+		return newAPIError(resp.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, out)
+}
+
+// retryable indica se a falha é temporária: erros de rede e timeouts, respostas
+// 5xx e o limite de requisições excedido (429), cuja espera é aplicada pelo
+// limitador de peso. Erros de conteúdo da resposta não são repetidos.
+func retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= http.StatusInternalServerError || apiErr.StatusCode == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
+// numberParser converte os campos numéricos de uma resposta da Binance,
+// guardando o primeiro erro encontrado para que a resposta inteira seja
+// validada de uma vez. Após um erro, as conversões seguintes retornam zero.
+// Preços, quantidades e saldos são convertidos com decimal; os valores dos
+// candles, usados apenas nos indicadores, com parseOptional.
+type numberParser struct {
+	err error
+}
+
+// parseOptional converte campos numéricos opcionais, tratando string vazia como zero.
+func (p *numberParser) parseOptional(str string) float64 {
+	if p.err != nil || str == "" {
+		return 0
+	}
+	v, err := utils.ParseFloat(str)
+	if err != nil {
+		p.err = err
+	}
+	return v
+}
+
+// decimal converte campos numéricos opcionais sem perda de precisão, tratando string vazia como zero.
+func (p *numberParser) decimal(str string) decimal.Decimal {
+	if p.err != nil || str == "" {
+		return decimal.Zero
+	}
+	v, err := utils.ParseDecimal(str)
+	if err != nil {
+		p.err = err
+	}
+	return v
+}
+
+// string converte um campo JSON que deveria ser um número em formato string.
+func (p *numberParser) string(v interface{}) float64 {
+	str, ok := v.(string)
+	if !ok {
+		p.fail(v, "string")
+		return 0
+	}
+	return p.parseOptional(str)
+}
+
+// int converte um campo JSON que deveria ser um número inteiro.
+func (p *numberParser) int(v interface{}) int64 {
+	n, ok := v.(float64)
+	if !ok {
+		p.fail(v, "número")
+		return 0
+	}
+	return int64(n)
+}
+
+// fail registra um campo com tipo inesperado, se ainda não houver erro.
+func (p *numberParser) fail(v interface{}, want string) {
+	if p.err == nil {
+		p.err = fmt.Errorf("campo %v (%T) deveria ser %s", v, v, want)
+	}
+}
@@ -0,0 +1,362 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestBinance cria um cliente para o servidor de teste, sem a sincronização do
+// relógio, que exigiria do servidor o endpoint /api/v3/time.
+func newTestBinance(url string) *Binance {
+	b := NewBinance(url, "key", "secret")
+	b.TimeSyncInterval = 0
+	return b
+}
+
+func TestBinanceGetKlines(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/klines" {
+			t.Errorf("caminho inesperado: %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("symbol") != "BTCUSDT" || q.Get("interval") != "15m" || q.Get("limit") != "2" {
+			t.Errorf("parâmetros inesperados: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[
+			[1000,"1.0","2.0","0.5","1.5","10.0",1999,"15.0",3,"5.0","7.5","0"],
+			[2000,"1.5","2.5","1.0","2.0","20.0",2999,"40.0",4,"10.0","20.0","0"]
+		]`))
+	}))
+	defer server.Close()
+
+	b := newTestBinance(server.URL)
+	candles, err := b.GetKlines(context.Background(), "BTCUSDT", "15m", 2)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("esperado 2 candles, obteve %d", len(candles))
+	}
+	if candles[1].OpenTime != 2000 || candles[1].Close != 2.0 || candles[1].NumberOfTrades != 4 {
+		t.Errorf("candle convertido incorretamente: %+v", candles[1])
+	}
+}
+
+func TestBinanceGetKlinesRange(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := start.Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("startTime") != "1704067200000" || q.Get("endTime") != "1704070800000" || q.Get("limit") != "1000" {
+			t.Errorf("parâmetros inesperados: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[[1704067200000,"1.0","2.0","0.5","1.5","10.0",1704068099999,"15.0",3,"5.0","7.5","0"]]`))
+	}))
+	defer server.Close()
+
+	candles, err := NewBinance(server.URL, "", "").GetKlinesRange(context.Background(), "BTCUSDT", "15m", start, end, 1000)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(candles) != 1 || candles[0].OpenTime != start.UnixMilli() {
+		t.Errorf("candles convertidos incorretamente: %+v", candles)
+	}
+}
+
+func TestBinanceGetKlines_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"Should reject invalid price", `[[1000,"1.0","2.0","0.5","abc","10.0",1999,"15.0",3,"5.0","7.5","0"]]`},
+		{"Should reject unexpected field type", `[[1000,1.0,"2.0","0.5","1.5","10.0",1999,"15.0",3,"5.0","7.5","0"]]`},
+		{"Should reject short row", `[[1000,"1.0","2.0"]]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			if _, err := newTestBinance(server.URL).GetKlines(context.Background(), "BTCUSDT", "15m", 1); err == nil {
+				t.Error("esperado erro para candle malformado")
+			}
+		})
+	}
+}
+
+func TestBinanceGetKlines_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("requisição não deveria ser enviada com o contexto cancelado")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := newTestBinance(server.URL).GetKlines(ctx, "BTCUSDT", "15m", 1)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("esperado context.Canceled, obteve %v", err)
+	}
+}
+
+func TestBinancePlaceOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v3/order" {
+			t.Errorf("requisição inesperada: %s %s", r.Method, r.URL.Path)
+		}
+		if r.Header.Get("X-MBX-APIKEY") != "key" {
+			t.Errorf("header X-MBX-APIKEY ausente")
+		}
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		signature := r.PostForm.Get("signature")
+		r.PostForm.Del("signature")
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(r.PostForm.Encode()))
+		if signature != hex.EncodeToString(mac.Sum(nil)) {
+			t.Errorf("assinatura inválida")
+		}
+
+		if r.PostForm.Get("newOrderRespType") != "FULL" {
+			t.Errorf("resposta completa não solicitada: %s", r.PostForm.Encode())
+		}
+		if r.PostForm.Get("newClientOrderId") != "cb_BTCUSDT_b_1700000000" {
+			t.Errorf("identificador do cliente não enviado: %s", r.PostForm.Encode())
+		}
+
+		w.Write([]byte(`{"symbol":"BTCUSDT","orderId":42,"clientOrderId":"abc","side":"BUY","type":"MARKET",
+			"status":"FILLED","origQty":"0.00100000","executedQty":"0.00100000","cummulativeQuoteQty":"30.00000000","transactTime":1234,
+			"fills":[
+				{"price":"29990.00","qty":"0.00040000","commission":"0.00000040","commissionAsset":"BTC","tradeId":7},
+				{"price":"30006.67","qty":"0.00060000","commission":"0.00000060","commissionAsset":"BTC","tradeId":8}
+			]}`))
+	}))
+	defer server.Close()
+
+	b := newTestBinance(server.URL)
+	order, err := b.PlaceOrder(context.Background(), OrderRequest{
+		Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.001"), ClientOrderID: "cb_BTCUSDT_b_1700000000",
+	})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if order.OrderID != 42 || order.Status != "FILLED" || !order.ExecutedQty.Equal(dec("0.001")) || !order.CummulativeQuoteQty.Equal(dec("30")) {
+		t.Errorf("ordem convertida incorretamente: %+v", order)
+	}
+	if len(order.Fills) != 2 || order.Fills[1].TradeID != 8 || !order.Fills[1].Price.Equal(dec("30006.67")) {
+		t.Errorf("execuções convertidas incorretamente: %+v", order.Fills)
+	}
+	if !order.AvgPrice().Equal(dec("30000")) {
+		t.Errorf("AvgPrice() = %v, esperado 30000", order.AvgPrice())
+	}
+	if commission, asset := order.Commission(); !commission.Equal(dec("0.000001")) || asset != "BTC" {
+		t.Errorf("Commission() = %v %s, esperado 0.000001 BTC", commission, asset)
+	}
+}
+
+func TestBinancePlaceOrder_Rejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`))
+	}))
+	defer server.Close()
+
+	b := newTestBinance(server.URL)
+	_, err := b.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.001")})
+	if err == nil || !strings.Contains(err.Error(), "LOT_SIZE") {
+		t.Errorf("esperado erro contendo o corpo da resposta, obteve %v", err)
+	}
+}
+
+func TestBinanceGetBalances(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/account" {
+			t.Errorf("caminho inesperado: %s", r.URL.Path)
+		}
+		q, _ := url.ParseQuery(r.URL.RawQuery)
+		if q.Get("timestamp") == "" || q.Get("signature") == "" {
+			t.Errorf("requisição não assinada: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"balances":[
+			{"asset":"BTC","free":"0.5","locked":"0.0"},
+			{"asset":"ETH","free":"0.0","locked":"0.0"},
+			{"asset":"USDT","free":"100.0","locked":"25.0"}
+		]}`))
+	}))
+	defer server.Close()
+
+	b := newTestBinance(server.URL)
+	balances, err := b.GetBalances(context.Background())
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("esperado 2 saldos não nulos, obteve %d", len(balances))
+	}
+	if balances[1].Asset != "USDT" || !balances[1].Free.Equal(dec("100")) || !balances[1].Locked.Equal(dec("25")) {
+		t.Errorf("saldo convertido incorretamente: %+v", balances[1])
+	}
+}
+
+func TestBinanceGetMyTrades(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/myTrades" {
+			t.Errorf("caminho inesperado: %s", r.URL.Path)
+		}
+		q, _ := url.ParseQuery(r.URL.RawQuery)
+		if q.Get("symbol") != "BTCUSDT" || q.Get("limit") != "10" || q.Get("signature") == "" {
+			t.Errorf("parâmetros inesperados: %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`[{"symbol":"BTCUSDT","id":7,"orderId":42,"price":"30000.00","qty":"0.00100000",
+			"quoteQty":"30.00","commission":"0.00000100","commissionAsset":"BTC","time":1234,"isBuyer":true,"isMaker":false}]`))
+	}))
+	defer server.Close()
+
+	trades, err := newTestBinance(server.URL).GetMyTrades(context.Background(), "BTCUSDT", 10)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(trades) != 1 {
+		t.Fatalf("esperada 1 execução, obteve %d", len(trades))
+	}
+	if trades[0].OrderID != 42 || !trades[0].Qty.Equal(dec("0.001")) || !trades[0].IsBuyer || trades[0].CommissionAsset != "BTC" {
+		t.Errorf("execução convertida incorretamente: %+v", trades[0])
+	}
+}
+
+func TestBinanceGetExchangeInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"symbols":[{"symbol":"BTCUSDT","status":"TRADING","baseAsset":"BTC","quoteAsset":"USDT","filters":[
+			{"filterType":"PRICE_FILTER","minPrice":"0.01000000","maxPrice":"1000000.00000000","tickSize":"0.01000000"},
+			{"filterType":"LOT_SIZE","minQty":"0.00001000","maxQty":"9000.00000000","stepSize":"0.00001000"},
+			{"filterType":"NOTIONAL","minNotional":"5.00000000","applyMinToMarket":true,"maxNotional":"9000000.00000000","applyMaxToMarket":false}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	info, err := newTestBinance(server.URL).GetExchangeInfo(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if info.BaseAsset != "BTC" || info.QuoteAsset != "USDT" {
+		t.Errorf("ativos convertidos incorretamente: %+v", info)
+	}
+	if !info.LotSize.StepSize.Equal(dec("0.00001")) || !info.PriceFilter.TickSize.Equal(dec("0.01")) {
+		t.Errorf("filtros convertidos incorretamente: %+v", info)
+	}
+	if !info.Notional.MinNotional.Equal(dec("5")) || !info.Notional.ApplyToMarket || info.Notional.ApplyMaxToMarket {
+		t.Errorf("filtro NOTIONAL convertido incorretamente: %+v", info.Notional)
+	}
+}
+
+func TestBinance_Retry(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []int // Status das respostas, em ordem; as seguintes retornam 200
+		order    bool  // Envia uma ordem em vez de consultar os saldos
+		wantReqs int32
+		wantErr  bool
+	}{
+		{"Should retry reads after server errors", []int{http.StatusServiceUnavailable, http.StatusBadGateway}, false, 3, false},
+		{"Should give up after the configured retries", []int{500, 500, 500, 500, 500}, false, 4, true},
+		{"Should not retry client errors", []int{http.StatusBadRequest}, false, 1, true},
+		{"Should not retry orders", []int{http.StatusServiceUnavailable}, true, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := requests.Add(1)
+				if int(n) <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[n-1])
+					w.Write([]byte(`{"code":-1000,"msg":"falha"}`))
+					return
+				}
+				w.Write([]byte(`{"balances":[]}`))
+			}))
+			defer server.Close()
+
+			b := newTestBinance(server.URL)
+			b.MinBackoff = 2 * time.Millisecond
+			b.MaxBackoff = 5 * time.Millisecond
+			var err error
+			if tt.order {
+				_, err = b.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.001")})
+			} else {
+				_, err = b.GetBalances(context.Background())
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("erro = %v, esperado erro: %v", err, tt.wantErr)
+			}
+			if got := requests.Load(); got != tt.wantReqs {
+				t.Errorf("%d requisições enviadas, esperado %d", got, tt.wantReqs)
+			}
+		})
+	}
+}
+
+func TestBinance_Timeout(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	b := NewBinance(server.URL, "", "")
+	b.client = &http.Client{Timeout: 20 * time.Millisecond}
+	b.MinBackoff = time.Millisecond
+	if _, err := b.GetKlines(context.Background(), "BTCUSDT", "1m", 1); err != nil {
+		t.Errorf("erro inesperado após o timeout da primeira tentativa: %v", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("%d requisições enviadas, esperado 2", got)
+	}
+}
+
+func TestBinanceGetOrderByClientID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.Method != http.MethodGet || r.URL.Path != "/api/v3/order" || q.Get("symbol") != "BTCUSDT" || q.Get("signature") == "" {
+			t.Errorf("requisição inesperada: %s %s?%s", r.Method, r.URL.Path, r.URL.RawQuery)
+		}
+		if q.Get("origClientOrderId") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+			return
+		}
+		w.Write([]byte(`{"symbol":"BTCUSDT","orderId":42,"clientOrderId":"abc","side":"BUY","type":"MARKET",
+			"status":"FILLED","origQty":"0.001","executedQty":"0.001","cummulativeQuoteQty":"30.00"}`))
+	}))
+	defer server.Close()
+
+	b := newTestBinance(server.URL)
+	order, err := b.GetOrderByClientID(context.Background(), "BTCUSDT", "abc")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if order.OrderID != 42 || order.ClientOrderID != "abc" || !order.ExecutedQty.Equal(dec("0.001")) {
+		t.Errorf("ordem convertida incorretamente: %+v", order)
+	}
+
+	if _, err := b.GetOrderByClientID(context.Background(), "BTCUSDT", "xyz"); !errors.Is(err, ErrUnknownOrder) {
+		t.Errorf("esperado ErrUnknownOrder, obteve %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+package exchange
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// serverClock guarda a diferença estimada entre o relógio da Binance e o local,
+// usada no timestamp das requisições assinadas para que um relógio local atrasado
+// ou adiantado não cause a rejeição das requisições fora da recvWindow.
+type serverClock struct {
+	mu       sync.Mutex
+	offset   time.Duration // Horário da Binance menos o horário local
+	syncedAt time.Time     // Última sincronização; zero força uma nova sincronização
+	syncing  bool          // Se uma requisição está sincronizando o relógio
+}
+
+// timestamp retorna o horário atual da Binance em milissegundos, sincronizando
+// antes o relógio se a última sincronização for mais antiga que TimeSyncInterval
+// (0 desativa a sincronização e usa o relógio local). Se a sincronização falhar,
+// a diferença anterior é mantida e a falha registrada no log.
+//
+// Apenas uma requisição sincroniza o relógio por vez, sem bloqueá-lo durante a
+// consulta: as requisições simultâneas usam a diferença anterior, para que uma
+// sincronização lenta não atrase as demais requisições assinadas.
+func (b *Binance) timestamp(ctx context.Context) int64 {
+	if b.TimeSyncInterval == 0 {
+		return time.Now().UnixMilli()
+	}
+	b.clock.mu.Lock()
+	offset := b.clock.offset
+	stale := b.clock.syncedAt.IsZero() || time.Since(b.clock.syncedAt) >= b.TimeSyncInterval
+	resync := stale && !b.clock.syncing
+	if resync {
+		b.clock.syncing = true
+	}
+	b.clock.mu.Unlock()
+
+	if resync {
+		synced, err := b.syncClock(ctx)
+		if err != nil {
+			log.Printf("Erro ao sincronizar o relógio com a Binance: %v", err)
+		} else {
+			offset = synced
+		}
+	}
+	return time.Now().Add(offset).UnixMilli()
+}
+
+// syncClock consulta o horário da Binance, atualiza a diferença para o relógio local
+// e a retorna. O relógio fica bloqueado apenas para a gravação da nova diferença.
+func (b *Binance) syncClock(ctx context.Context) (time.Duration, error) {
+	var resp struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	sent := time.Now()
+	err := b.publicRequest(ctx, "/api/v3/time", nil, &resp)
+	received := time.Now()
+
+	b.clock.mu.Lock()
+	defer b.clock.mu.Unlock()
+	b.clock.syncing = false
+	if err != nil {
+		return 0, err
+	}
+
+	// O horário informado é considerado do meio do tempo de ida e volta da requisição
+	local := sent.Add(received.Sub(sent) / 2)
+	offset := time.UnixMilli(resp.ServerTime).Sub(local)
+	if (offset - b.clock.offset).Abs() >= time.Second {
+		log.Printf("Diferença entre o relógio da Binance e o local: %s", offset.Round(time.Millisecond))
+	}
+	b.clock.offset = offset
+	b.clock.syncedAt = received
+	return offset, nil
+}
+
+// resetClock força a sincronização do relógio antes da próxima requisição assinada.
+func (b *Binance) resetClock() {
+	b.clock.mu.Lock()
+	b.clock.syncedAt = time.Time{}
+	b.clock.mu.Unlock()
+}
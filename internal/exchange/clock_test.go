@@ -0,0 +1,107 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBinance_ServerTime(t *testing.T) {
+	const skew = 5 * time.Second
+
+	var syncs atomic.Int32
+	var reject atomic.Bool
+	timestamps := make(chan int64, 4)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/time" {
+			syncs.Add(1)
+			fmt.Fprintf(w, `{"serverTime":%d}`, time.Now().Add(skew).UnixMilli())
+			return
+		}
+		q := r.URL.Query()
+		if q.Get("recvWindow") != "10000" {
+			t.Errorf("recvWindow = %q, esperado 10000", q.Get("recvWindow"))
+		}
+		ts, _ := strconv.ParseInt(q.Get("timestamp"), 10, 64)
+		timestamps <- ts
+		if reject.Swap(false) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`))
+			return
+		}
+		w.Write([]byte(`{"balances":[]}`))
+	}))
+	defer server.Close()
+
+	b := NewBinance(server.URL, "key", "secret")
+	b.RecvWindow = 10 * time.Second
+	ctx := context.Background()
+
+	// O relógio é sincronizado na primeira requisição assinada e reaproveitado nas seguintes
+	for range 2 {
+		if _, err := b.GetBalances(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if diff := time.UnixMilli(<-timestamps).Sub(time.Now().Add(skew)).Abs(); diff > time.Second {
+			t.Errorf("timestamp a %s do horário da Binance", diff)
+		}
+	}
+	if got := syncs.Load(); got != 1 {
+		t.Errorf("%d sincronizações, esperado 1", got)
+	}
+
+	// O timestamp rejeitado força uma nova sincronização antes da próxima requisição
+	reject.Store(true)
+	if _, err := b.GetBalances(ctx); err == nil {
+		t.Error("timestamp rejeitado não retornou erro")
+	}
+	<-timestamps
+	if _, err := b.GetBalances(ctx); err != nil {
+		t.Fatal(err)
+	}
+	<-timestamps
+	if got := syncs.Load(); got != 2 {
+		t.Errorf("%d sincronizações, esperado 2 após o timestamp rejeitado", got)
+	}
+}
+
+func TestBinance_SlowServerTime(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v3/time" {
+			close(started)
+			<-release
+			fmt.Fprintf(w, `{"serverTime":%d}`, time.Now().UnixMilli())
+			return
+		}
+		w.Write([]byte(`{"balances":[]}`))
+	}))
+	defer server.Close()
+	defer close(release)
+
+	b := NewBinance(server.URL, "key", "secret")
+	syncing := make(chan error, 1)
+	go func() {
+		_, err := b.GetBalances(context.Background())
+		syncing <- err
+	}()
+	<-started
+
+	// Enquanto a sincronização aguarda a Binance, as demais requisições usam a diferença anterior
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := b.GetBalances(ctx); err != nil {
+		t.Fatalf("requisição bloqueada pela sincronização do relógio: %v", err)
+	}
+
+	release <- struct{}{}
+	if err := <-syncing; err != nil {
+		t.Fatal(err)
+	}
+}
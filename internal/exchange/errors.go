@@ -0,0 +1,114 @@
+package exchange
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Erros conhecidos da API da Binance. Use errors.Is para identificar o motivo
+// de um *APIError.
+var (
+	// ErrOrderRejected indica uma ordem rejeitada pela corretora (-2010).
+	ErrOrderRejected = errors.New("ordem rejeitada")
+	// ErrInsufficientBalance indica uma ordem rejeitada por saldo insuficiente (-2010),
+	// também informado pela simulação do modo paper.
+	ErrInsufficientBalance = errors.New("saldo insuficiente")
+	// ErrFilterFailure indica uma ordem que viola os filtros de negociação do par
+	// (-1013), também informado pelos *FilterError da validação local.
+	ErrFilterFailure = errors.New("filtro de negociação violado")
+	// ErrInvalidTimestamp indica uma requisição com o timestamp fora da recvWindow (-1021).
+	ErrInvalidTimestamp = errors.New("timestamp fora da recvWindow")
+	// ErrInvalidSignature indica uma assinatura inválida, em geral pelo segredo incorreto (-1022).
+	ErrInvalidSignature = errors.New("assinatura inválida")
+	// ErrUnauthorized indica chave da API inválida, IP não autorizado ou permissão ausente (-2015).
+	ErrUnauthorized = errors.New("chave da API, IP ou permissão não autorizados")
+	// ErrTooManyRequests indica o limite de requisições excedido (-1003).
+	ErrTooManyRequests = errors.New("limite de requisições excedido")
+	// ErrUnknownOrder indica uma ordem inexistente na corretora (-2013).
+	ErrUnknownOrder = errors.New("ordem inexistente")
+)
+
+// Códigos de erro da Binance associados aos erros conhecidos.
+const (
+	codeTooManyRequests  = -1003
+	codeFilterFailure    = -1013
+	codeInvalidTimestamp = -1021
+	codeInvalidSignature = -1022
+	codeOrderRejected    = -2010
+	codeUnknownOrder     = -2013
+	codeUnauthorized     = -2015
+)
+
+// IsDefiniteRejection indica se a falha no envio de uma ordem garante que ela não
+// foi aceita: uma rejeição da corretora com status 4xx e um código de erro conhecido
+// da Binance (ver APIError.Unwrap), ou uma validação local de saldo ou filtros.
+// Nas demais falhas (ex: timeout, status 408 ou 5xx, código -1007 de resultado
+// desconhecido, resposta sem o formato da Binance, como a página de erro de um
+// proxy) a ordem pode ter sido aceita, e seu estado deve ser consultado antes de
+// um novo envio.
+func IsDefiniteRejection(err error) bool {
+	if errors.Is(err, ErrFilterFailure) || errors.Is(err, ErrInsufficientBalance) {
+		return true
+	}
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode < http.StatusInternalServerError &&
+		apiErr.StatusCode != http.StatusRequestTimeout && apiErr.Unwrap() != nil
+}
+
+// APIError é a resposta da Binance com status HTTP diferente de 200, no formato
+// {"code":-1013,"msg":"Filter failure: LOT_SIZE"}. Respostas em outro formato
+// (ex: página de erro de um proxy) têm Code zero e o corpo em Msg.
+type APIError struct {
+	StatusCode int    // Status HTTP da resposta
+	Code       int    // Código de erro da Binance
+	Msg        string // Mensagem de erro da Binance
+}
+
+// newAPIError converte o corpo da resposta de erro com o status informado.
+func newAPIError(statusCode int, body []byte) *APIError {
+	var payload struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Code == 0 {
+		return &APIError{StatusCode: statusCode, Msg: string(body)}
+	}
+	return &APIError{StatusCode: statusCode, Code: payload.Code, Msg: payload.Msg}
+}
+
+func (e *APIError) Error() string {
+	if e.Code == 0 {
+		return fmt.Sprintf("status %d: %s", e.StatusCode, e.Msg)
+	}
+	return fmt.Sprintf("erro %d da Binance: %s (status %d)", e.Code, e.Msg, e.StatusCode)
+}
+
+// Unwrap permite comparar o erro com os erros conhecidos através de errors.Is.
+// Retorna nil para códigos sem erro conhecido associado.
+func (e *APIError) Unwrap() error {
+	switch e.Code {
+	case codeTooManyRequests:
+		return ErrTooManyRequests
+	case codeFilterFailure:
+		return ErrFilterFailure
+	case codeInvalidTimestamp:
+		return ErrInvalidTimestamp
+	case codeInvalidSignature:
+		return ErrInvalidSignature
+	case codeOrderRejected:
+		// O mesmo código é usado para várias rejeições; o saldo insuficiente
+		// é identificado pela mensagem
+		if strings.Contains(strings.ToLower(e.Msg), "insufficient balance") {
+			return ErrInsufficientBalance
+		}
+		return ErrOrderRejected
+	case codeUnknownOrder:
+		return ErrUnknownOrder
+	case codeUnauthorized:
+		return ErrUnauthorized
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+)
+
+func TestNewAPIError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		wantCode int
+		want     error
+	}{
+		{"Should map insufficient balance", 400, `{"code":-2010,"msg":"Account has insufficient balance for requested action."}`, -2010, ErrInsufficientBalance},
+		{"Should map other order rejections", 400, `{"code":-2010,"msg":"Market is closed."}`, -2010, ErrOrderRejected},
+		{"Should map filter failure", 400, `{"code":-1013,"msg":"Filter failure: LOT_SIZE"}`, -1013, ErrFilterFailure},
+		{"Should map invalid timestamp", 400, `{"code":-1021,"msg":"Timestamp for this request is outside of the recvWindow."}`, -1021, ErrInvalidTimestamp},
+		{"Should map invalid signature", 400, `{"code":-1022,"msg":"Signature for this request is not valid."}`, -1022, ErrInvalidSignature},
+		{"Should map unauthorized key", 401, `{"code":-2015,"msg":"Invalid API-key, IP, or permissions for action."}`, -2015, ErrUnauthorized},
+		{"Should map too many requests", 429, `{"code":-1003,"msg":"Too many requests."}`, -1003, ErrTooManyRequests},
+		{"Should map unknown order", 400, `{"code":-2013,"msg":"Order does not exist."}`, -2013, ErrUnknownOrder},
+		{"Should keep unknown codes", 400, `{"code":-1100,"msg":"Illegal characters found in parameter."}`, -1100, nil},
+		{"Should keep bodies outside the error format", 502, `<html>Bad Gateway</html>`, 0, nil},
+	}
+
+	known := []error{ErrOrderRejected, ErrInsufficientBalance, ErrFilterFailure, ErrInvalidTimestamp,
+		ErrInvalidSignature, ErrUnauthorized, ErrTooManyRequests, ErrUnknownOrder}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := error(newAPIError(tt.status, []byte(tt.body)))
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.Code != tt.wantCode || apiErr.StatusCode != tt.status {
+				t.Fatalf("erro convertido incorretamente: %+v", apiErr)
+			}
+			if tt.wantCode == 0 && apiErr.Msg != tt.body {
+				t.Errorf("Msg = %q, esperado o corpo da resposta", apiErr.Msg)
+			}
+			for _, k := range known {
+				if got := errors.Is(err, k); got != (k == tt.want) {
+					t.Errorf("errors.Is(%v) = %v", k, got)
+				}
+			}
+		})
+	}
+}
+
+func TestIsDefiniteRejection(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"Should accept exchange rejections", &APIError{StatusCode: 400, Code: -2010, Msg: "Account has insufficient balance for requested action."}, true},
+		{"Should accept local filter validations", &FilterError{Symbol: "BTCUSDT", Filter: ErrLotSize}, true},
+		{"Should accept simulated insufficient balance", fmt.Errorf("%w: USDT", ErrInsufficientBalance), true},
+		{"Should reject server errors", &APIError{StatusCode: 503, Msg: "Service Unavailable"}, false},
+		{"Should reject unknown send status", &APIError{StatusCode: 400, Code: -1007, Msg: "Timeout waiting for response from backend server."}, false},
+		{"Should reject responses without a Binance error code", &APIError{StatusCode: 413, Msg: "<html>Request Entity Too Large</html>"}, false},
+		{"Should reject request timeouts", &APIError{StatusCode: 408, Msg: "Request Timeout"}, false},
+		{"Should reject request timeouts with a Binance error code", &APIError{StatusCode: 408, Code: -2010, Msg: "Order rejected."}, false},
+		{"Should reject network errors", &url.Error{Op: "Post", URL: "https://api.binance.com/api/v3/order", Err: context.DeadlineExceeded}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsDefiniteRejection(tt.err); got != tt.want {
+				t.Errorf("IsDefiniteRejection(%v) = %v, esperado %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,160 @@
+package exchange
+
+import (
+	"context"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Lados e tipos de ordem aceitos pelas corretoras.
+const (
+	// SideBuy representa uma ordem de compra.
+	SideBuy = "BUY"
+	// SideSell representa uma ordem de venda.
+	SideSell = "SELL"
+	// OrderTypeMarket representa uma ordem executada a mercado.
+	OrderTypeMarket = "MARKET"
+)
+
+// Exchange define as operações que o bot precisa de uma corretora.
+// O loop de trading depende apenas desta interface, permitindo substituir a
+// Binance por outra corretora ou por uma implementação falsa em testes.
+type Exchange interface {
+	// GetKlines retorna os candles mais recentes do par informado.
+	GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]Candlestick, error)
+	// GetKlinesRange retorna até limit candles do par abertos entre start e end (inclusive),
+	// do mais antigo para o mais recente.
+	GetKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time, limit int) ([]Candlestick, error)
+	// PlaceOrder envia uma nova ordem e retorna o estado informado pela corretora.
+	PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error)
+	// CancelOrder cancela uma ordem ainda aberta.
+	CancelOrder(ctx context.Context, symbol string, orderID int64) (*Order, error)
+	// GetOrder consulta o estado atual de uma ordem.
+	GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error)
+	// GetOrderByClientID consulta o estado atual da ordem enviada com o identificador
+	// do cliente. Retorna um erro com ErrUnknownOrder se a ordem não existir.
+	GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*Order, error)
+	// GetBalances retorna os saldos da conta.
+	GetBalances(ctx context.Context) ([]Balance, error)
+	// GetExchangeInfo retorna as regras de negociação do par informado.
+	GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error)
+	// GetMyTrades retorna as execuções mais recentes da conta no par, da mais antiga para a mais recente.
+	GetMyTrades(ctx context.Context, symbol string, limit int) ([]Trade, error)
+}
+
+// Candlestick representa um candle (kline) retornado pela corretora.
+// Os valores são float64 pois alimentam apenas os indicadores; preços e
+// quantidades de ordens, execuções e saldos usam decimal.Decimal.
+type Candlestick struct {
+	OpenTime                 int64   // Horário de abertura do candle em milissegundos
+	Open                     float64 // Preço de abertura do candle
+	High                     float64 // Preço mais alto durante o período do candle
+	Low                      float64 // Preço mais baixo durante o período do candle
+	Close                    float64 // Preço de fechamento (ou último preço) do candle
+	Volume                   float64 // Volume total negociado durante o período do candle
+	CloseTime                int64   // Horário de fechamento do candle em milissegundos
+	QuoteAssetVolume         float64 // Volume total do ativo de cotação durante o período
+	NumberOfTrades           int64   // Número de negociações durante o período
+	TakerBuyBaseAssetVolume  float64 // Volume de compra do ativo base pelos takers
+	TakerBuyQuoteAssetVolume float64 // Volume de compra do ativo de cotação pelos takers
+	Ignore                   float64 // Campo não utilizado, ignorar
+}
+
+// OrderRequest contém os dados necessários para enviar uma ordem.
+type OrderRequest struct {
+	Symbol   string          // Par de moedas (ex: BTCUSDT)
+	Side     string          // Direção da ordem (SideBuy ou SideSell)
+	Type     string          // Tipo da ordem (ex: OrderTypeMarket)
+	Quantity decimal.Decimal // Quantidade do ativo base
+	// Price é o preço de referência usado na validação dos filtros de preço e
+	// valor mínimo. Em ordens a mercado ele não é enviado à corretora.
+	Price decimal.Decimal
+	// ClientOrderID é o identificador atribuído pelo cliente (newClientOrderId),
+	// que permite consultar a ordem mesmo sem a resposta do envio. Se vazio, a
+	// corretora gera um identificador.
+	ClientOrderID string
+}
+
+// Order representa o estado de uma ordem na corretora.
+type Order struct {
+	Symbol              string          // Par de moedas
+	OrderID             int64           // Identificador da ordem na corretora
+	ClientOrderID       string          // Identificador atribuído pelo cliente
+	Side                string          // Direção da ordem
+	Type                string          // Tipo da ordem
+	Status              string          // Estado da ordem (ex: NEW, FILLED, CANCELED)
+	OrigQty             decimal.Decimal // Quantidade solicitada
+	ExecutedQty         decimal.Decimal // Quantidade executada
+	CummulativeQuoteQty decimal.Decimal // Valor total executado no ativo de cotação
+	TransactTime        int64           // Horário da transação em milissegundos
+	Fills               []Fill          // Execuções da ordem, quando informadas pela corretora
+}
+
+// Fill representa uma execução de uma ordem, como retornada na criação da ordem.
+type Fill struct {
+	TradeID         int64           // Identificador da execução na corretora
+	Price           decimal.Decimal // Preço da execução
+	Qty             decimal.Decimal // Quantidade executada do ativo base
+	Commission      decimal.Decimal // Taxa cobrada
+	CommissionAsset string          // Ativo em que a taxa foi cobrada
+}
+
+// AvgPrice retorna o preço médio de execução da ordem, ou zero se nada foi executado.
+// A divisão usa decimal.DivisionPrecision casas decimais.
+func (o *Order) AvgPrice() decimal.Decimal {
+	if !o.ExecutedQty.IsPositive() {
+		return decimal.Zero
+	}
+	return o.CummulativeQuoteQty.Div(o.ExecutedQty)
+}
+
+// Commission retorna a taxa total da ordem no ativo da primeira execução.
+// Execuções com taxa em outro ativo (ex: desconto em BNB) não são somadas e
+// devem ser consultadas em Fills.
+func (o *Order) Commission() (decimal.Decimal, string) {
+	if len(o.Fills) == 0 {
+		return decimal.Zero, ""
+	}
+	asset := o.Fills[0].CommissionAsset
+	total := decimal.Zero
+	for _, f := range o.Fills {
+		if f.CommissionAsset == asset {
+			total = total.Add(f.Commission)
+		}
+	}
+	return total, asset
+}
+
+// Trade representa uma execução (total ou parcial) de uma ordem da conta.
+type Trade struct {
+	Symbol          string          // Par de moedas
+	ID              int64           // Identificador da execução na corretora
+	OrderID         int64           // Identificador da ordem executada
+	Price           decimal.Decimal // Preço da execução
+	Qty             decimal.Decimal // Quantidade executada do ativo base
+	QuoteQty        decimal.Decimal // Valor executado no ativo de cotação
+	Commission      decimal.Decimal // Taxa cobrada
+	CommissionAsset string          // Ativo em que a taxa foi cobrada
+	Time            int64           // Horário da execução em milissegundos
+	IsBuyer         bool            // Indica se a execução foi uma compra
+}
+
+// Balance representa o saldo de um ativo na conta.
+type Balance struct {
+	Asset  string          // Ativo (ex: BTC, USDT)
+	Free   decimal.Decimal // Saldo disponível
+	Locked decimal.Decimal // Saldo bloqueado em ordens abertas
+}
+
+// SymbolInfo contém as regras de negociação de um par.
+type SymbolInfo struct {
+	Symbol     string // Par de moedas
+	Status     string // Estado do par (ex: TRADING)
+	BaseAsset  string // Ativo base (ex: BTC)
+	QuoteAsset string // Ativo de cotação (ex: USDT)
+
+	LotSize     LotSizeFilter  // Regras de quantidade (filtro LOT_SIZE)
+	PriceFilter PriceFilter    // Regras de preço (filtro PRICE_FILTER)
+	Notional    NotionalFilter // Limites de valor da ordem (filtros MIN_NOTIONAL/NOTIONAL)
+}
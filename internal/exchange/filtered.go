@@ -0,0 +1,95 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// FilteredExchange decora uma Exchange aplicando os filtros de negociação do par
+// antes de cada ordem. As regras de /api/v3/exchangeInfo são mantidas em cache
+// por símbolo durante ttl, evitando uma consulta a cada ordem.
+type FilteredExchange struct {
+	Exchange
+
+	ttl   time.Duration
+	mu    sync.Mutex
+	cache map[string]cachedSymbol
+}
+
+// cachedSymbol é uma entrada do cache de regras de negociação.
+type cachedSymbol struct {
+	info      *SymbolInfo
+	expiresAt time.Time
+}
+
+// NewFilteredExchange cria o decorador sobre ex
+// Parâmetros:
+// - ex: corretora que recebe as ordens já validadas
+// - ttl: tempo de validade das regras em cache (0 mantém as regras indefinidamente)
+func NewFilteredExchange(ex Exchange, ttl time.Duration) *FilteredExchange {
+	return &FilteredExchange{
+		Exchange: ex,
+		ttl:      ttl,
+		cache:    make(map[string]cachedSymbol),
+	}
+}
+
+// GetExchangeInfo retorna as regras do par a partir do cache, consultando a
+// corretora apenas quando a entrada não existe ou expirou.
+func (f *FilteredExchange) GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	f.mu.Lock()
+	entry, ok := f.cache[symbol]
+	f.mu.Unlock()
+	if ok && (f.ttl == 0 || time.Now().Before(entry.expiresAt)) {
+		return entry.info, nil
+	}
+
+	info, err := f.Exchange.GetExchangeInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	f.cache[symbol] = cachedSymbol{info: info, expiresAt: time.Now().Add(f.ttl)}
+	f.mu.Unlock()
+	return info, nil
+}
+
+// PlaceOrder arredonda a quantidade para o stepSize, arredonda o preço de
+// referência para o tickSize e valida LOT_SIZE, PRICE_FILTER e MIN_NOTIONAL
+// antes de repassar a ordem.
+//
+// Retorna um *FilterError sem enviar a ordem se algum filtro for violado. Se a
+// corretora rejeitar a ordem pelos filtros (ErrFilterFailure), as regras do par
+// em cache são descartadas, para que a próxima ordem use as regras atualizadas.
+func (f *FilteredExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	info, err := f.GetExchangeInfo(ctx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Quantity, err = info.NormalizeQuantity(req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Price.IsPositive() {
+		req.Price, err = info.NormalizePrice(req.Price)
+		if err != nil {
+			return nil, err
+		}
+		if err := info.ValidateNotional(req.Quantity, req.Price, req.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	order, err := f.Exchange.PlaceOrder(ctx, req)
+	if errors.Is(err, ErrFilterFailure) {
+		f.mu.Lock()
+		delete(f.cache, req.Symbol)
+		f.mu.Unlock()
+	}
+	return order, err
+}
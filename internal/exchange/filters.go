@@ -0,0 +1,126 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Erros de validação dos filtros de um par. Use errors.Is para identificar
+// qual filtro foi violado a partir de um *FilterError.
+var (
+	// ErrLotSize indica quantidade fora dos limites do filtro LOT_SIZE.
+	ErrLotSize = errors.New("LOT_SIZE")
+	// ErrPriceFilter indica preço fora dos limites do filtro PRICE_FILTER.
+	ErrPriceFilter = errors.New("PRICE_FILTER")
+	// ErrMinNotional indica valor da ordem abaixo do mínimo (MIN_NOTIONAL/NOTIONAL).
+	ErrMinNotional = errors.New("MIN_NOTIONAL")
+	// ErrMaxNotional indica valor da ordem acima do máximo do filtro NOTIONAL.
+	ErrMaxNotional = errors.New("MAX_NOTIONAL")
+)
+
+// FilterError descreve uma violação dos filtros de negociação de um par.
+type FilterError struct {
+	Symbol string          // Par de moedas
+	Filter error           // Filtro violado (ErrLotSize, ErrPriceFilter, ErrMinNotional ou ErrMaxNotional)
+	Field  string          // Campo avaliado (quantity, price ou notional)
+	Value  decimal.Decimal // Valor enviado, após o arredondamento
+	Limit  decimal.Decimal // Limite violado
+}
+
+func (e *FilterError) Error() string {
+	return fmt.Sprintf("%s: %s %s %s fora do limite %s",
+		e.Symbol, e.Filter, e.Field, e.Value, e.Limit)
+}
+
+// Unwrap permite comparar o erro com os sentinelas através de errors.Is,
+// tanto com o filtro violado quanto com ErrFilterFailure.
+func (e *FilterError) Unwrap() []error {
+	return []error{e.Filter, ErrFilterFailure}
+}
+
+// LotSizeFilter contém as regras de quantidade do filtro LOT_SIZE de um par.
+type LotSizeFilter struct {
+	MinQty   decimal.Decimal // Quantidade mínima por ordem
+	MaxQty   decimal.Decimal // Quantidade máxima por ordem
+	StepSize decimal.Decimal // Incremento permitido para a quantidade
+}
+
+// Floor arredonda a quantidade para baixo até o múltiplo de StepSize mais próximo.
+// Se StepSize não estiver definido, a quantidade é retornada sem alterações.
+// A quantidade deve ser positiva.
+func (f LotSizeFilter) Floor(qty decimal.Decimal) decimal.Decimal {
+	if !f.StepSize.IsPositive() {
+		return qty
+	}
+	// QuoRem calcula o quociente inteiro exato, sem o arredondamento de Div
+	steps, _ := qty.QuoRem(f.StepSize, 0)
+	return steps.Mul(f.StepSize)
+}
+
+// PriceFilter contém as regras de preço do filtro PRICE_FILTER de um par.
+type PriceFilter struct {
+	MinPrice decimal.Decimal // Preço mínimo (0 desativa)
+	MaxPrice decimal.Decimal // Preço máximo (0 desativa)
+	TickSize decimal.Decimal // Incremento permitido para o preço (0 desativa)
+}
+
+// NotionalFilter contém os limites de valor (preço x quantidade) de uma ordem,
+// vindos do filtro MIN_NOTIONAL ou NOTIONAL.
+type NotionalFilter struct {
+	MinNotional decimal.Decimal // Valor mínimo da ordem
+	MaxNotional decimal.Decimal // Valor máximo da ordem (0 desativa)
+	// ApplyToMarket indica se o limite mínimo também vale para ordens a mercado
+	ApplyToMarket bool
+	// ApplyMaxToMarket indica se o limite máximo também vale para ordens a mercado
+	ApplyMaxToMarket bool
+}
+
+// NormalizeQuantity arredonda a quantidade para baixo até o stepSize do par e
+// valida os limites do filtro LOT_SIZE.
+//
+// Retorna um *FilterError com ErrLotSize se a quantidade arredondada estiver fora dos limites.
+func (s *SymbolInfo) NormalizeQuantity(qty decimal.Decimal) (decimal.Decimal, error) {
+	qty = s.LotSize.Floor(qty)
+	if !qty.IsPositive() || qty.LessThan(s.LotSize.MinQty) {
+		return qty, &FilterError{Symbol: s.Symbol, Filter: ErrLotSize, Field: "quantity", Value: qty, Limit: s.LotSize.MinQty}
+	}
+	if s.LotSize.MaxQty.IsPositive() && qty.GreaterThan(s.LotSize.MaxQty) {
+		return qty, &FilterError{Symbol: s.Symbol, Filter: ErrLotSize, Field: "quantity", Value: qty, Limit: s.LotSize.MaxQty}
+	}
+	return qty, nil
+}
+
+// NormalizePrice arredonda o preço para o tickSize mais próximo e valida os
+// limites do filtro PRICE_FILTER.
+//
+// Retorna um *FilterError com ErrPriceFilter se o preço arredondado estiver fora dos limites.
+func (s *SymbolInfo) NormalizePrice(price decimal.Decimal) (decimal.Decimal, error) {
+	if tick := s.PriceFilter.TickSize; tick.IsPositive() {
+		price = price.Div(tick).Round(0).Mul(tick)
+	}
+	if !price.IsPositive() || (s.PriceFilter.MinPrice.IsPositive() && price.LessThan(s.PriceFilter.MinPrice)) {
+		return price, &FilterError{Symbol: s.Symbol, Filter: ErrPriceFilter, Field: "price", Value: price, Limit: s.PriceFilter.MinPrice}
+	}
+	if s.PriceFilter.MaxPrice.IsPositive() && price.GreaterThan(s.PriceFilter.MaxPrice) {
+		return price, &FilterError{Symbol: s.Symbol, Filter: ErrPriceFilter, Field: "price", Value: price, Limit: s.PriceFilter.MaxPrice}
+	}
+	return price, nil
+}
+
+// ValidateNotional valida o valor da ordem (quantidade x preço) contra os
+// filtros MIN_NOTIONAL/NOTIONAL. Para ordens a mercado o mínimo só é aplicado
+// se ApplyToMarket estiver ativo, e o máximo se ApplyMaxToMarket estiver ativo.
+func (s *SymbolInfo) ValidateNotional(qty, price decimal.Decimal, orderType string) error {
+	notional := qty.Mul(price)
+	checkMin := orderType != OrderTypeMarket || s.Notional.ApplyToMarket
+	if checkMin && s.Notional.MinNotional.IsPositive() && notional.LessThan(s.Notional.MinNotional) {
+		return &FilterError{Symbol: s.Symbol, Filter: ErrMinNotional, Field: "notional", Value: notional, Limit: s.Notional.MinNotional}
+	}
+	checkMax := orderType != OrderTypeMarket || s.Notional.ApplyMaxToMarket
+	if checkMax && s.Notional.MaxNotional.IsPositive() && notional.GreaterThan(s.Notional.MaxNotional) {
+		return &FilterError{Symbol: s.Symbol, Filter: ErrMaxNotional, Field: "notional", Value: notional, Limit: s.Notional.MaxNotional}
+	}
+	return nil
+}
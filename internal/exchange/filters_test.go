@@ -0,0 +1,209 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// dec converte um literal de teste em decimal.Decimal.
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestLotSizeFilterFloor(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   LotSizeFilter
+		quantity decimal.Decimal
+		expected decimal.Decimal
+	}{
+		{
+			name:     "Should round down to step size",
+			filter:   LotSizeFilter{StepSize: dec("0.001")},
+			quantity: dec("0.0019999"),
+			expected: dec("0.001"),
+		},
+		{
+			name:     "Should keep exact multiples",
+			filter:   LotSizeFilter{StepSize: dec("0.1")},
+			quantity: dec("0.3"),
+			expected: dec("0.3"),
+		},
+		{
+			name:     "Should round to whole units",
+			filter:   LotSizeFilter{StepSize: dec("1")},
+			quantity: dec("12.7"),
+			expected: dec("12"),
+		},
+		{
+			name:     "Should keep quantity without step size",
+			filter:   LotSizeFilter{},
+			quantity: dec("0.123456"),
+			expected: dec("0.123456"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Floor(tt.quantity); !got.Equal(tt.expected) {
+				t.Errorf("Floor() = %v, esperado %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func testSymbolInfo() *SymbolInfo {
+	return &SymbolInfo{
+		Symbol:      "BTCUSDT",
+		LotSize:     LotSizeFilter{MinQty: dec("0.0001"), MaxQty: dec("100"), StepSize: dec("0.0001")},
+		PriceFilter: PriceFilter{MinPrice: dec("0.01"), MaxPrice: dec("1000000"), TickSize: dec("0.01")},
+		Notional:    NotionalFilter{MinNotional: dec("5"), ApplyToMarket: true},
+	}
+}
+
+func TestSymbolInfoNormalizeQuantity(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity decimal.Decimal
+		expected decimal.Decimal
+		wantErr  error
+	}{
+		{name: "Should round down to step size", quantity: dec("0.00123456"), expected: dec("0.0012")},
+		{name: "Should reject quantity below min", quantity: dec("0.00009"), wantErr: ErrLotSize},
+		{name: "Should reject quantity above max", quantity: dec("150"), wantErr: ErrLotSize},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testSymbolInfo().NormalizeQuantity(tt.quantity)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("esperado erro %v, obteve %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("NormalizeQuantity() = %v, esperado %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSymbolInfoNormalizePrice(t *testing.T) {
+	info := testSymbolInfo()
+	got, err := info.NormalizePrice(dec("25000.12789"))
+	if err != nil || !got.Equal(dec("25000.13")) {
+		t.Errorf("NormalizePrice() = %v, %v; esperado 25000.13", got, err)
+	}
+	if _, err := info.NormalizePrice(dec("2000000")); !errors.Is(err, ErrPriceFilter) {
+		t.Errorf("esperado ErrPriceFilter, obteve %v", err)
+	}
+}
+
+func TestSymbolInfoValidateNotional(t *testing.T) {
+	info := testSymbolInfo()
+	if err := info.ValidateNotional(dec("0.0001"), dec("25000"), OrderTypeMarket); !errors.Is(err, ErrMinNotional) {
+		t.Errorf("esperado ErrMinNotional, obteve %v", err)
+	}
+	if err := info.ValidateNotional(dec("0.001"), dec("25000"), OrderTypeMarket); err != nil {
+		t.Errorf("erro inesperado: %v", err)
+	}
+
+	info.Notional.ApplyToMarket = false
+	if err := info.ValidateNotional(dec("0.0001"), dec("25000"), OrderTypeMarket); err != nil {
+		t.Errorf("mínimo não deveria ser aplicado a ordens a mercado: %v", err)
+	}
+}
+
+func TestSymbolInfoValidateMaxNotional(t *testing.T) {
+	tests := []struct {
+		name             string
+		orderType        string
+		applyMaxToMarket bool
+		wantErr          bool
+	}{
+		{"Should ignore the maximum on market orders by default", OrderTypeMarket, false, false},
+		{"Should apply the maximum on market orders when applyMaxToMarket is set", OrderTypeMarket, true, true},
+		{"Should always apply the maximum on limit orders", "LIMIT", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := testSymbolInfo()
+			info.Notional.MaxNotional = dec("1000")
+			info.Notional.ApplyMaxToMarket = tt.applyMaxToMarket
+
+			err := info.ValidateNotional(dec("0.1"), dec("25000"), tt.orderType)
+			if tt.wantErr != errors.Is(err, ErrMaxNotional) || (!tt.wantErr && err != nil) {
+				t.Errorf("ValidateNotional() erro = %v, esperado ErrMaxNotional: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// recordingExchange registra as ordens recebidas e conta as consultas de exchangeInfo.
+type recordingExchange struct {
+	Exchange
+	infoCalls int
+	orders    []OrderRequest
+	rejectErr error // Erro retornado no envio das ordens
+}
+
+func (r *recordingExchange) GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	r.infoCalls++
+	return testSymbolInfo(), nil
+}
+
+func (r *recordingExchange) PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	r.orders = append(r.orders, req)
+	if r.rejectErr != nil {
+		return nil, r.rejectErr
+	}
+	return &Order{Symbol: req.Symbol, ExecutedQty: req.Quantity}, nil
+}
+
+func TestFilteredExchange(t *testing.T) {
+	inner := &recordingExchange{}
+	f := NewFilteredExchange(inner, time.Hour)
+
+	_, err := f.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.00123456"), Price: dec("25000")})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if len(inner.orders) != 1 || !inner.orders[0].Quantity.Equal(dec("0.0012")) {
+		t.Fatalf("ordem não arredondada antes do envio: %+v", inner.orders)
+	}
+
+	_, err = f.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.0001"), Price: dec("25000")})
+	var filterErr *FilterError
+	if !errors.As(err, &filterErr) || !errors.Is(err, ErrMinNotional) || !errors.Is(err, ErrFilterFailure) {
+		t.Fatalf("esperado *FilterError com ErrMinNotional, obteve %v", err)
+	}
+	if len(inner.orders) != 1 {
+		t.Errorf("ordem inválida não deveria ser enviada")
+	}
+	if inner.infoCalls != 1 {
+		t.Errorf("exchangeInfo consultado %d vezes, esperado 1 (cache)", inner.infoCalls)
+	}
+
+	// A rejeição pelos filtros na corretora descarta as regras em cache
+	inner.rejectErr = &APIError{StatusCode: 400, Code: -1013, Msg: "Filter failure: LOT_SIZE"}
+	_, err = f.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.0012"), Price: dec("25000")})
+	if !errors.Is(err, ErrFilterFailure) {
+		t.Fatalf("esperado ErrFilterFailure, obteve %v", err)
+	}
+	inner.rejectErr = nil
+	if _, err := f.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("0.0012"), Price: dec("25000")}); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if inner.infoCalls != 2 {
+		t.Errorf("exchangeInfo consultado %d vezes, esperado 2 após a rejeição", inner.infoCalls)
+	}
+}
@@ -0,0 +1,63 @@
+package exchange
+
+import (
+	"fmt"
+	"time"
+)
+
+// intervals relaciona os intervalos de kline aceitos pela Binance à sua duração.
+// O intervalo mensal (1M) não possui duração fixa e é tratado à parte.
+var intervals = map[string]time.Duration{
+	"1s":  time.Second,
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+	"1M":  0,
+}
+
+// weekOffset é a distância entre a época Unix (quinta-feira) e a primeira
+// segunda-feira, dia em que começam os candles semanais da Binance.
+const weekOffset = 4 * 24 * time.Hour
+
+// ValidInterval indica se o intervalo é aceito pelo endpoint de klines da Binance.
+func ValidInterval(interval string) bool {
+	_, ok := intervals[interval]
+	return ok
+}
+
+// NextCandleClose retorna o horário de fechamento do candle em formação no instante now,
+// ou seja, o início do próximo candle. Os candles são alinhados em UTC como na Binance:
+// intervalos fixos a partir da época Unix, semanais às segundas-feiras e mensais no
+// primeiro dia do mês.
+//
+// Retorna erro se o intervalo não for aceito pela Binance.
+func NextCandleClose(interval string, now time.Time) (time.Time, error) {
+	d, ok := intervals[interval]
+	if !ok {
+		return time.Time{}, fmt.Errorf("intervalo inválido: %s", interval)
+	}
+
+	now = now.UTC()
+	if interval == "1M" {
+		return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC), nil
+	}
+
+	var offset time.Duration
+	if interval == "1w" {
+		offset = weekOffset
+	}
+	elapsed := time.Duration(now.UnixNano()) - offset
+	next := (elapsed/d + 1) * d
+	return time.Unix(0, int64(next+offset)).UTC(), nil
+}
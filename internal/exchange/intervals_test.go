@@ -0,0 +1,62 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextCandleClose(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval string
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			name:     "Should align 15m candles",
+			interval: "15m",
+			now:      time.Date(2024, 3, 10, 12, 7, 30, 0, time.UTC),
+			expected: time.Date(2024, 3, 10, 12, 15, 0, 0, time.UTC),
+		},
+		{
+			name:     "Should move to next candle exactly at close",
+			interval: "1h",
+			now:      time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC),
+			expected: time.Date(2024, 3, 10, 13, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Should align daily candles to UTC midnight",
+			interval: "1d",
+			now:      time.Date(2024, 3, 10, 23, 59, 0, 0, time.FixedZone("BRT", -3*3600)),
+			expected: time.Date(2024, 3, 12, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Should align weekly candles to Monday",
+			interval: "1w",
+			now:      time.Date(2024, 3, 13, 8, 0, 0, 0, time.UTC), // quarta-feira
+			expected: time.Date(2024, 3, 18, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Should align monthly candles to the first day",
+			interval: "1M",
+			now:      time.Date(2024, 12, 20, 8, 0, 0, 0, time.UTC),
+			expected: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NextCandleClose(tt.interval, tt.now)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !got.Equal(tt.expected) {
+				t.Errorf("NextCandleClose() = %v, esperado %v", got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := NextCandleClose("7m", time.Now()); err == nil {
+		t.Error("esperado erro para intervalo inválido")
+	}
+}
@@ -0,0 +1,267 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// PaperConfig contém os parâmetros da simulação do modo paper.
+type PaperConfig struct {
+	FeePercent      float64         // Taxa cobrada no ativo de cotação, em porcentagem do valor executado
+	SlippagePercent float64         // Deslizamento aplicado contra o preço, em porcentagem
+	QuoteBalance    decimal.Decimal // Saldo inicial do ativo de cotação
+	BaseBalance     decimal.Decimal // Saldo inicial do ativo base de cada par negociado
+}
+
+// Paper implementa Exchange simulando as ordens localmente.
+// Dados de mercado e regras dos pares são obtidos da corretora real (market),
+// enquanto ordens são executadas contra o fechamento do último candle, sem
+// nenhuma requisição assinada.
+type Paper struct {
+	market Exchange
+	cfg    PaperConfig
+
+	mu       sync.Mutex
+	balances map[string]decimal.Decimal
+	symbols  map[string]*SymbolInfo
+	orders   map[int64]*Order
+	trades   []Trade
+	nextID   int64
+}
+
+// NewPaper cria uma corretora simulada que usa market como fonte de dados de mercado.
+func NewPaper(market Exchange, cfg PaperConfig) *Paper {
+	return &Paper{
+		market:   market,
+		cfg:      cfg,
+		balances: make(map[string]decimal.Decimal),
+		symbols:  make(map[string]*SymbolInfo),
+		orders:   make(map[int64]*Order),
+		nextID:   1,
+	}
+}
+
+// GetKlines repassa a consulta de candles para a corretora real.
+func (p *Paper) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]Candlestick, error) {
+	return p.market.GetKlines(ctx, symbol, interval, limit)
+}
+
+// GetKlinesRange repassa a consulta de candles históricos para a corretora real.
+func (p *Paper) GetKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time, limit int) ([]Candlestick, error) {
+	return p.market.GetKlinesRange(ctx, symbol, interval, start, end, limit)
+}
+
+// GetExchangeInfo repassa a consulta para a corretora real e inicializa
+// os saldos simulados dos ativos do par na primeira consulta.
+func (p *Paper) GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	p.mu.Lock()
+	info, ok := p.symbols[symbol]
+	p.mu.Unlock()
+	if ok {
+		return info, nil
+	}
+
+	info, err := p.market.GetExchangeInfo(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.symbols[symbol] = info
+	if _, ok := p.balances[info.QuoteAsset]; !ok {
+		p.balances[info.QuoteAsset] = p.cfg.QuoteBalance
+	}
+	if _, ok := p.balances[info.BaseAsset]; !ok {
+		p.balances[info.BaseAsset] = p.cfg.BaseBalance
+	}
+	return info, nil
+}
+
+// PlaceOrder simula uma ordem a mercado
+// O preço de execução é o fechamento do candle mais recente ajustado pelo
+// deslizamento (para cima na compra, para baixo na venda). A taxa é descontada
+// do ativo de cotação.
+//
+// Retorna ErrInsufficientBalance se o saldo simulado não cobrir a ordem.
+func (p *Paper) PlaceOrder(ctx context.Context, req OrderRequest) (*Order, error) {
+	if req.Type != OrderTypeMarket {
+		return nil, fmt.Errorf("modo paper suporta apenas ordens %s", OrderTypeMarket)
+	}
+	if !req.Quantity.IsPositive() {
+		return nil, errors.New("quantidade deve ser maior que zero")
+	}
+
+	info, err := p.GetExchangeInfo(ctx, req.Symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	candles, err := p.market.GetKlines(ctx, req.Symbol, "1m", 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(candles) == 0 {
+		return nil, fmt.Errorf("nenhum candle disponível para %s", req.Symbol)
+	}
+	price := decimal.NewFromFloat(candles[len(candles)-1].Close)
+
+	hundred := decimal.NewFromInt(100)
+	slippage := decimal.NewFromFloat(p.cfg.SlippagePercent).Div(hundred)
+	switch req.Side {
+	case SideBuy:
+		price = price.Mul(decimal.NewFromInt(1).Add(slippage))
+	case SideSell:
+		price = price.Mul(decimal.NewFromInt(1).Sub(slippage))
+	default:
+		return nil, fmt.Errorf("lado da ordem inválido: %s", req.Side)
+	}
+
+	quoteQty := price.Mul(req.Quantity)
+	fee := quoteQty.Mul(decimal.NewFromFloat(p.cfg.FeePercent)).Div(hundred)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if req.Side == SideBuy {
+		cost := quoteQty.Add(fee)
+		if p.balances[info.QuoteAsset].LessThan(cost) {
+			return nil, fmt.Errorf("%w: %s disponível %s, necessário %s",
+				ErrInsufficientBalance, info.QuoteAsset, p.balances[info.QuoteAsset], cost)
+		}
+		p.balances[info.QuoteAsset] = p.balances[info.QuoteAsset].Sub(cost)
+		p.balances[info.BaseAsset] = p.balances[info.BaseAsset].Add(req.Quantity)
+	} else {
+		if p.balances[info.BaseAsset].LessThan(req.Quantity) {
+			return nil, fmt.Errorf("%w: %s disponível %s, necessário %s",
+				ErrInsufficientBalance, info.BaseAsset, p.balances[info.BaseAsset], req.Quantity)
+		}
+		p.balances[info.BaseAsset] = p.balances[info.BaseAsset].Sub(req.Quantity)
+		p.balances[info.QuoteAsset] = p.balances[info.QuoteAsset].Add(quoteQty.Sub(fee))
+	}
+
+	clientOrderID := req.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = fmt.Sprintf("paper-%d", p.nextID)
+	}
+	order := &Order{
+		Symbol:              req.Symbol,
+		OrderID:             p.nextID,
+		ClientOrderID:       clientOrderID,
+		Side:                req.Side,
+		Type:                req.Type,
+		Status:              "FILLED",
+		OrigQty:             req.Quantity,
+		ExecutedQty:         req.Quantity,
+		CummulativeQuoteQty: quoteQty,
+		TransactTime:        time.Now().UnixMilli(),
+		Fills: []Fill{{
+			TradeID:         p.nextID,
+			Price:           price,
+			Qty:             req.Quantity,
+			Commission:      fee,
+			CommissionAsset: info.QuoteAsset,
+		}},
+	}
+	p.orders[order.OrderID] = order
+	p.trades = append(p.trades, Trade{
+		Symbol:          req.Symbol,
+		ID:              order.OrderID,
+		OrderID:         order.OrderID,
+		Price:           price,
+		Qty:             req.Quantity,
+		QuoteQty:        quoteQty,
+		Commission:      fee,
+		CommissionAsset: info.QuoteAsset,
+		Time:            order.TransactTime,
+		IsBuyer:         req.Side == SideBuy,
+	})
+	p.nextID++
+
+	copied := *order
+	copied.Fills = slices.Clone(order.Fills)
+	return &copied, nil
+}
+
+// CancelOrder sempre falha, pois as ordens simuladas são executadas imediatamente.
+func (p *Paper) CancelOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	order, err := p.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("ordem %d já executada com status %s", order.OrderID, order.Status)
+}
+
+// GetOrder consulta uma ordem simulada.
+func (p *Paper) GetOrder(ctx context.Context, symbol string, orderID int64) (*Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	order, ok := p.orders[orderID]
+	if !ok || order.Symbol != symbol {
+		return nil, fmt.Errorf("%w: %d em %s", ErrUnknownOrder, orderID, symbol)
+	}
+	copied := *order
+	return &copied, nil
+}
+
+// GetOrderByClientID consulta a ordem simulada mais recente enviada com o identificador do cliente.
+func (p *Paper) GetOrderByClientID(ctx context.Context, symbol string, clientOrderID string) (*Order, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var found *Order
+	for _, order := range p.orders {
+		if order.Symbol == symbol && order.ClientOrderID == clientOrderID && (found == nil || order.OrderID > found.OrderID) {
+			found = order
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("%w: %s em %s", ErrUnknownOrder, clientOrderID, symbol)
+	}
+	copied := *found
+	return &copied, nil
+}
+
+// GetBalances retorna os saldos simulados não nulos, ordenados por ativo.
+func (p *Paper) GetBalances(ctx context.Context) ([]Balance, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var balances []Balance
+	for asset, free := range p.balances {
+		if free.IsZero() {
+			continue
+		}
+		balances = append(balances, Balance{Asset: asset, Free: free})
+	}
+	sort.Slice(balances, func(i, j int) bool {
+		return balances[i].Asset < balances[j].Asset
+	})
+	return balances, nil
+}
+
+// GetMyTrades retorna as execuções simuladas mais recentes do par.
+// Como as ordens simuladas são executadas integralmente, há uma execução por ordem.
+func (p *Paper) GetMyTrades(ctx context.Context, symbol string, limit int) ([]Trade, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var trades []Trade
+	for _, trade := range p.trades {
+		if trade.Symbol == symbol {
+			trades = append(trades, trade)
+		}
+	}
+	if limit > 0 && len(trades) > limit {
+		trades = trades[len(trades)-limit:]
+	}
+	return trades, nil
+}
@@ -0,0 +1,103 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// fakeMarket fornece dados de mercado fixos para os testes do modo paper.
+type fakeMarket struct {
+	Exchange
+	price float64
+}
+
+func (f *fakeMarket) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]Candlestick, error) {
+	return []Candlestick{{Close: f.price}}, nil
+}
+
+func (f *fakeMarket) GetExchangeInfo(ctx context.Context, symbol string) (*SymbolInfo, error) {
+	return &SymbolInfo{Symbol: symbol, Status: "TRADING", BaseAsset: "BTC", QuoteAsset: "USDT"}, nil
+}
+
+func balanceOf(t *testing.T, p *Paper, asset string) decimal.Decimal {
+	t.Helper()
+	balances, err := p.GetBalances(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, b := range balances {
+		if b.Asset == asset {
+			return b.Free
+		}
+	}
+	return decimal.Zero
+}
+
+func TestPaperPlaceOrder(t *testing.T) {
+	market := &fakeMarket{price: 100}
+	p := NewPaper(market, PaperConfig{FeePercent: 1, SlippagePercent: 1, QuoteBalance: dec("1000")})
+
+	buy, err := p.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("2")})
+	if err != nil {
+		t.Fatalf("erro inesperado na compra: %v", err)
+	}
+	if buy.Status != "FILLED" || !buy.ExecutedQty.Equal(dec("2")) || !buy.CummulativeQuoteQty.Equal(dec("202")) {
+		t.Errorf("compra simulada incorretamente: %+v", buy)
+	}
+	if commission, asset := buy.Commission(); !commission.Equal(dec("2.02")) || asset != "USDT" || !buy.AvgPrice().Equal(dec("101")) {
+		t.Errorf("execução simulada incorretamente: %+v", buy.Fills)
+	}
+	// 1000 - 202 (2 x 101) - 2.02 de taxa
+	if got := balanceOf(t, p, "USDT"); !got.Equal(dec("795.98")) {
+		t.Errorf("saldo USDT = %v, esperado 795.98", got)
+	}
+	if got := balanceOf(t, p, "BTC"); !got.Equal(dec("2")) {
+		t.Errorf("saldo BTC = %v, esperado 2", got)
+	}
+
+	market.price = 110
+	sell, err := p.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideSell, Type: OrderTypeMarket, Quantity: dec("2")})
+	if err != nil {
+		t.Fatalf("erro inesperado na venda: %v", err)
+	}
+	// 2 x 108.9 = 217.8, taxa de 2.178
+	if !sell.CummulativeQuoteQty.Equal(dec("217.8")) {
+		t.Errorf("venda simulada incorretamente: %+v", sell)
+	}
+	if got := balanceOf(t, p, "USDT"); !got.Equal(dec("1011.602")) {
+		t.Errorf("saldo USDT = %v após a venda", got)
+	}
+	if got := balanceOf(t, p, "BTC"); !got.IsZero() {
+		t.Errorf("saldo BTC = %v, esperado 0", got)
+	}
+
+	order, err := p.GetOrder(context.Background(), "BTCUSDT", sell.OrderID)
+	if err != nil || order.Side != SideSell {
+		t.Errorf("GetOrder() = %+v, %v", order, err)
+	}
+
+	trades, err := p.GetMyTrades(context.Background(), "BTCUSDT", 1)
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("GetMyTrades() = %+v, %v", trades, err)
+	}
+	if trades[0].OrderID != sell.OrderID || trades[0].IsBuyer || !trades[0].Commission.Equal(dec("2.178")) {
+		t.Errorf("execução simulada incorretamente: %+v", trades[0])
+	}
+}
+
+func TestPaperPlaceOrder_InsufficientBalance(t *testing.T) {
+	p := NewPaper(&fakeMarket{price: 100}, PaperConfig{QuoteBalance: dec("50")})
+
+	_, err := p.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideBuy, Type: OrderTypeMarket, Quantity: dec("1")})
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("esperado ErrInsufficientBalance na compra, obteve %v", err)
+	}
+
+	_, err = p.PlaceOrder(context.Background(), OrderRequest{Symbol: "BTCUSDT", Side: SideSell, Type: OrderTypeMarket, Quantity: dec("1")})
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Errorf("esperado ErrInsufficientBalance na venda, obteve %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultWeightLimit é o peso máximo de requisições por minuto por IP na API REST da Binance.
+	defaultWeightLimit = 6000
+	// throttlePercent é a parcela do limite de peso a partir da qual as requisições
+	// aguardam a janela seguinte, preservando uma margem para as de outros processos.
+	throttlePercent = 90
+	// maxRateLimitWait é a espera máxima por uma janela ou pelo fim de um bloqueio;
+	// bloqueios mais longos (ex: banimento temporário do IP) falham imediatamente.
+	maxRateLimitWait = time.Minute
+)
+
+// weightLimiter acompanha o peso consumido das requisições, informado pela Binance
+// no header X-MBX-USED-WEIGHT-1M, e os bloqueios solicitados em respostas 429 e 418
+// pelo header Retry-After. Pode ser usado por várias goroutines.
+type weightLimiter struct {
+	limit  int           // Peso máximo por janela
+	window time.Duration // Duração da janela do limite, alinhada ao relógio
+
+	mu           sync.Mutex
+	used         int       // Maior peso consumido informado na janela de windowStart
+	windowStart  time.Time // Início da janela do peso em used
+	blockedUntil time.Time // Fim do bloqueio solicitado pela Binance
+}
+
+// newWeightLimiter cria um limitador com o limite de peso da Binance por minuto.
+func newWeightLimiter() *weightLimiter {
+	return &weightLimiter{limit: defaultWeightLimit, window: time.Minute}
+}
+
+// wait aguarda até que uma nova requisição possa ser enviada: o fim do bloqueio
+// solicitado pela Binance ou, com o peso consumido na janela atual acima de
+// throttlePercent do limite, o início da janela seguinte.
+// Retorna erro se ctx for cancelado ou se a espera exceder maxRateLimitWait.
+func (l *weightLimiter) wait(ctx context.Context) error {
+	now := time.Now()
+	l.mu.Lock()
+	until := l.blockedUntil
+	if l.used*100 >= l.limit*throttlePercent && now.Truncate(l.window).Equal(l.windowStart) {
+		until = maxTime(until, l.windowStart.Add(l.window))
+	}
+	l.mu.Unlock()
+
+	delay := until.Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+	if delay > maxRateLimitWait {
+		return fmt.Errorf("requisições à Binance suspensas até %s pelo limite de requisições", until.Format(time.DateTime))
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// update registra o peso consumido informado na resposta e, em respostas 429
+// (limite excedido) e 418 (IP bloqueado), o bloqueio pelo tempo de Retry-After.
+// Sem Retry-After, o bloqueio dura até o início da janela seguinte.
+func (l *weightLimiter) update(resp *http.Response) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if used, err := strconv.Atoi(resp.Header.Get("X-MBX-USED-WEIGHT-1M")); err == nil {
+		// As respostas de requisições simultâneas podem chegar fora de ordem;
+		// dentro da mesma janela prevalece o maior peso informado
+		start := now.Truncate(l.window)
+		if start.After(l.windowStart) {
+			l.windowStart, l.used = start, used
+		} else {
+			l.used = max(l.used, used)
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		until := now.Truncate(l.window).Add(l.window)
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			until = now.Add(time.Duration(seconds) * time.Second)
+		}
+		l.blockedUntil = maxTime(l.blockedUntil, until)
+	}
+}
+
+// maxTime retorna o mais recente entre a e b.
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestWeightLimiter_Throttle(t *testing.T) {
+	const window = 200 * time.Millisecond
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A primeira requisição consome quase todo o limite da janela
+		used := "1"
+		if requests.Add(1) == 1 {
+			used = "95"
+		}
+		w.Header().Set("X-MBX-USED-WEIGHT-1M", used)
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	b := NewBinance(server.URL, "", "")
+	b.limiter.limit = 100
+	b.limiter.window = window
+
+	// Alinha o início ao começo de uma janela, para que as duas requisições não a atravessem
+	time.Sleep(time.Until(time.Now().Truncate(window).Add(window)))
+	ctx := context.Background()
+	if _, err := b.GetKlines(ctx, "BTCUSDT", "1m", 1); err != nil {
+		t.Fatal(err)
+	}
+	first := time.Now().Truncate(window)
+	if _, err := b.GetKlines(ctx, "BTCUSDT", "1m", 1); err != nil {
+		t.Fatal(err)
+	}
+	if time.Now().Truncate(window).Equal(first) {
+		t.Error("requisição enviada na mesma janela após o peso atingir o limite")
+	}
+
+	// Abaixo do limite, a requisição é enviada sem espera
+	started := time.Now()
+	if _, err := b.GetKlines(ctx, "BTCUSDT", "1m", 1); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(started); elapsed > window/2 {
+		t.Errorf("requisição abaixo do limite aguardou %s", elapsed)
+	}
+}
+
+func TestWeightLimiter_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		retryAfter string
+		wantReqs   int32
+		wantErr    string
+	}{
+		{"Should wait Retry-After and retry when rate limited", http.StatusTooManyRequests, "1", 2, ""},
+		{"Should fail fast while the IP is banned", http.StatusTeapot, "120", 1, "suspensas"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(tt.status)
+					w.Write([]byte(`{"code":-1003,"msg":"Too many requests"}`))
+					return
+				}
+				w.Write([]byte(`[]`))
+			}))
+			defer server.Close()
+
+			b := NewBinance(server.URL, "", "")
+			b.MinBackoff = time.Millisecond
+			started := time.Now()
+			_, err := b.GetKlines(context.Background(), "BTCUSDT", "1m", 1)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("erro inesperado: %v", err)
+				}
+				if elapsed := time.Since(started); elapsed < 900*time.Millisecond {
+					t.Errorf("nova tentativa após %s, esperado Retry-After de 1s", elapsed)
+				}
+			} else {
+				// O bloqueio também impede as requisições seguintes, sem contatar a corretora
+				_, err = b.GetKlines(context.Background(), "BTCUSDT", "1m", 1)
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("erro = %v, esperado contendo %q", err, tt.wantErr)
+				}
+			}
+			if got := requests.Load(); got != tt.wantReqs {
+				t.Errorf("%d requisições enviadas, esperado %d", got, tt.wantReqs)
+			}
+		})
+	}
+}
@@ -0,0 +1,386 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/shopspring/decimal"
+)
+
+// Tipos de execução informados em ExecutionReport.ExecutionType.
+const (
+	// ExecutionNew indica que a ordem foi aceita pela corretora.
+	ExecutionNew = "NEW"
+	// ExecutionTrade indica uma execução (total ou parcial) da ordem.
+	ExecutionTrade = "TRADE"
+	// ExecutionCanceled indica que a ordem foi cancelada.
+	ExecutionCanceled = "CANCELED"
+	// ExecutionExpired indica que a ordem expirou sem ser totalmente executada.
+	ExecutionExpired = "EXPIRED"
+	// ExecutionRejected indica que a ordem foi rejeitada.
+	ExecutionRejected = "REJECTED"
+)
+
+// ExecutionReport é uma atualização de ordem da conta enviada pelo user data stream
+// (evento executionReport): criação, cada execução, cancelamento ou expiração,
+// inclusive de ordens enviadas fora do bot.
+type ExecutionReport struct {
+	Symbol              string          // Par de moedas
+	OrderID             int64           // Identificador da ordem na corretora
+	ClientOrderID       string          // Identificador atribuído pelo cliente (o original, em cancelamentos)
+	Side                string          // Direção da ordem
+	Type                string          // Tipo da ordem
+	ExecutionType       string          // Motivo do evento (ExecutionNew, ExecutionTrade, ...)
+	Status              string          // Estado da ordem após o evento (ex: PARTIALLY_FILLED, FILLED)
+	OrigQty             decimal.Decimal // Quantidade solicitada
+	ExecutedQty         decimal.Decimal // Quantidade executada acumulada
+	CummulativeQuoteQty decimal.Decimal // Valor executado acumulado no ativo de cotação
+	LastQty             decimal.Decimal // Quantidade da última execução
+	LastPrice           decimal.Decimal // Preço da última execução
+	Commission          decimal.Decimal // Taxa da última execução
+	CommissionAsset     string          // Ativo da taxa da última execução (vazio sem execução)
+	TradeID             int64           // Identificador da última execução (-1 sem execução)
+	TransactTime        int64           // Horário do evento na corretora em milissegundos
+}
+
+// AccountUpdate são os saldos da conta alterados por uma ordem ou transferência,
+// enviados pelo user data stream (evento outboundAccountPosition).
+type AccountUpdate struct {
+	EventTime int64     // Horário do evento em milissegundos
+	Balances  []Balance // Saldos dos ativos alterados
+}
+
+// UserEvent é um evento do user data stream; apenas um dos campos é preenchido.
+type UserEvent struct {
+	Execution *ExecutionReport
+	Account   *AccountUpdate
+}
+
+// ListenKeyService gerencia as listen keys que identificam o user data stream da conta.
+type ListenKeyService interface {
+	// CreateListenKey cria (ou retorna a ativa) a listen key da conta, válida por 60 minutos.
+	CreateListenKey(ctx context.Context) (string, error)
+	// KeepAliveListenKey prorroga a validade da listen key por mais 60 minutos.
+	KeepAliveListenKey(ctx context.Context, listenKey string) error
+	// CloseListenKey encerra a listen key e o stream associado.
+	CloseListenKey(ctx context.Context, listenKey string) error
+}
+
+// CreateListenKey cria a listen key do user data stream em /api/v3/userDataStream.
+// Se já houver uma listen key ativa, a Binance retorna a mesma e prorroga sua validade.
+func (b *Binance) CreateListenKey(ctx context.Context) (string, error) {
+	var resp struct {
+		ListenKey string `json:"listenKey"`
+	}
+	if err := b.keyedRequest(ctx, http.MethodPost, "/api/v3/userDataStream", nil, &resp); err != nil {
+		return "", fmt.Errorf("erro ao criar a listen key: %w", err)
+	}
+	if resp.ListenKey == "" {
+		return "", errors.New("listen key vazia na resposta")
+	}
+	return resp.ListenKey, nil
+}
+
+// KeepAliveListenKey prorroga a validade da listen key; deve ser chamado a cada 30 minutos.
+func (b *Binance) KeepAliveListenKey(ctx context.Context, listenKey string) error {
+	params := url.Values{}
+	params.Add("listenKey", listenKey)
+	if err := b.keyedRequest(ctx, http.MethodPut, "/api/v3/userDataStream", params, &struct{}{}); err != nil {
+		return fmt.Errorf("erro ao prorrogar a listen key: %w", err)
+	}
+	return nil
+}
+
+// CloseListenKey encerra a listen key.
+func (b *Binance) CloseListenKey(ctx context.Context, listenKey string) error {
+	params := url.Values{}
+	params.Add("listenKey", listenKey)
+	if err := b.keyedRequest(ctx, http.MethodDelete, "/api/v3/userDataStream", params, &struct{}{}); err != nil {
+		return fmt.Errorf("erro ao encerrar a listen key: %w", err)
+	}
+	return nil
+}
+
+// errListenKeyExpired indica que a corretora encerrou o stream por expiração da listen key.
+var errListenKeyExpired = errors.New("listen key expirada")
+
+// UserStream recebe as atualizações de ordens e saldos da conta pelo user data
+// stream da Binance. A cada conexão uma listen key é obtida e prorrogada a cada
+// KeepAlive; quedas de conexão, falhas na prorrogação e a expiração da listen key
+// são tratadas com reconexões em intervalos crescentes. Eventos perdidos enquanto
+// desconectado não são recuperados; a reconciliação periódica cobre essas lacunas.
+type UserStream struct {
+	// KeepAlive é o intervalo entre as prorrogações da listen key
+	KeepAlive time.Duration
+	// PingInterval é o intervalo entre os pings que detectam conexões perdidas
+	PingInterval time.Duration
+	// MinBackoff e MaxBackoff limitam a espera entre as tentativas de reconexão,
+	// que dobra a cada falha seguida
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	url    string
+	keys   ListenKeyService
+	events chan UserEvent
+}
+
+// NewUserStream cria o user data stream da conta no endereço baseURL dos streams
+// (ex: wss://stream.binance.com:9443), com as listen keys gerenciadas por keys.
+func NewUserStream(baseURL string, keys ListenKeyService) *UserStream {
+	return &UserStream{
+		KeepAlive:    30 * time.Minute,
+		PingInterval: time.Minute,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Minute,
+		url:          strings.TrimRight(baseURL, "/") + "/ws/",
+		keys:         keys,
+		events:       make(chan UserEvent, 64),
+	}
+}
+
+// Events retorna o canal que recebe os eventos de ordens e saldos, na ordem enviada
+// pela corretora. Os eventos não são descartados: a leitura do stream aguarda o
+// consumidor. O canal é fechado quando Run retorna.
+func (s *UserStream) Events() <-chan UserEvent {
+	return s.events
+}
+
+// Run mantém o stream conectado até ctx ser cancelado, reconectando após cada falha.
+// Deve ser chamado uma única vez; sempre retorna o erro de ctx.
+func (s *UserStream) Run(ctx context.Context) error {
+	defer close(s.events)
+
+	backoff := s.MinBackoff
+	for {
+		started := time.Now()
+		err := s.session(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Uma conexão que se manteve por mais tempo que a espera máxima é
+		// considerada estável, e as tentativas voltam a começar pela espera mínima
+		if time.Since(started) > s.MaxBackoff {
+			backoff = s.MinBackoff
+		}
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("User data stream desconectado: %v; reconectando em %s", err, wait.Round(time.Millisecond))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, s.MaxBackoff)
+	}
+}
+
+// session obtém uma listen key, conecta ao stream e repassa os eventos até a
+// conexão falhar, a prorrogação da listen key falhar ou ctx ser cancelado.
+func (s *UserStream) session(ctx context.Context) error {
+	listenKey, err := s.keys.CreateListenKey(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// A listen key é encerrada mesmo após o cancelamento de ctx
+		closeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+		defer cancel()
+		if err := s.keys.CloseListenKey(closeCtx, listenKey); err != nil {
+			log.Print(err)
+		}
+	}()
+
+	conn, _, err := websocket.Dial(ctx, s.url+listenKey, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar: %w", err)
+	}
+	defer conn.CloseNow()
+
+	// A leitura é interrompida quando a manutenção da conexão falha
+	sessionCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go s.maintain(sessionCtx, cancel, conn, listenKey)
+
+	for {
+		_, data, err := conn.Read(sessionCtx)
+		if err != nil {
+			if cause := context.Cause(sessionCtx); cause != nil && ctx.Err() == nil {
+				return cause
+			}
+			return err
+		}
+
+		event, err := parseUserEvent(data)
+		if errors.Is(err, errListenKeyExpired) {
+			return err
+		}
+		if err != nil {
+			log.Printf("Evento do user data stream ignorado: %v", err)
+			continue
+		}
+		if event == nil {
+			continue
+		}
+
+		select {
+		case s.events <- *event:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// maintain prorroga a listen key a cada KeepAlive e envia pings a cada PingInterval
+// até ctx ser cancelado. Uma falha encerra a sessão por meio de cancel.
+func (s *UserStream) maintain(ctx context.Context, cancel context.CancelCauseFunc, conn *websocket.Conn, listenKey string) {
+	keepAlive := time.NewTicker(s.KeepAlive)
+	defer keepAlive.Stop()
+	ping := time.NewTicker(s.PingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-keepAlive.C:
+			if err := s.keys.KeepAliveListenKey(ctx, listenKey); err != nil {
+				cancel(err)
+				return
+			}
+		case <-ping.C:
+			pingCtx, cancelPing := context.WithTimeout(ctx, s.PingInterval)
+			err := conn.Ping(pingCtx)
+			cancelPing()
+			if err != nil && ctx.Err() == nil {
+				cancel(fmt.Errorf("conexão sem resposta ao ping: %w", err))
+				return
+			}
+		}
+	}
+}
+
+// userEventType identifica o tipo de um evento do user data stream.
+// E é declarado para que encoding/json não o leia como e.
+type userEventType struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+}
+
+// binanceExecutionReport é o formato JSON do evento executionReport.
+// Vários campos diferem apenas em maiúsculas (ex: c e C, i e I); como encoding/json
+// aceitaria uma chave pelo campo de outra, todos os pares são declarados.
+type binanceExecutionReport struct {
+	Event             string `json:"e"`
+	EventTime         int64  `json:"E"`
+	Symbol            string `json:"s"`
+	Side              string `json:"S"`
+	ClientOrderID     string `json:"c"`
+	OrigClientOrderID string `json:"C"`
+	Type              string `json:"o"`
+	CreationTime      int64  `json:"O"`
+	OrigQty           string `json:"q"`
+	QuoteOrderQty     string `json:"Q"`
+	ExecutionType     string `json:"x"`
+	Status            string `json:"X"`
+	OrderID           int64  `json:"i"`
+	IgnoreID          int64  `json:"I"`
+	LastQty           string `json:"l"`
+	LastPrice         string `json:"L"`
+	Commission        string `json:"n"`
+	CommissionAsset   string `json:"N"`
+	TradeID           int64  `json:"t"`
+	TransactTime      int64  `json:"T"`
+	ExecutedQty       string `json:"z"`
+	CumQuoteQty       string `json:"Z"`
+}
+
+// binanceAccountPosition é o formato JSON do evento outboundAccountPosition.
+type binanceAccountPosition struct {
+	Event      string `json:"e"`
+	EventTime  int64  `json:"E"`
+	UpdateTime int64  `json:"u"`
+	Balances   []struct {
+		Asset  string `json:"a"`
+		Free   string `json:"f"`
+		Locked string `json:"l"`
+	} `json:"B"`
+}
+
+// parseUserEvent converte uma mensagem do user data stream em UserEvent.
+// Retorna nil, sem erro, para os eventos não utilizados (ex: balanceUpdate) e
+// errListenKeyExpired quando a corretora encerra o stream.
+func parseUserEvent(data []byte) (*UserEvent, error) {
+	var kind userEventType
+	if err := json.Unmarshal(data, &kind); err != nil {
+		return nil, fmt.Errorf("evento com formato inesperado: %w", err)
+	}
+
+	var p numberParser
+	switch kind.Event {
+	case "executionReport":
+		var raw binanceExecutionReport
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("executionReport com formato inesperado: %w", err)
+		}
+		clientOrderID := raw.ClientOrderID
+		if raw.OrigClientOrderID != "" {
+			clientOrderID = raw.OrigClientOrderID
+		}
+		report := &ExecutionReport{
+			Symbol:              raw.Symbol,
+			OrderID:             raw.OrderID,
+			ClientOrderID:       clientOrderID,
+			Side:                raw.Side,
+			Type:                raw.Type,
+			ExecutionType:       raw.ExecutionType,
+			Status:              raw.Status,
+			OrigQty:             p.decimal(raw.OrigQty),
+			ExecutedQty:         p.decimal(raw.ExecutedQty),
+			CummulativeQuoteQty: p.decimal(raw.CumQuoteQty),
+			LastQty:             p.decimal(raw.LastQty),
+			LastPrice:           p.decimal(raw.LastPrice),
+			Commission:          p.decimal(raw.Commission),
+			CommissionAsset:     raw.CommissionAsset,
+			TradeID:             raw.TradeID,
+			TransactTime:        raw.TransactTime,
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("executionReport da ordem %d com formato inesperado: %w", raw.OrderID, p.err)
+		}
+		return &UserEvent{Execution: report}, nil
+
+	case "outboundAccountPosition":
+		var raw binanceAccountPosition
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return nil, fmt.Errorf("outboundAccountPosition com formato inesperado: %w", err)
+		}
+		update := &AccountUpdate{EventTime: raw.EventTime}
+		for _, b := range raw.Balances {
+			update.Balances = append(update.Balances, Balance{
+				Asset:  b.Asset,
+				Free:   p.decimal(b.Free),
+				Locked: p.decimal(b.Locked),
+			})
+		}
+		if p.err != nil {
+			return nil, fmt.Errorf("outboundAccountPosition com formato inesperado: %w", p.err)
+		}
+		return &UserEvent{Account: update}, nil
+
+	case "listenKeyExpired":
+		return nil, errListenKeyExpired
+	}
+	return nil, nil
+}
@@ -0,0 +1,158 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+)
+
+// userStreamServer simula a API e o user data stream da Binance: a primeira conexão
+// envia messages e a expiração da listen key; as seguintes permanecem abertas.
+type userStreamServer struct {
+	messages    []string
+	creates     atomic.Int32
+	keepAlives  atomic.Int32
+	closes      atomic.Int32
+	connections atomic.Int32
+}
+
+func (s *userStreamServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v3/userDataStream" {
+		if r.Header.Get("X-MBX-APIKEY") != "key" {
+			http.Error(w, `{"code":-2015,"msg":"Invalid API-key"}`, http.StatusUnauthorized)
+			return
+		}
+		switch r.Method {
+		case http.MethodPost:
+			s.creates.Add(1)
+			w.Write([]byte(`{"listenKey":"abc123"}`))
+		case http.MethodPut:
+			s.keepAlives.Add(1)
+			w.Write([]byte(`{}`))
+		case http.MethodDelete:
+			s.closes.Add(1)
+			w.Write([]byte(`{}`))
+		}
+		return
+	}
+	if r.URL.Path != "/ws/abc123" {
+		http.NotFound(w, r)
+		return
+	}
+
+	conn, err := websocket.Accept(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+	if s.connections.Add(1) > 1 {
+		<-r.Context().Done()
+		return
+	}
+	for _, msg := range append(s.messages, `{"e":"listenKeyExpired","E":1576653824250,"listenKey":"abc123"}`) {
+		if err := conn.Write(r.Context(), websocket.MessageText, []byte(msg)); err != nil {
+			return
+		}
+	}
+	// Aguarda o cliente encerrar a conexão após a expiração
+	conn.Read(r.Context())
+}
+
+func TestUserStream(t *testing.T) {
+	server := &userStreamServer{messages: []string{
+		`{"e":"executionReport","E":1499405658658,"s":"BTCUSDT","c":"bot1","S":"BUY","o":"MARKET","f":"GTC",` +
+			`"q":"0.00200000","p":"0","P":"0","F":"0","g":-1,"C":"","x":"TRADE","X":"PARTIALLY_FILLED","r":"NONE",` +
+			`"i":4293153,"l":"0.00100000","z":"0.00100000","L":"65000.00","n":"0.00000100","N":"BTC","T":1499405658657,` +
+			`"t":77,"I":8641984,"w":false,"m":false,"M":true,"O":1499405658650,"Z":"65.00000000","Y":"65","Q":"0"}`,
+		`{"e":"balanceUpdate","E":1573200697110,"a":"BTC","d":"100.00000000","T":1573200697068}`,
+		`{"e":"outboundAccountPosition","E":1564034571105,"u":1564034571073,"B":[{"a":"BTC","f":"0.00099900","l":"0.00000000"}]}`,
+		`{"e":"executionReport","E":1499405658700,"s":"BTCUSDT","c":"web_x","S":"SELL","o":"LIMIT","q":"1","x":"CANCELED",` +
+			`"X":"CANCELED","C":"manual1","i":99,"I":1,"l":"0","z":"0","L":"0","n":"0","N":null,"T":1499405658700,"t":-1,"O":1,"Z":"0"}`,
+	}}
+	ts := httptest.NewServer(server)
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s := NewUserStream(strings.Replace(ts.URL, "http", "ws", 1), NewBinance(ts.URL, "key", "secret"))
+	s.KeepAlive = 20 * time.Millisecond
+	s.MinBackoff = 10 * time.Millisecond
+	s.MaxBackoff = 10 * time.Millisecond
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	var events []UserEvent
+	for len(events) < 3 {
+		select {
+		case ev := <-s.Events():
+			events = append(events, ev)
+		case <-ctx.Done():
+			t.Fatalf("recebidos %d de 3 eventos", len(events))
+		}
+	}
+
+	trade := events[0].Execution
+	if trade == nil || trade.OrderID != 4293153 || trade.ClientOrderID != "bot1" || trade.ExecutionType != ExecutionTrade ||
+		!trade.ExecutedQty.Equal(dec("0.001")) || !trade.CummulativeQuoteQty.Equal(dec("65")) ||
+		!trade.LastPrice.Equal(dec("65000")) || trade.CommissionAsset != "BTC" || trade.TradeID != 77 || trade.TransactTime != 1499405658657 {
+		t.Errorf("executionReport convertido incorretamente: %+v", trade)
+	}
+	account := events[1].Account
+	if account == nil || len(account.Balances) != 1 || account.Balances[0].Asset != "BTC" || !account.Balances[0].Free.Equal(dec("0.000999")) {
+		t.Errorf("outboundAccountPosition convertido incorretamente: %+v", account)
+	}
+	canceled := events[2].Execution
+	if canceled == nil || canceled.ClientOrderID != "manual1" || canceled.Status != "CANCELED" || canceled.CommissionAsset != "" {
+		t.Errorf("cancelamento convertido incorretamente: %+v", canceled)
+	}
+
+	// A expiração da listen key encerra a conexão; uma nova listen key é obtida e prorrogada
+	for server.creates.Load() < 2 || server.keepAlives.Load() < 1 || server.closes.Load() < 1 {
+		select {
+		case <-ctx.Done():
+			t.Fatalf("%d listen keys criadas, %d prorrogações e %d encerramentos", server.creates.Load(), server.keepAlives.Load(), server.closes.Load())
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, esperado context.Canceled", err)
+	}
+	if _, ok := <-s.Events(); ok {
+		t.Error("canal de eventos não foi fechado")
+	}
+}
+
+func TestParseUserEvent_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		msg  string
+	}{
+		{"Should reject invalid JSON", `{`},
+		{"Should reject invalid quantity", `{"e":"executionReport","i":1,"z":"abc"}`},
+		{"Should reject invalid balance", `{"e":"outboundAccountPosition","B":[{"a":"BTC","f":"x","l":"0"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseUserEvent([]byte(tt.msg)); err == nil {
+				t.Error("evento aceito, esperado erro")
+			}
+		})
+	}
+
+	if ev, err := parseUserEvent([]byte(`{"e":"balanceUpdate"}`)); ev != nil || err != nil {
+		t.Errorf("evento não utilizado retornou %+v, erro: %v", ev, err)
+	}
+	if _, err := parseUserEvent([]byte(`{"e":"listenKeyExpired"}`)); err != errListenKeyExpired {
+		t.Errorf("expiração retornou %v, esperado errListenKeyExpired", err)
+	}
+}
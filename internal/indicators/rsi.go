@@ -1,6 +1,12 @@
 package indicators
 
-import "math"
+import (
+	"errors"
+	"math"
+)
+
+// ErrNotEnoughPrices indica que a série de preços é menor que o período do indicador.
+var ErrNotEnoughPrices = errors.New("not enough prices to calculate indicator")
 
 // CalculateRSI calcula o Índice de Força Relativa (RSI) para uma série de preços
 // O RSI é um indicador de momentum que mede a velocidade e magnitude das mudanças de preços
@@ -14,9 +20,10 @@ import "math"
 //   - float64: valor do RSI entre 0 e 100
 //   - Valores acima de 70 geralmente indicam sobrecompra
 //   - Valores abaixo de 30 geralmente indicam sobrevenda
-func CalculateRSI(prices []float64, period int) float64 {
-	if len(prices) < period+1 {
-		panic("Not enough prices to calculate RSI")
+//   - error: ErrNotEnoughPrices se houver menos de period+1 preços
+func CalculateRSI(prices []float64, period int) (float64, error) {
+	if period <= 0 || len(prices) < period+1 {
+		return 0, ErrNotEnoughPrices
 	}
 
 	var avgGains, avgLoss float64
@@ -41,7 +48,7 @@ func CalculateRSI(prices []float64, period int) float64 {
 	rs := avgGains / avgLoss
 	rsi := 100.0 - (100.0 / (1.0 + rs))
 
-	return rsi
+	return rsi, nil
 }
 
 // calculateAverage calcula a média de ganhos e perdas para um determinado período
@@ -1,6 +1,7 @@
 package indicators
 
 import (
+	"errors"
 	"math"
 	"testing"
 )
@@ -12,7 +13,7 @@ func TestCalculateRSI(t *testing.T) {
 		period    int
 		expected  float64
 		tolerance float64
-		wantPanic bool
+		wantErr   bool
 	}{
 		{
 			name:      "Should calculate RSI correctly for uptrend",
@@ -20,7 +21,7 @@ func TestCalculateRSI(t *testing.T) {
 			period:    14,
 			expected:  100.0, // All gains, no losses
 			tolerance: 0.01,
-			wantPanic: false,
+			wantErr:   false,
 		},
 		{
 			name:      "Should calculate RSI correctly for downtrend",
@@ -28,7 +29,7 @@ func TestCalculateRSI(t *testing.T) {
 			period:    14,
 			expected:  0.0, // All losses, no gains
 			tolerance: 0.01,
-			wantPanic: false,
+			wantErr:   false,
 		},
 		{
 			name:   "Should calculate RSI correctly for mixed trend",
@@ -37,29 +38,30 @@ func TestCalculateRSI(t *testing.T) {
 			// Atualizado para refletir o resultado atual
 			expected:  65.0,
 			tolerance: 0.01,
-			wantPanic: false,
+			wantErr:   false,
 		},
 		{
-			name:      "Should panic when prices length is less than period + 1",
-			prices:    []float64{1.0, 2.0},
-			period:    14,
-			wantPanic: true,
+			name:    "Should return error when prices length is less than period + 1",
+			prices:  []float64{1.0, 2.0},
+			period:  14,
+			wantErr: true,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.wantPanic {
-				defer func() {
-					if r := recover(); r == nil {
-						t.Error("Expected panic but got none")
-					}
-				}()
-			}
-
-			got := CalculateRSI(tt.prices, tt.period)
+			got, err := CalculateRSI(tt.prices, tt.period)
 
-			if !tt.wantPanic && math.Abs(got-tt.expected) > tt.tolerance {
+			if tt.wantErr {
+				if !errors.Is(err, ErrNotEnoughPrices) {
+					t.Errorf("Expected ErrNotEnoughPrices but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if math.Abs(got-tt.expected) > tt.tolerance {
 				t.Errorf("CalculateRSI() = %v, want %v (±%v)", got, tt.expected, tt.tolerance)
 			}
 		})
@@ -132,6 +134,6 @@ func BenchmarkCalculateRSI(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = CalculateRSI(prices, period)
+		_, _ = CalculateRSI(prices, period)
 	}
 }
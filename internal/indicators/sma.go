@@ -7,9 +7,10 @@ package indicators
 //
 // Retorna:
 //   - float64: valor da média móvel
-func CalculateSMA(prices []float64, period int) float64 {
-	if len(prices) < period {
-		panic("Not enough prices to calculate SMA")
+//   - error: ErrNotEnoughPrices se houver menos preços que o período
+func CalculateSMA(prices []float64, period int) (float64, error) {
+	if period <= 0 || len(prices) < period {
+		return 0, ErrNotEnoughPrices
 	}
 
 	sum := 0.0
@@ -17,5 +18,5 @@ func CalculateSMA(prices []float64, period int) float64 {
 		sum += prices[i]
 	}
 
-	return sum / float64(period)
+	return sum / float64(period), nil
 }
@@ -0,0 +1,151 @@
+package marketdata
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// pageLimit é a quantidade máxima de candles por requisição aceita pela Binance.
+const pageLimit = 1000
+
+// Gap representa um período sem candles no histórico local.
+type Gap struct {
+	// Start é a abertura do primeiro candle ausente.
+	Start time.Time
+	// End é a abertura do próximo candle registrado, ou o fim do período verificado.
+	End time.Time
+}
+
+// BackfillResult resume a execução de Backfill.
+type BackfillResult struct {
+	// Saved é a quantidade de candles gravados.
+	Saved int
+	// Requests é a quantidade de requisições feitas à corretora.
+	Requests int
+	// Gaps são os períodos que continuaram sem candles após o download
+	// (ex: manutenção da corretora ou par ainda não listado).
+	Gaps []Gap
+}
+
+// FindGaps retorna os períodos entre start e end sem candles em candles.
+// Parâmetros:
+//   - candles: candles registrados, ordenados pelo horário de abertura
+//   - interval: intervalo dos candles (ex: "15m")
+//   - start, end: período verificado; só são esperados candles fechados até end
+//
+// Os candles são alinhados como na Binance (ver exchange.NextCandleClose); um
+// start no meio de um candle é arredondado para a abertura do candle seguinte.
+// Retorna erro se o intervalo não for aceito pela Binance.
+func FindGaps(candles []database.Candle, interval string, start, end time.Time) ([]Gap, error) {
+	// O próximo fechamento após start-1ms é a primeira abertura a partir de start
+	cursor, err := exchange.NextCandleClose(interval, start.Add(-time.Millisecond))
+	if err != nil {
+		return nil, err
+	}
+
+	var gaps []Gap
+	for _, c := range candles {
+		open := time.UnixMilli(c.OpenTime).UTC()
+		if open.Before(cursor) {
+			continue
+		}
+		if open.After(cursor) {
+			gaps = append(gaps, Gap{Start: cursor, End: open})
+		}
+		if cursor, err = exchange.NextCandleClose(interval, open); err != nil {
+			return nil, err
+		}
+	}
+
+	// Falta o final do período se o candle aberto em cursor já estava fechado em end
+	closeAt, err := exchange.NextCandleClose(interval, cursor)
+	if err != nil {
+		return nil, err
+	}
+	if !closeAt.After(end) {
+		gaps = append(gaps, Gap{Start: cursor, End: end})
+	}
+	return gaps, nil
+}
+
+// Backfill baixa da corretora os candles fechados do par entre start e end que
+// ainda não estão no histórico local e os grava em store.
+// Parâmetros:
+//   - ex: corretora consultada, paginando /api/v3/klines com startTime e endTime
+//   - store: histórico local de candles
+//   - symbol, interval: par e intervalo dos candles (ex: "BTCUSDT", "1h")
+//   - start, end: período a ser completado; end não deve ser posterior ao instante atual
+//   - out: destino das mensagens de progresso
+//
+// Apenas os períodos sem candles (ver FindGaps) são baixados, de modo que uma
+// execução interrompida pode ser retomada sem repetir o download.
+//
+// Retorna o resumo do download, com os períodos que continuaram sem candles,
+// ou erro se alguma requisição ou gravação falhar.
+func Backfill(ctx context.Context, ex exchange.Exchange, store database.Store, symbol string, interval string, start, end time.Time, out io.Writer) (*BackfillResult, error) {
+	stored, err := store.GetCandles(ctx, symbol, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+	gaps, err := FindGaps(stored, interval, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BackfillResult{}
+	for _, gap := range gaps {
+		if err := fetchGap(ctx, ex, store, symbol, interval, gap, end, result, out); err != nil {
+			return result, err
+		}
+	}
+
+	stored, err = store.GetCandles(ctx, symbol, interval, start, end)
+	if err != nil {
+		return result, err
+	}
+	result.Gaps, err = FindGaps(stored, interval, start, end)
+	return result, err
+}
+
+// fetchGap baixa os candles abertos em gap, página por página, gravando apenas os
+// fechados até end. O total gravado e de requisições é acumulado em result.
+func fetchGap(ctx context.Context, ex exchange.Exchange, store database.Store, symbol string, interval string, gap Gap, end time.Time, result *BackfillResult, out io.Writer) error {
+	cursor := gap.Start
+	for cursor.Before(gap.End) {
+		// endTime é inclusivo na Binance
+		candles, err := ex.GetKlinesRange(ctx, symbol, interval, cursor, gap.End.Add(-time.Millisecond), pageLimit)
+		if err != nil {
+			return fmt.Errorf("erro ao baixar candles de %s a partir de %s: %w", symbol, cursor.Format(time.DateTime), err)
+		}
+		result.Requests++
+		if len(candles) == 0 {
+			return nil
+		}
+
+		closed := candles[:0:0]
+		for _, c := range candles {
+			if c.CloseTime < end.UnixMilli() {
+				closed = append(closed, c)
+			}
+		}
+		if err := store.SaveCandles(ctx, CandleRecords(symbol, interval, closed)); err != nil {
+			return fmt.Errorf("erro ao gravar candles de %s: %w", symbol, err)
+		}
+		result.Saved += len(closed)
+
+		last := time.UnixMilli(candles[len(candles)-1].OpenTime).UTC()
+		fmt.Fprintf(out, "%s %s: %d candles gravados até %s\n", symbol, interval, result.Saved, last.Format(time.DateTime))
+		// Uma página incompleta encerra o período; uma página anterior ao cursor
+		// evitaria que a paginação avançasse
+		if len(candles) < pageLimit || last.Before(cursor) {
+			return nil
+		}
+		cursor = last.Add(time.Millisecond)
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package marketdata
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// fakeHistory gera candles de 1 minuto, exceto no período de manutenção [outageStart, outageEnd).
+type fakeHistory struct {
+	exchange.Exchange
+	outageStart, outageEnd time.Time
+	requests               int
+}
+
+func (f *fakeHistory) GetKlinesRange(ctx context.Context, symbol string, interval string, start, end time.Time, limit int) ([]exchange.Candlestick, error) {
+	f.requests++
+	var candles []exchange.Candlestick
+	for open := start.Truncate(time.Minute); !open.After(end) && len(candles) < limit; open = open.Add(time.Minute) {
+		if open.Before(start) || !open.Before(f.outageStart) && open.Before(f.outageEnd) {
+			continue
+		}
+		candles = append(candles, exchange.Candlestick{
+			OpenTime:  open.UnixMilli(),
+			CloseTime: open.Add(time.Minute).UnixMilli() - 1,
+			Close:     float64(open.Unix()),
+		})
+	}
+	return candles, nil
+}
+
+// minute retorna o horário m minutos após o início de 2024.
+func minute(m int) time.Time {
+	return time.Date(2024, 1, 1, 0, m, 0, 0, time.UTC)
+}
+
+func TestFindGaps(t *testing.T) {
+	record := func(m int) database.Candle {
+		return database.Candle{OpenTime: minute(m).UnixMilli(), CloseTime: minute(m+1).UnixMilli() - 1}
+	}
+
+	tests := []struct {
+		name    string
+		candles []database.Candle
+		start   time.Time
+		end     time.Time
+		want    []Gap
+	}{
+		{
+			name:    "Should find no gaps in a complete history",
+			candles: []database.Candle{record(0), record(1), record(2)},
+			start:   minute(0),
+			end:     minute(3),
+		},
+		{
+			name:    "Should find gaps at the start, middle and end",
+			candles: []database.Candle{record(2), record(5)},
+			start:   minute(0),
+			end:     minute(8),
+			want: []Gap{
+				{Start: minute(0), End: minute(2)},
+				{Start: minute(3), End: minute(5)},
+				{Start: minute(6), End: minute(8)},
+			},
+		},
+		{
+			name:  "Should report the whole period when there are no candles",
+			start: minute(0),
+			end:   minute(10),
+			want:  []Gap{{Start: minute(0), End: minute(10)}},
+		},
+		{
+			name:    "Should ignore the candle still open at the end",
+			candles: []database.Candle{record(0)},
+			start:   minute(0),
+			end:     minute(1).Add(30 * time.Second),
+		},
+		{
+			name:    "Should align start to the next candle open",
+			candles: []database.Candle{record(1)},
+			start:   minute(0).Add(10 * time.Second),
+			end:     minute(2),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FindGaps(tt.candles, "1m", tt.start, tt.end)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("FindGaps() = %v, esperado %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Start.Equal(tt.want[i].Start) || !got[i].End.Equal(tt.want[i].End) {
+					t.Errorf("lacuna %d = %v, esperado %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBackfill(t *testing.T) {
+	ctx := context.Background()
+	store := database.NewMemoryStore()
+	ex := &fakeHistory{outageStart: minute(1500), outageEnd: minute(1510)}
+
+	// Um candle já registrado divide o período em dois downloads
+	if err := store.SaveCandles(ctx, CandleRecords("BTCUSDT", "1m", []exchange.Candlestick{
+		{OpenTime: minute(100).UnixMilli(), CloseTime: minute(101).UnixMilli() - 1},
+	})); err != nil {
+		t.Fatal(err)
+	}
+
+	start, end := minute(0), minute(2500)
+	result, err := Backfill(ctx, ex, store, "BTCUSDT", "1m", start, end, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 2500 candles no período, menos o já registrado e os 10 da manutenção
+	if result.Saved != 2489 {
+		t.Errorf("Saved = %d, esperado 2489", result.Saved)
+	}
+	if len(result.Gaps) != 1 || !result.Gaps[0].Start.Equal(minute(1500)) || !result.Gaps[0].End.Equal(minute(1510)) {
+		t.Errorf("Gaps = %v, esperado a manutenção entre os minutos 1500 e 1510", result.Gaps)
+	}
+	stored, err := store.GetCandles(ctx, "BTCUSDT", "1m", start, end)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2490 {
+		t.Errorf("registrados %d candles, esperado 2490", len(stored))
+	}
+
+	// Uma nova execução baixa apenas o período ainda sem candles
+	ex.requests = 0
+	result, err = Backfill(ctx, ex, store, "BTCUSDT", "1m", start, end, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Saved != 0 || ex.requests != 1 {
+		t.Errorf("nova execução gravou %d candles em %d requisições, esperado 0 em 1", result.Saved, ex.requests)
+	}
+}
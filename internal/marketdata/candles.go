@@ -0,0 +1,51 @@
+package marketdata
+
+import (
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// CandleRecords converte os candles da corretora em registros do histórico local.
+func CandleRecords(symbol string, interval string, candles []exchange.Candlestick) []database.Candle {
+	records := make([]database.Candle, len(candles))
+	for i, c := range candles {
+		records[i] = database.Candle{
+			Symbol:              symbol,
+			Interval:            interval,
+			OpenTime:            c.OpenTime,
+			CloseTime:           c.CloseTime,
+			Open:                c.Open,
+			High:                c.High,
+			Low:                 c.Low,
+			Close:               c.Close,
+			Volume:              c.Volume,
+			QuoteVolume:         c.QuoteAssetVolume,
+			Trades:              c.NumberOfTrades,
+			TakerBuyBaseVolume:  c.TakerBuyBaseAssetVolume,
+			TakerBuyQuoteVolume: c.TakerBuyQuoteAssetVolume,
+		}
+	}
+	return records
+}
+
+// Candlesticks converte registros do histórico local nos candles usados pelas
+// estratégias e pelo backtest.
+func Candlesticks(records []database.Candle) []exchange.Candlestick {
+	candles := make([]exchange.Candlestick, len(records))
+	for i, r := range records {
+		candles[i] = exchange.Candlestick{
+			OpenTime:                 r.OpenTime,
+			Open:                     r.Open,
+			High:                     r.High,
+			Low:                      r.Low,
+			Close:                    r.Close,
+			Volume:                   r.Volume,
+			CloseTime:                r.CloseTime,
+			QuoteAssetVolume:         r.QuoteVolume,
+			NumberOfTrades:           r.Trades,
+			TakerBuyBaseAssetVolume:  r.TakerBuyBaseVolume,
+			TakerBuyQuoteAssetVolume: r.TakerBuyQuoteVolume,
+		}
+	}
+	return candles
+}
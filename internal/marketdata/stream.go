@@ -0,0 +1,300 @@
+package marketdata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/utils"
+	"github.com/coder/websocket"
+)
+
+// ErrCandleGap indica um candle que não é o seguinte ao último do Buffer: os
+// candles entre eles não foram recebidos (ex: eventos perdidos pelo stream).
+var ErrCandleGap = errors.New("candles ausentes entre o último registrado e o recebido")
+
+// Buffer mantém em memória os candles mais recentes de um par, ordenados pela abertura.
+// Pode ser usado por várias goroutines ao mesmo tempo.
+type Buffer struct {
+	mu      sync.Mutex
+	candles []exchange.Candlestick
+	size    int
+}
+
+// NewBuffer cria um Buffer que mantém no máximo size candles.
+func NewBuffer(size int) *Buffer {
+	return &Buffer{size: size}
+}
+
+// Seed substitui o conteúdo do buffer pelos candles informados, mantendo os size mais recentes.
+func (b *Buffer) Seed(candles []exchange.Candlestick) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if len(candles) > b.size {
+		candles = candles[len(candles)-b.size:]
+	}
+	b.candles = append(b.candles[:0:0], candles...)
+}
+
+// Update registra c no buffer: substitui o último candle se ambos tiverem a mesma
+// abertura ou o acrescenta ao final, descartando o mais antigo ao exceder o tamanho.
+// Retorna false, sem alterar o buffer, se c for anterior ao último candle registrado,
+// ou ErrCandleGap se c não abrir no fechamento do último candle.
+func (b *Buffer) Update(c exchange.Candlestick) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if n := len(b.candles); n > 0 {
+		last := b.candles[n-1]
+		switch {
+		case c.OpenTime < last.OpenTime:
+			return false, nil
+		case c.OpenTime == last.OpenTime:
+			b.candles[n-1] = c
+			return true, nil
+		case last.CloseTime > 0 && c.OpenTime != last.CloseTime+1:
+			// O fechamento informado pela Binance é o milissegundo anterior à abertura seguinte
+			return false, fmt.Errorf("%w: %s a %s", ErrCandleGap,
+				time.UnixMilli(last.CloseTime+1).UTC().Format(time.DateTime), time.UnixMilli(c.OpenTime).UTC().Format(time.DateTime))
+		}
+	}
+	b.candles = append(b.candles, c)
+	if len(b.candles) > b.size {
+		b.candles = b.candles[len(b.candles)-b.size:]
+	}
+	return true, nil
+}
+
+// Candles retorna uma cópia dos candles do buffer, incluindo o candle em formação.
+func (b *Buffer) Candles() []exchange.Candlestick {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]exchange.Candlestick(nil), b.candles...)
+}
+
+// KlineStream acompanha os candles de um par pelo stream <symbol>@kline_<interval>
+// da Binance, mantendo-os em um Buffer. A cada conexão o buffer é carregado com
+// uma única requisição REST; daí em diante é atualizado apenas pelos eventos do stream,
+// e recarregado pela API REST se faltarem candles entre os eventos.
+// Quedas de conexão são tratadas com reconexões em intervalos crescentes.
+type KlineStream struct {
+	Symbol   string
+	Interval string
+
+	// MinBackoff e MaxBackoff limitam a espera entre as tentativas de reconexão,
+	// que dobra a cada falha seguida
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// IdleTimeout é o tempo máximo sem eventos antes de considerar a conexão perdida
+	IdleTimeout time.Duration
+
+	url    string
+	ex     exchange.Exchange
+	buffer *Buffer
+	closed chan exchange.Candlestick
+	live   atomic.Bool
+}
+
+// NewKlineStream cria o stream de klines do par no endereço baseURL (ex: wss://stream.binance.com:9443),
+// mantendo os size candles mais recentes. ex é usada apenas para carregar o buffer a cada conexão.
+func NewKlineStream(baseURL string, ex exchange.Exchange, symbol string, interval string, size int) *KlineStream {
+	return &KlineStream{
+		Symbol:      symbol,
+		Interval:    interval,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+		IdleTimeout: 2 * time.Minute,
+		url:         fmt.Sprintf("%s/ws/%s@kline_%s", strings.TrimRight(baseURL, "/"), strings.ToLower(symbol), interval),
+		ex:          ex,
+		buffer:      NewBuffer(size),
+		closed:      make(chan exchange.Candlestick, 16),
+	}
+}
+
+// Closed retorna o canal que recebe cada candle fechado informado pelo stream.
+// O canal é fechado quando Run retorna.
+func (s *KlineStream) Closed() <-chan exchange.Candlestick {
+	return s.closed
+}
+
+// Candles retorna os candles mantidos em memória, incluindo o candle em formação.
+func (s *KlineStream) Candles() []exchange.Candlestick {
+	return s.buffer.Candles()
+}
+
+// Live informa se o stream está conectado e com o buffer carregado.
+// Enquanto desconectado, os candles do buffer podem estar desatualizados.
+func (s *KlineStream) Live() bool {
+	return s.live.Load()
+}
+
+// Run mantém o stream conectado até ctx ser cancelado, reconectando após cada falha.
+// Deve ser chamado uma única vez; sempre retorna o erro de ctx.
+func (s *KlineStream) Run(ctx context.Context) error {
+	defer close(s.closed)
+
+	backoff := s.MinBackoff
+	for {
+		started := time.Now()
+		err := s.session(ctx)
+		s.live.Store(false)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		// Uma conexão que se manteve por mais tempo que a espera máxima é
+		// considerada estável, e as tentativas voltam a começar pela espera mínima
+		if time.Since(started) > s.MaxBackoff {
+			backoff = s.MinBackoff
+		}
+		// A variação aleatória evita que todos os pares reconectem ao mesmo tempo
+		wait := backoff/2 + rand.N(backoff/2+1)
+		log.Printf("Stream de %s desconectado: %v; reconectando em %s", s.Symbol, err, wait.Round(time.Millisecond))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		backoff = min(backoff*2, s.MaxBackoff)
+	}
+}
+
+// session conecta ao stream, carrega o buffer e processa os eventos até a conexão falhar.
+func (s *KlineStream) session(ctx context.Context) error {
+	conn, _, err := websocket.Dial(ctx, s.url, nil)
+	if err != nil {
+		return fmt.Errorf("erro ao conectar: %w", err)
+	}
+	defer conn.CloseNow()
+
+	// O buffer é carregado depois da conexão para que nenhum candle fechado entre a
+	// requisição e o primeiro evento seja perdido; eventos já refletidos na resposta
+	// apenas substituem o último candle
+	if err := s.load(ctx); err != nil {
+		return fmt.Errorf("erro ao carregar os candles iniciais: %w", err)
+	}
+	s.live.Store(true)
+
+	for {
+		readCtx, cancel := context.WithTimeout(ctx, s.IdleTimeout)
+		_, data, err := conn.Read(readCtx)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		c, closed, err := parseKlineEvent(data)
+		if err != nil {
+			log.Printf("Evento do stream de %s ignorado: %v", s.Symbol, err)
+			continue
+		}
+		applied, err := s.buffer.Update(c)
+		if errors.Is(err, ErrCandleGap) {
+			// Os candles ausentes são obtidos pela API REST; se ela ainda não
+			// refletir o candle recebido, a conexão é refeita
+			log.Printf("Stream de %s com %v; recarregando os candles", s.Symbol, err)
+			if err := s.load(ctx); err != nil {
+				return fmt.Errorf("erro ao recarregar os candles: %w", err)
+			}
+			if applied, err = s.buffer.Update(c); err != nil {
+				return err
+			}
+		}
+		if !applied || !closed {
+			continue
+		}
+
+		// Um consumidor lento não pode travar a leitura do stream
+		select {
+		case s.closed <- c:
+		default:
+			log.Printf("Fechamento do candle de %s descartado: consumidor ocupado", s.Symbol)
+		}
+	}
+}
+
+// load carrega o buffer com os candles mais recentes obtidos pela API REST.
+func (s *KlineStream) load(ctx context.Context) error {
+	candles, err := s.ex.GetKlines(ctx, s.Symbol, s.Interval, s.buffer.size)
+	if err != nil {
+		return err
+	}
+	s.buffer.Seed(candles)
+	return nil
+}
+
+// klineEvent é o evento enviado pelo stream <symbol>@kline_<interval>.
+// Os horários e a quantidade de trades são números JSON; os preços e volumes, strings.
+// Os campos E e L, não utilizados, são declarados porque encoding/json aceitaria
+// as chaves como e e l, que diferem apenas em maiúsculas e têm outros tipos.
+type klineEvent struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+	Kline     struct {
+		OpenTime            int64  `json:"t"`
+		CloseTime           int64  `json:"T"`
+		Open                string `json:"o"`
+		Close               string `json:"c"`
+		High                string `json:"h"`
+		Low                 string `json:"l"`
+		Volume              string `json:"v"`
+		LastTradeID         int64  `json:"L"`
+		Trades              int64  `json:"n"`
+		Closed              bool   `json:"x"`
+		QuoteVolume         string `json:"q"`
+		TakerBuyBaseVolume  string `json:"V"`
+		TakerBuyQuoteVolume string `json:"Q"`
+	} `json:"k"`
+}
+
+// parseKlineEvent converte um evento do stream no candle informado,
+// indicando se o candle já está fechado.
+func parseKlineEvent(data []byte) (exchange.Candlestick, bool, error) {
+	var ev klineEvent
+	if err := json.Unmarshal(data, &ev); err != nil {
+		return exchange.Candlestick{}, false, fmt.Errorf("evento com formato inesperado: %w", err)
+	}
+	if ev.Event != "kline" {
+		return exchange.Candlestick{}, false, fmt.Errorf("evento inesperado: %q", ev.Event)
+	}
+
+	k := ev.Kline
+	c := exchange.Candlestick{
+		OpenTime:       k.OpenTime,
+		CloseTime:      k.CloseTime,
+		NumberOfTrades: k.Trades,
+	}
+	fields := []struct {
+		str string
+		dst *float64
+	}{
+		{k.Open, &c.Open},
+		{k.High, &c.High},
+		{k.Low, &c.Low},
+		{k.Close, &c.Close},
+		{k.Volume, &c.Volume},
+		{k.QuoteVolume, &c.QuoteAssetVolume},
+		{k.TakerBuyBaseVolume, &c.TakerBuyBaseAssetVolume},
+		{k.TakerBuyQuoteVolume, &c.TakerBuyQuoteAssetVolume},
+	}
+	for _, f := range fields {
+		v, err := utils.ParseFloat(f.str)
+		if err != nil {
+			return exchange.Candlestick{}, false, fmt.Errorf("candle com formato inesperado: %w", err)
+		}
+		*f.dst = v
+	}
+	return c, k.Closed, nil
+}
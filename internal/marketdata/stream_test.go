@@ -0,0 +1,266 @@
+package marketdata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/coder/websocket"
+)
+
+// fakeKlines retorna candles de 1 minuto fechados até minute(9) na primeira
+// requisição, avançando um minuto a cada nova requisição.
+type fakeKlines struct {
+	exchange.Exchange
+	requests atomic.Int32
+}
+
+func (f *fakeKlines) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]exchange.Candlestick, error) {
+	last := 8 + int(f.requests.Add(1))
+	candles := make([]exchange.Candlestick, limit)
+	for i := range candles {
+		candles[i] = candle(last - limit + 1 + i)
+	}
+	return candles, nil
+}
+
+// candle retorna o candle de 1 minuto aberto em minute(m).
+func candle(m int) exchange.Candlestick {
+	return exchange.Candlestick{OpenTime: minute(m).UnixMilli(), CloseTime: minute(m+1).UnixMilli() - 1, Close: float64(m)}
+}
+
+// klineMessage monta o evento do stream para o candle aberto em minute(m).
+func klineMessage(m int, closed bool) string {
+	c := candle(m)
+	return fmt.Sprintf(`{"e":"kline","E":%d,"s":"BTCUSDT","k":{"t":%d,"T":%d,"s":"BTCUSDT","i":"1m",`+
+		`"o":"1","c":"%d","h":"2","l":"0.5","v":"10","n":3,"x":%t,"q":"10","V":"5","Q":"5"}}`,
+		c.CloseTime, c.OpenTime, c.CloseTime, m, closed)
+}
+
+func TestBuffer_Update(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  exchange.Candlestick
+		applied bool
+		wantErr error
+		want    []float64
+	}{
+		{
+			name:    "Should replace the candle still forming",
+			update:  exchange.Candlestick{OpenTime: minute(2).UnixMilli(), Close: 20},
+			applied: true,
+			want:    []float64{0, 1, 20},
+		},
+		{
+			name:    "Should append a new candle dropping the oldest",
+			update:  candle(3),
+			applied: true,
+			want:    []float64{1, 2, 3},
+		},
+		{
+			name:   "Should ignore an older candle",
+			update: exchange.Candlestick{OpenTime: minute(1).UnixMilli(), Close: 10},
+			want:   []float64{0, 1, 2},
+		},
+		{
+			name:    "Should refuse a candle after missing candles",
+			update:  candle(5),
+			wantErr: ErrCandleGap,
+			want:    []float64{0, 1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := NewBuffer(3)
+			b.Seed([]exchange.Candlestick{candle(-1), candle(0), candle(1), candle(2)})
+			if got, err := b.Update(tt.update); got != tt.applied || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Update() = %v, %v; esperado %v, %v", got, err, tt.applied, tt.wantErr)
+			}
+
+			candles := b.Candles()
+			if len(candles) != len(tt.want) {
+				t.Fatalf("buffer com %d candles, esperado %d", len(candles), len(tt.want))
+			}
+			for i, c := range candles {
+				if c.Close != tt.want[i] {
+					t.Errorf("candle %d com fechamento %v, esperado %v", i, c.Close, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestParseKlineEvent(t *testing.T) {
+	c, closed, err := parseKlineEvent([]byte(klineMessage(5, true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !closed || c.OpenTime != minute(5).UnixMilli() || c.Close != 5 || c.High != 2 || c.NumberOfTrades != 3 {
+		t.Errorf("candle convertido incorretamente: %+v, fechado=%v", c, closed)
+	}
+
+	for _, msg := range []string{`{"e":"trade"}`, `{"e":"kline","k":{"o":"abc"}}`, `[]`} {
+		if _, _, err := parseKlineEvent([]byte(msg)); err == nil {
+			t.Errorf("evento %s aceito, esperado erro", msg)
+		}
+	}
+}
+
+func TestKlineStream(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ws/btcusdt@kline_1m" {
+			http.NotFound(w, r)
+			return
+		}
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		// A primeira conexão cai após fechar um candle; a segunda permanece aberta
+		n := connections.Add(1)
+		messages := []string{klineMessage(10, false), `{"e":"trade"}`, klineMessage(10, true)}
+		if n > 1 {
+			messages = []string{klineMessage(11, true)}
+		}
+		for _, msg := range messages {
+			if err := conn.Write(r.Context(), websocket.MessageText, []byte(msg)); err != nil {
+				return
+			}
+		}
+		if n > 1 {
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ex := &fakeKlines{}
+	s := NewKlineStream(strings.Replace(server.URL, "http", "ws", 1), ex, "BTCUSDT", "1m", 5)
+	s.MinBackoff = 10 * time.Millisecond
+	s.MaxBackoff = 10 * time.Millisecond
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	for _, want := range []int{10, 11} {
+		select {
+		case c := <-s.Closed():
+			if c.OpenTime != minute(want).UnixMilli() || c.Close != float64(want) {
+				t.Errorf("fechamento recebido do candle %+v, esperado o do minuto %d", c, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("fechamento do minuto %d não recebido", want)
+		}
+	}
+
+	if !s.Live() {
+		t.Error("stream conectado informado como desconectado")
+	}
+	candles := s.Candles()
+	if len(candles) != 5 || candles[4].OpenTime != minute(11).UnixMilli() || candles[0].OpenTime != minute(7).UnixMilli() {
+		t.Errorf("buffer com os candles %v, esperado do minuto 7 ao 11", candles)
+	}
+	// Cada conexão carrega o buffer com uma única requisição
+	if connections.Load() != 2 || ex.requests.Load() != 2 {
+		t.Errorf("%d conexões e %d requisições, esperado 2 e 2", connections.Load(), ex.requests.Load())
+	}
+
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("Run() = %v, esperado context.Canceled", err)
+	}
+	if _, ok := <-s.Closed(); ok {
+		t.Error("canal de fechamentos não foi fechado")
+	}
+}
+
+// fakeLatest retorna candles de 1 minuto fechados até minute(last), fechando
+// seeded na primeira requisição.
+type fakeLatest struct {
+	exchange.Exchange
+	last     atomic.Int32
+	requests atomic.Int32
+	seeded   chan struct{}
+}
+
+func (f *fakeLatest) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]exchange.Candlestick, error) {
+	if f.requests.Add(1) == 1 {
+		close(f.seeded)
+	}
+	last := int(f.last.Load())
+	candles := make([]exchange.Candlestick, limit)
+	for i := range candles {
+		candles[i] = candle(last - limit + 1 + i)
+	}
+	return candles, nil
+}
+
+func TestKlineStream_Gap(t *testing.T) {
+	ex := &fakeLatest{seeded: make(chan struct{})}
+	ex.last.Store(10)
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+		connections.Add(1)
+
+		// Os eventos dos minutos 11 e 12 se perdem
+		<-ex.seeded
+		if err := conn.Write(r.Context(), websocket.MessageText, []byte(klineMessage(10, true))); err != nil {
+			return
+		}
+		ex.last.Store(13)
+		if err := conn.Write(r.Context(), websocket.MessageText, []byte(klineMessage(13, true))); err != nil {
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	s := NewKlineStream(strings.Replace(server.URL, "http", "ws", 1), ex, "BTCUSDT", "1m", 5)
+	done := make(chan error)
+	go func() { done <- s.Run(ctx) }()
+
+	for _, want := range []int{10, 13} {
+		select {
+		case c := <-s.Closed():
+			if c.OpenTime != minute(want).UnixMilli() {
+				t.Errorf("fechamento recebido do candle %+v, esperado o do minuto %d", c, want)
+			}
+		case <-ctx.Done():
+			t.Fatalf("fechamento do minuto %d não recebido", want)
+		}
+	}
+
+	// Os candles ausentes são recarregados pela API REST sem refazer a conexão
+	candles := s.Candles()
+	for i, c := range candles {
+		if c.OpenTime != minute(9+i).UnixMilli() {
+			t.Errorf("candle %d aberto em %v, esperado o minuto %d", i, time.UnixMilli(c.OpenTime), 9+i)
+		}
+	}
+	if len(candles) != 5 || connections.Load() != 1 || ex.requests.Load() != 2 {
+		t.Errorf("%d candles, %d conexões e %d requisições, esperado 5, 1 e 2", len(candles), connections.Load(), ex.requests.Load())
+	}
+
+	cancel()
+	<-done
+}
@@ -0,0 +1,95 @@
+package risk
+
+// Reason identifica a regra que motivou uma ordem.
+type Reason string
+
+const (
+	// ReasonStrategy indica uma ordem gerada pela estratégia (ShouldEnter/ShouldExit).
+	ReasonStrategy Reason = "strategy"
+	// ReasonStopLoss indica uma saída porque o preço caiu até o stop-loss.
+	ReasonStopLoss Reason = "stop_loss"
+	// ReasonTakeProfit indica uma saída porque o preço atingiu o alvo de lucro.
+	ReasonTakeProfit Reason = "take_profit"
+	// ReasonTrailingStop indica uma saída porque o preço recuou a partir da máxima.
+	ReasonTrailingStop Reason = "trailing_stop"
+)
+
+// Rules contém os limites de saída gerenciados pelo bot, em porcentagem.
+// Um valor zero desativa a regra correspondente.
+type Rules struct {
+	// StopLossPercent é a queda máxima em relação ao preço de entrada.
+	StopLossPercent float64
+	// TakeProfitPercent é a alta em relação ao preço de entrada que encerra a posição.
+	TakeProfitPercent float64
+	// TrailingStopPercent é a queda máxima em relação ao maior preço desde a entrada.
+	TrailingStopPercent float64
+}
+
+// Position é o estado da posição aberta acompanhado pelas regras de risco.
+type Position struct {
+	// EntryPrice é o preço de entrada registrado.
+	EntryPrice float64
+	// HighestPrice é o maior preço observado desde a entrada.
+	HighestPrice float64
+}
+
+// NewPosition cria o acompanhamento de uma posição aberta ao preço informado.
+func NewPosition(entryPrice float64) *Position {
+	return &Position{EntryPrice: entryPrice, HighestPrice: entryPrice}
+}
+
+// Enabled indica se alguma regra de saída está ativa.
+func (r Rules) Enabled() bool {
+	return r.StopLossPercent > 0 || r.TakeProfitPercent > 0 || r.TrailingStopPercent > 0
+}
+
+// Check avalia as regras de saída para o preço atual
+// O maior preço da posição é atualizado antes da avaliação. As regras são
+// verificadas na ordem stop-loss, trailing stop e take-profit.
+//
+// Retorna:
+//   - Reason: regra que motivou a saída
+//   - bool: true se a posição deve ser encerrada
+func (r Rules) Check(pos *Position, price float64) (Reason, bool) {
+	if pos == nil || pos.EntryPrice <= 0 {
+		return "", false
+	}
+	if price > pos.HighestPrice {
+		pos.HighestPrice = price
+	}
+
+	if r.StopLossPercent > 0 && price <= r.StopLossPrice(pos) {
+		return ReasonStopLoss, true
+	}
+	if r.TrailingStopPercent > 0 && price <= r.TrailingStopPrice(pos) {
+		return ReasonTrailingStop, true
+	}
+	if r.TakeProfitPercent > 0 && price >= r.TakeProfitPrice(pos) {
+		return ReasonTakeProfit, true
+	}
+	return "", false
+}
+
+// StopLossPrice retorna o preço do stop-loss, ou zero se a regra estiver desativada.
+func (r Rules) StopLossPrice(pos *Position) float64 {
+	if r.StopLossPercent <= 0 {
+		return 0
+	}
+	return pos.EntryPrice * (1 - r.StopLossPercent/100)
+}
+
+// TakeProfitPrice retorna o preço do take-profit, ou zero se a regra estiver desativada.
+func (r Rules) TakeProfitPrice(pos *Position) float64 {
+	if r.TakeProfitPercent <= 0 {
+		return 0
+	}
+	return pos.EntryPrice * (1 + r.TakeProfitPercent/100)
+}
+
+// TrailingStopPrice retorna o preço do trailing stop, ou zero se a regra estiver desativada.
+func (r Rules) TrailingStopPrice(pos *Position) float64 {
+	if r.TrailingStopPercent <= 0 {
+		return 0
+	}
+	return pos.HighestPrice * (1 - r.TrailingStopPercent/100)
+}
@@ -0,0 +1,71 @@
+package risk
+
+import "testing"
+
+func TestRulesCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		rules      Rules
+		prices     []float64
+		wantReason Reason
+		wantExit   bool
+	}{
+		{
+			name:       "Should trigger stop loss",
+			rules:      Rules{StopLossPercent: 5},
+			prices:     []float64{98, 95},
+			wantReason: ReasonStopLoss,
+			wantExit:   true,
+		},
+		{
+			name:       "Should trigger take profit",
+			rules:      Rules{TakeProfitPercent: 10},
+			prices:     []float64{105, 111},
+			wantReason: ReasonTakeProfit,
+			wantExit:   true,
+		},
+		{
+			name:       "Should trigger trailing stop from highest price",
+			rules:      Rules{TrailingStopPercent: 5},
+			prices:     []float64{110, 120, 114},
+			wantReason: ReasonTrailingStop,
+			wantExit:   true,
+		},
+		{
+			name:       "Should prefer stop loss over trailing stop",
+			rules:      Rules{StopLossPercent: 5, TrailingStopPercent: 2},
+			prices:     []float64{94},
+			wantReason: ReasonStopLoss,
+			wantExit:   true,
+		},
+		{
+			name:     "Should hold inside the limits",
+			rules:    Rules{StopLossPercent: 5, TakeProfitPercent: 10, TrailingStopPercent: 5},
+			prices:   []float64{97, 104, 100},
+			wantExit: false,
+		},
+		{
+			name:     "Should never exit with rules disabled",
+			rules:    Rules{},
+			prices:   []float64{1, 1000},
+			wantExit: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pos := NewPosition(100)
+			var reason Reason
+			var exit bool
+			for _, p := range tt.prices {
+				reason, exit = tt.rules.Check(pos, p)
+				if exit {
+					break
+				}
+			}
+			if exit != tt.wantExit || reason != tt.wantReason {
+				t.Errorf("Check() = (%q, %v), esperado (%q, %v)", reason, exit, tt.wantReason, tt.wantExit)
+			}
+		})
+	}
+}
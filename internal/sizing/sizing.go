@@ -0,0 +1,118 @@
+package sizing
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// Métodos de dimensionamento de posição disponíveis.
+const (
+	// MethodFixedQuote compra um valor fixo no ativo de cotação (ex: 50 USDT).
+	MethodFixedQuote = "fixed_quote"
+	// MethodPercentBalance compra uma porcentagem do saldo livre do ativo de cotação.
+	MethodPercentBalance = "percent_balance"
+	// MethodFixedRisk arrisca uma porcentagem do saldo livre até o stop-loss.
+	MethodFixedRisk = "fixed_risk"
+)
+
+// hundred é o divisor das porcentagens.
+var hundred = decimal.NewFromInt(100)
+
+// Input contém os dados de mercado e da conta usados para dimensionar uma compra.
+type Input struct {
+	// Price é o preço atual do ativo.
+	Price decimal.Decimal
+	// FreeQuote é o saldo livre do ativo de cotação.
+	FreeQuote decimal.Decimal
+	// StopLossPercent é a distância do stop-loss ao preço de entrada, em porcentagem.
+	StopLossPercent float64
+}
+
+// Sizer calcula a quantidade do ativo base a ser comprada.
+// A quantidade retornada ainda deve ser ajustada às regras do par (lot size).
+type Sizer interface {
+	Size(in Input) (decimal.Decimal, error)
+}
+
+// New cria o Sizer do método informado
+// Parâmetros:
+//   - method: MethodFixedQuote, MethodPercentBalance ou MethodFixedRisk
+//   - value: valor em cotação para MethodFixedQuote, ou porcentagem para os demais
+//
+// Retorna erro se o método for desconhecido ou o valor inválido.
+func New(method string, value float64) (Sizer, error) {
+	if value <= 0 {
+		return nil, fmt.Errorf("valor de dimensionamento deve ser maior que zero: %v", value)
+	}
+
+	amount := decimal.NewFromFloat(value)
+	switch method {
+	case MethodFixedQuote:
+		return FixedQuote{Amount: amount}, nil
+	case MethodPercentBalance:
+		if value > 100 {
+			return nil, fmt.Errorf("porcentagem do saldo deve ser no máximo 100: %v", value)
+		}
+		return PercentBalance{Percent: amount}, nil
+	case MethodFixedRisk:
+		if value > 100 {
+			return nil, fmt.Errorf("risco por operação deve ser no máximo 100: %v", value)
+		}
+		return FixedRisk{RiskPercent: amount}, nil
+	default:
+		return nil, fmt.Errorf("método de dimensionamento desconhecido: %s", method)
+	}
+}
+
+// FixedQuote compra sempre o mesmo valor no ativo de cotação.
+type FixedQuote struct {
+	Amount decimal.Decimal
+}
+
+// Size retorna Amount / Price, limitado ao saldo livre.
+func (s FixedQuote) Size(in Input) (decimal.Decimal, error) {
+	if !in.Price.IsPositive() {
+		return decimal.Zero, errors.New("preço deve ser maior que zero")
+	}
+	amount := decimal.Min(s.Amount, in.FreeQuote)
+	return amount.Div(in.Price), nil
+}
+
+// PercentBalance compra uma porcentagem do saldo livre do ativo de cotação.
+type PercentBalance struct {
+	Percent decimal.Decimal
+}
+
+// Size retorna FreeQuote * Percent / Price.
+func (s PercentBalance) Size(in Input) (decimal.Decimal, error) {
+	if !in.Price.IsPositive() {
+		return decimal.Zero, errors.New("preço deve ser maior que zero")
+	}
+	return in.FreeQuote.Mul(s.Percent).Div(hundred).Div(in.Price), nil
+}
+
+// FixedRisk dimensiona a posição para que uma saída no stop-loss perca
+// RiskPercent do saldo livre (fixed fractional).
+type FixedRisk struct {
+	RiskPercent decimal.Decimal
+}
+
+// Size retorna (FreeQuote * RiskPercent) / (Price * StopLossPercent), limitado ao saldo livre.
+// Retorna erro se o stop-loss não estiver configurado.
+func (s FixedRisk) Size(in Input) (decimal.Decimal, error) {
+	if !in.Price.IsPositive() {
+		return decimal.Zero, errors.New("preço deve ser maior que zero")
+	}
+	if in.StopLossPercent <= 0 {
+		return decimal.Zero, errors.New("o método fixed_risk exige um stop-loss configurado")
+	}
+
+	riskAmount := in.FreeQuote.Mul(s.RiskPercent).Div(hundred)
+	stopDistance := in.Price.Mul(decimal.NewFromFloat(in.StopLossPercent)).Div(hundred)
+	quantity := riskAmount.Div(stopDistance)
+
+	maxQuantity := in.FreeQuote.Div(in.Price)
+	return decimal.Min(quantity, maxQuantity), nil
+}
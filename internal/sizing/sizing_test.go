@@ -0,0 +1,94 @@
+package sizing
+
+import (
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+// dec converte um literal de teste em decimal.Decimal.
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestSize(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  string
+		value   float64
+		input   Input
+		want    decimal.Decimal
+		wantErr bool
+	}{
+		{
+			name:   "Should buy a fixed quote amount",
+			method: MethodFixedQuote,
+			value:  50,
+			input:  Input{Price: dec("25000"), FreeQuote: dec("1000")},
+			want:   dec("0.002"),
+		},
+		{
+			name:   "Should cap fixed quote amount at free balance",
+			method: MethodFixedQuote,
+			value:  50,
+			input:  Input{Price: dec("10"), FreeQuote: dec("20")},
+			want:   dec("2"),
+		},
+		{
+			name:   "Should buy a percentage of free balance",
+			method: MethodPercentBalance,
+			value:  10,
+			input:  Input{Price: dec("100"), FreeQuote: dec("1000")},
+			want:   dec("1"),
+		},
+		{
+			name:   "Should risk a fraction of balance until the stop",
+			method: MethodFixedRisk,
+			value:  1,
+			input:  Input{Price: dec("100"), FreeQuote: dec("1000"), StopLossPercent: 5},
+			want:   dec("2"), // arrisca 10 USDT com stop a 5 USDT por unidade
+		},
+		{
+			name:   "Should cap fixed risk at free balance",
+			method: MethodFixedRisk,
+			value:  2,
+			input:  Input{Price: dec("100"), FreeQuote: dec("1000"), StopLossPercent: 1},
+			want:   dec("10"),
+		},
+		{
+			name:    "Should fail fixed risk without stop loss",
+			method:  MethodFixedRisk,
+			value:   1,
+			input:   Input{Price: dec("100"), FreeQuote: dec("1000")},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sizer, err := New(tt.method, tt.value)
+			if err != nil {
+				t.Fatalf("erro inesperado em New: %v", err)
+			}
+			got, err := sizer.Size(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Size() erro = %v, esperado erro = %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !got.Equal(tt.want) {
+				t.Errorf("Size() = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := New("unknown", 1); err == nil {
+		t.Error("esperado erro para método desconhecido")
+	}
+	if _, err := New(MethodFixedQuote, 0); err == nil {
+		t.Error("esperado erro para valor zero")
+	}
+	if _, err := New(MethodPercentBalance, 150); err == nil {
+		t.Error("esperado erro para porcentagem acima de 100")
+	}
+}
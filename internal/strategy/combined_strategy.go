@@ -1,7 +1,17 @@
 package strategy
 
-import "github.com/brunossouza/crypto_bot/internal/indicators"
+import (
+	"fmt"
 
+	"github.com/brunossouza/crypto_bot/internal/indicators"
+)
+
+func init() {
+	Register("combined", newCombinedFromParams)
+}
+
+// CombinedStrategy combina RSI e SMA: compra em sobrevenda dentro de uma tendência
+// de alta e vende em sobrecompra dentro de uma tendência de baixa.
 type CombinedStrategy struct {
 	RSIPeriod          int
 	SMAPeriod          int
@@ -20,9 +30,53 @@ func NewCombinedStrategy(rsiPeriod, smaPeriod int, overbought, oversold, trendSt
 	}
 }
 
-func (s *CombinedStrategy) ShouldEnter(prices []float64) bool {
-	rsi := indicators.CalculateRSI(prices, s.RSIPeriod)
-	sma := indicators.CalculateSMA(prices, s.SMAPeriod)
+// newCombinedFromParams cria a estratégia a partir dos parâmetros rsi_period, sma_period,
+// overbought, oversold e trend_strength. Os padrões são 14, 20, 70, 30 e 1.0.
+// Retorna erro se um período não for positivo ou se oversold não for menor que overbought.
+func newCombinedFromParams(params Params) (Strategy, error) {
+	if err := params.checkKeys("rsi_period", "sma_period", "overbought", "oversold", "trend_strength"); err != nil {
+		return nil, err
+	}
+	rsiPeriod, err := params.period("rsi_period", 14)
+	if err != nil {
+		return nil, err
+	}
+	smaPeriod, err := params.period("sma_period", 20)
+	if err != nil {
+		return nil, err
+	}
+	overbought, err := params.Float("overbought", 70)
+	if err != nil {
+		return nil, err
+	}
+	oversold, err := params.Float("oversold", 30)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLevels(oversold, overbought); err != nil {
+		return nil, err
+	}
+	trendStrength, err := params.Float("trend_strength", 1.0)
+	if err != nil {
+		return nil, err
+	}
+	return NewCombinedStrategy(rsiPeriod, smaPeriod, overbought, oversold, trendStrength), nil
+}
+
+func (s *CombinedStrategy) Name() string {
+	return "combined"
+}
+
+// MinBars retorna o maior entre RSIPeriod+1 (o RSI usa as variações entre preços) e SMAPeriod.
+func (s *CombinedStrategy) MinBars() int {
+	return max(s.RSIPeriod+1, s.SMAPeriod)
+}
+
+func (s *CombinedStrategy) ShouldEnter(prices []float64) (bool, error) {
+	rsi, sma, err := s.GetIndicators(prices)
+	if err != nil {
+		return false, err
+	}
 	currentPrice := prices[len(prices)-1]
 
 	// Tendência de alta (preço acima da média móvel) + RSI indicando sobrevenda
@@ -30,12 +84,14 @@ func (s *CombinedStrategy) ShouldEnter(prices []float64) bool {
 	isOversold := rsi < s.OversoldLevel
 	trendStrength := (currentPrice - sma) / sma * 100
 
-	return isTrendUp && isOversold && trendStrength > s.TrendStrengthLevel
+	return isTrendUp && isOversold && trendStrength > s.TrendStrengthLevel, nil
 }
 
-func (s *CombinedStrategy) ShouldExit(prices []float64) bool {
-	rsi := indicators.CalculateRSI(prices, s.RSIPeriod)
-	sma := indicators.CalculateSMA(prices, s.SMAPeriod)
+func (s *CombinedStrategy) ShouldExit(prices []float64) (bool, error) {
+	rsi, sma, err := s.GetIndicators(prices)
+	if err != nil {
+		return false, err
+	}
 	currentPrice := prices[len(prices)-1]
 
 	// Tendência de baixa (preço abaixo da média móvel) + RSI indicando sobrecompra
@@ -43,10 +99,34 @@ func (s *CombinedStrategy) ShouldExit(prices []float64) bool {
 	isOverbought := rsi > s.OverboughtLevel
 	trendStrength := (sma - currentPrice) / sma * 100
 
-	return isTrendDown && isOverbought && trendStrength > s.TrendStrengthLevel
+	return isTrendDown && isOverbought && trendStrength > s.TrendStrengthLevel, nil
+}
+
+// GetIndicators calcula o RSI e a SMA dos preços informados.
+// Retorna erro se não houver preços suficientes para algum dos indicadores.
+func (s *CombinedStrategy) GetIndicators(prices []float64) (rsi, sma float64, err error) {
+	rsi, err = indicators.CalculateRSI(prices, s.RSIPeriod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("RSI: %w", err)
+	}
+	sma, err = indicators.CalculateSMA(prices, s.SMAPeriod)
+	if err != nil {
+		return 0, 0, fmt.Errorf("SMA: %w", err)
+	}
+	return rsi, sma, nil
 }
 
-func (s *CombinedStrategy) GetIndicators(prices []float64) (rsi, sma float64) {
-	return indicators.CalculateRSI(prices, s.RSIPeriod),
-		indicators.CalculateSMA(prices, s.SMAPeriod)
+// Indicators retorna RSI, SMA e a força da tendência (distância percentual do preço à SMA).
+func (s *CombinedStrategy) Indicators(prices []float64) ([]Indicator, error) {
+	rsi, sma, err := s.GetIndicators(prices)
+	if err != nil {
+		return nil, err
+	}
+	currentPrice := prices[len(prices)-1]
+
+	return []Indicator{
+		{Key: IndicatorRSI, Name: "RSI", Value: rsi},
+		{Key: IndicatorSMA, Name: "SMA", Value: sma},
+		{Key: IndicatorTrendStrength, Name: "Tendência (%)", Value: (currentPrice - sma) / sma * 100},
+	}, nil
 }
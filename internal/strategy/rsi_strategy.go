@@ -0,0 +1,86 @@
+package strategy
+
+import (
+	"fmt"
+
+	"github.com/brunossouza/crypto_bot/internal/indicators"
+)
+
+func init() {
+	Register("rsi", newRSIFromParams)
+}
+
+// RSIStrategy utiliza apenas o RSI: compra em sobrevenda e vende em sobrecompra.
+type RSIStrategy struct {
+	Period          int
+	OverboughtLevel float64
+	OversoldLevel   float64
+}
+
+func NewRSIStrategy(period int, overbought, oversold float64) *RSIStrategy {
+	return &RSIStrategy{
+		Period:          period,
+		OverboughtLevel: overbought,
+		OversoldLevel:   oversold,
+	}
+}
+
+// newRSIFromParams cria a estratégia a partir dos parâmetros period, overbought
+// e oversold. Os padrões são 14, 70 e 30.
+// Retorna erro se o período não for positivo ou se oversold não for menor que overbought.
+func newRSIFromParams(params Params) (Strategy, error) {
+	if err := params.checkKeys("period", "overbought", "oversold"); err != nil {
+		return nil, err
+	}
+	period, err := params.period("period", 14)
+	if err != nil {
+		return nil, err
+	}
+	overbought, err := params.Float("overbought", 70)
+	if err != nil {
+		return nil, err
+	}
+	oversold, err := params.Float("oversold", 30)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkLevels(oversold, overbought); err != nil {
+		return nil, err
+	}
+	return NewRSIStrategy(period, overbought, oversold), nil
+}
+
+func (s *RSIStrategy) Name() string {
+	return "rsi"
+}
+
+// MinBars retorna Period+1, pois o RSI usa as variações entre preços consecutivos.
+func (s *RSIStrategy) MinBars() int {
+	return s.Period + 1
+}
+
+func (s *RSIStrategy) ShouldEnter(prices []float64) (bool, error) {
+	rsi, err := indicators.CalculateRSI(prices, s.Period)
+	if err != nil {
+		return false, fmt.Errorf("RSI: %w", err)
+	}
+	return rsi < s.OversoldLevel, nil
+}
+
+func (s *RSIStrategy) ShouldExit(prices []float64) (bool, error) {
+	rsi, err := indicators.CalculateRSI(prices, s.Period)
+	if err != nil {
+		return false, fmt.Errorf("RSI: %w", err)
+	}
+	return rsi > s.OverboughtLevel, nil
+}
+
+func (s *RSIStrategy) Indicators(prices []float64) ([]Indicator, error) {
+	rsi, err := indicators.CalculateRSI(prices, s.Period)
+	if err != nil {
+		return nil, fmt.Errorf("RSI: %w", err)
+	}
+	return []Indicator{
+		{Key: IndicatorRSI, Name: "RSI", Value: rsi},
+	}, nil
+}
@@ -0,0 +1,184 @@
+package strategy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Strategy define as decisões que o loop de trading precisa de uma estratégia.
+// Os preços são sempre ordenados do mais antigo para o mais recente.
+// Os métodos retornam erro quando os indicadores não podem ser calculados
+// (ex: menos preços que MinBars), em vez de interromper o programa.
+type Strategy interface {
+	// Name retorna o nome com que a estratégia foi registrada.
+	Name() string
+	// ShouldEnter indica se uma posição deve ser aberta.
+	ShouldEnter(prices []float64) (bool, error)
+	// ShouldExit indica se a posição aberta deve ser encerrada.
+	ShouldExit(prices []float64) (bool, error)
+	// Indicators retorna os valores atuais dos indicadores, na ordem de exibição.
+	Indicators(prices []float64) ([]Indicator, error)
+	// MinBars retorna a quantidade mínima de preços necessária para as decisões.
+	MinBars() int
+}
+
+// Chaves dos indicadores comuns às estratégias, registradas no diário de decisões.
+const (
+	// IndicatorRSI identifica o Índice de Força Relativa.
+	IndicatorRSI = "rsi"
+	// IndicatorSMA identifica a média móvel simples.
+	IndicatorSMA = "sma"
+	// IndicatorTrendStrength identifica a distância do preço à média, em porcentagem.
+	IndicatorTrendStrength = "trend_strength"
+)
+
+// Indicator é o valor de um indicador calculado pela estratégia.
+type Indicator struct {
+	// Key identifica o indicador de forma estável (ex: IndicatorRSI).
+	Key string
+	// Name é o nome exibido no relatório.
+	Name string
+	// Value é o valor atual do indicador.
+	Value float64
+}
+
+// Factory cria uma estratégia a partir dos parâmetros informados.
+type Factory func(params Params) (Strategy, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Factory)
+)
+
+// Register registra uma estratégia com o nome informado.
+// Deve ser chamada no init do arquivo que implementa a estratégia.
+// Gera panic se o nome já estiver registrado.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic("estratégia registrada duas vezes: " + name)
+	}
+	registry[name] = factory
+}
+
+// New cria uma nova instância da estratégia registrada com o nome informado.
+// Retorna erro se a estratégia não existir ou se os parâmetros forem inválidos.
+func New(name string, params Params) (Strategy, error) {
+	registryMu.RLock()
+	factory, ok := registry[name]
+	registryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("estratégia desconhecida: %s (disponíveis: %s)", name, strings.Join(Names(), ", "))
+	}
+	s, err := factory(params)
+	if err != nil {
+		return nil, fmt.Errorf("parâmetros inválidos para a estratégia %s: %w", name, err)
+	}
+	return s, nil
+}
+
+// Names retorna os nomes das estratégias registradas em ordem alfabética.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Params contém os parâmetros de uma estratégia no formato chave=valor.
+type Params map[string]string
+
+// ParseParams converte uma lista no formato "chave=valor,chave=valor" em Params.
+// Espaços ao redor de chaves e valores são ignorados. Uma string vazia resulta em Params vazio.
+func ParseParams(str string) (Params, error) {
+	params := Params{}
+	for _, pair := range strings.Split(str, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("parâmetro inválido: %q", pair)
+		}
+		params[key] = strings.TrimSpace(value)
+	}
+	return params, nil
+}
+
+// Int retorna o parâmetro key como inteiro, ou def se ele não estiver definido.
+func (p Params) Int(key string, def int) (int, error) {
+	str, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return v, nil
+}
+
+// Float retorna o parâmetro key como float64, ou def se ele não estiver definido.
+func (p Params) Float(key string, def float64) (float64, error) {
+	str, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(str, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", key, err)
+	}
+	return v, nil
+}
+
+// checkKeys retorna erro se algum parâmetro não estiver entre as chaves conhecidas,
+// evitando que um erro de digitação seja ignorado silenciosamente.
+func (p Params) checkKeys(known ...string) error {
+	for key := range p {
+		found := false
+		for _, k := range known {
+			if key == k {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("parâmetro desconhecido: %s (aceitos: %s)", key, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
+// period retorna o parâmetro key como período de indicador, ou def se ele não estiver
+// definido. Retorna erro se o período não for positivo.
+func (p Params) period(key string, def int) (int, error) {
+	v, err := p.Int(key, def)
+	if err != nil {
+		return 0, err
+	}
+	if v <= 0 {
+		return 0, fmt.Errorf("%s: o período deve ser positivo, obteve %d", key, v)
+	}
+	return v, nil
+}
+
+// checkLevels retorna erro se o nível de sobrevenda não for menor que o de sobrecompra.
+func checkLevels(oversold, overbought float64) error {
+	if oversold >= overbought {
+		return fmt.Errorf("oversold (%g) deve ser menor que overbought (%g)", oversold, overbought)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package strategy
+
+import "testing"
+
+func TestParseParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Params
+		wantErr bool
+	}{
+		{
+			name:  "Should parse key value pairs",
+			input: "rsi_period=14, sma_period = 20",
+			want:  Params{"rsi_period": "14", "sma_period": "20"},
+		},
+		{
+			name:  "Should return empty params for empty string",
+			input: "",
+			want:  Params{},
+		},
+		{
+			name:    "Should fail without separator",
+			input:   "rsi_period",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseParams(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseParams() erro = %v, esperado erro = %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseParams() = %v, esperado %v", got, tt.want)
+			}
+			for k, v := range tt.want {
+				if got[k] != v {
+					t.Errorf("ParseParams()[%s] = %q, esperado %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	s, err := New("combined", Params{"rsi_period": "7", "oversold": "25"})
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	combined, ok := s.(*CombinedStrategy)
+	if !ok {
+		t.Fatalf("esperado *CombinedStrategy, obteve %T", s)
+	}
+	if combined.RSIPeriod != 7 || combined.OversoldLevel != 25 || combined.SMAPeriod != 20 {
+		t.Errorf("parâmetros aplicados incorretamente: %+v", combined)
+	}
+
+	if _, err := New("unknown", nil); err == nil {
+		t.Error("esperado erro para estratégia desconhecida")
+	}
+	if _, err := New("combined", Params{"rsi_perod": "7"}); err == nil {
+		t.Error("esperado erro para parâmetro desconhecido")
+	}
+	if _, err := New("rsi", Params{"period": "abc"}); err == nil {
+		t.Error("esperado erro para parâmetro inválido")
+	}
+}
+
+func TestNew_InvalidParams(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		params   Params
+	}{
+		{"Should reject a zero RSI period", "rsi", Params{"period": "0"}},
+		{"Should reject a negative RSI period", "rsi", Params{"period": "-14"}},
+		{"Should reject oversold above overbought", "rsi", Params{"oversold": "80"}},
+		{"Should reject equal oversold and overbought", "rsi", Params{"oversold": "50", "overbought": "50"}},
+		{"Should reject a zero combined RSI period", "combined", Params{"rsi_period": "0"}},
+		{"Should reject a negative SMA period", "combined", Params{"sma_period": "-20"}},
+		{"Should reject combined oversold above overbought", "combined", Params{"oversold": "75", "overbought": "25"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if s, err := New(tt.strategy, tt.params); err == nil {
+				t.Errorf("New(%q, %v) = %+v, esperado erro", tt.strategy, tt.params, s)
+			}
+		})
+	}
+}
+
+func TestStrategies_NotEnoughPrices(t *testing.T) {
+	for _, name := range Names() {
+		t.Run(name, func(t *testing.T) {
+			s, err := New(name, nil)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			prices := []float64{1, 2, 3}
+			if _, err := s.ShouldEnter(prices); err == nil {
+				t.Error("ShouldEnter: esperado erro com preços insuficientes")
+			}
+			if _, err := s.ShouldExit(prices); err == nil {
+				t.Error("ShouldExit: esperado erro com preços insuficientes")
+			}
+			if _, err := s.Indicators(prices); err == nil {
+				t.Error("Indicators: esperado erro com preços insuficientes")
+			}
+		})
+	}
+}
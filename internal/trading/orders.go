@@ -0,0 +1,117 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/risk"
+)
+
+// clientOrderID gera o identificador do cliente (newClientOrderId) da ordem pretendida
+// no lado side do par na avaliação do candle aberto em candleTime (milissegundos).
+// O identificador é determinístico: os reenvios da mesma ordem usam o mesmo valor,
+// permitindo consultá-la na corretora quando o resultado do envio é desconhecido.
+// O formato respeita o limite de 36 caracteres da Binance para pares de até 20 caracteres.
+func clientOrderID(symbol, side string, candleTime int64) string {
+	return fmt.Sprintf("cb_%s_%s_%d", symbol, strings.ToLower(side[:1]), candleTime/1000)
+}
+
+// savePending registra a ordem com o estado database.OrderStatusPending antes do
+// envio e retorna o registro gravado. Um registro anterior com o mesmo identificador
+// do cliente que não foi aceito pela corretora é reaproveitado com a nova quantidade;
+// se a ordem já foi aceita, retorna erro para que ela não seja enviada novamente.
+func savePending(ctx context.Context, order database.Order) (*database.Order, error) {
+	record, err := store.GetOrderByClientID(ctx, order.Symbol, cfg.Mode, order.ClientOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar ordem %s: %w", order.ClientOrderID, err)
+	}
+	if record != nil {
+		if record.Status != database.OrderStatusRejected {
+			return nil, fmt.Errorf("ordem %s já enviada com status %s", order.ClientOrderID, record.Status)
+		}
+		record.Quantity = order.Quantity
+		record.Price = order.Price
+		record.Status = database.OrderStatusPending
+		if err := store.UpdateOrder(ctx, *record); err != nil {
+			return nil, fmt.Errorf("erro ao salvar ordem: %w", err)
+		}
+		return record, nil
+	}
+
+	order.Status = database.OrderStatusPending
+	if err := store.SaveOrder(ctx, order); err != nil {
+		return nil, fmt.Errorf("erro ao salvar ordem: %w", err)
+	}
+	record, err = store.GetOrderByClientID(ctx, order.Symbol, cfg.Mode, order.ClientOrderID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao consultar ordem %s: %w", order.ClientOrderID, err)
+	}
+	if record == nil {
+		return nil, fmt.Errorf("ordem %s não encontrada após o registro", order.ClientOrderID)
+	}
+	return record, nil
+}
+
+// markNotSent registra que a ordem não foi aceita pela corretora e retorna cause,
+// o motivo da falha no envio.
+func markNotSent(ctx context.Context, record *database.Order, cause error) error {
+	record.Status = database.OrderStatusRejected
+	if err := store.UpdateOrder(ctx, *record); err != nil {
+		return fmt.Errorf("%w (registro da ordem %s não atualizado: %v)", cause, record.ClientOrderID, err)
+	}
+	return cause
+}
+
+// resolveOrder consulta na corretora, pelo identificador do cliente, a ordem registrada
+// cujo envio falhou com cause sem uma rejeição definitiva.
+// Retorna a ordem se ela foi aceita; ErrNotSent (com cause) se ela não existe na
+// corretora e pode ser reenviada; ou ErrUncertainOrder se a consulta falhar, mantendo
+// o registro com o estado database.OrderStatusPending.
+func resolveOrder(ctx context.Context, record *database.Order, cause error) (*exchange.Order, error) {
+	order, err := client.GetOrderByClientID(ctx, record.Symbol, record.ClientOrderID)
+	if errors.Is(err, exchange.ErrUnknownOrder) {
+		return nil, markNotSent(ctx, record, fmt.Errorf("%w: %w", ErrNotSent, cause))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: ordem %s (%v); consulta falhou: %v", ErrUncertainOrder, record.ClientOrderID, cause, err)
+	}
+	log.Printf("Ordem %s de %s confirmada na corretora com status %s após falha no envio: %v",
+		record.ClientOrderID, record.Symbol, order.Status, cause)
+	return order, nil
+}
+
+// resolvePending resolve as ordens do par que ficaram com o estado
+// database.OrderStatusPending (resultado desconhecido em um envio anterior),
+// registrando o resultado consultado na corretora.
+// Retorna ErrUncertainOrder se alguma ordem continuar sem resultado, e erro também
+// se uma ordem pendente tiver sido executada: a posição mudou, e a nova ordem deve
+// ser decidida novamente na próxima avaliação.
+func resolvePending(ctx context.Context, symbol string) error {
+	pending, err := store.GetPendingOrders(ctx, symbol, cfg.Mode)
+	if err != nil {
+		return err
+	}
+	for i := range pending {
+		record := &pending[i]
+		order, err := resolveOrder(ctx, record, errors.New("envio anterior sem resposta"))
+		if errors.Is(err, ErrNotSent) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := recordOrder(ctx, record, order, record.Price); errors.Is(err, ErrNotFilled) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		return fmt.Errorf("ordem pendente %s executada (%s %s); nova ordem não enviada",
+			record.ClientOrderID, risk.Reason(record.Reason), order.ExecutedQty)
+	}
+	return nil
+}
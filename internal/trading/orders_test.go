@@ -0,0 +1,175 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/risk"
+)
+
+func TestClientOrderID(t *testing.T) {
+	tests := []struct {
+		name       string
+		symbol     string
+		side       string
+		candleTime int64
+		want       string
+	}{
+		{"Should identify a buy by the candle", "BTCUSDT", exchange.SideBuy, 1700000040000, "cb_BTCUSDT_b_1700000040"},
+		{"Should identify a sell by the candle", "BTCUSDT", exchange.SideSell, 1700000040000, "cb_BTCUSDT_s_1700000040"},
+		{"Should fit the Binance limit", "ABCDEFGHIJKLMNOPQRST", exchange.SideBuy, 1700000040000, "cb_ABCDEFGHIJKLMNOPQRST_b_1700000040"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := clientOrderID(tt.symbol, tt.side, tt.candleTime)
+			if got != tt.want || len(got) > 36 {
+				t.Errorf("clientOrderID() = %q, esperado %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewOrder_LostResponse(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"Should look up the order after a timeout", errTimeout},
+		{"Should look up the order after a request timeout", &exchange.APIError{StatusCode: 408, Msg: "Request Timeout"}},
+		{"Should look up the order after a response without a Binance error code", &exchange.APIError{StatusCode: 413, Msg: "<html>Request Entity Too Large</html>"}},
+		{"Should look up the order after an unknown send status", &exchange.APIError{StatusCode: 400, Code: -1007, Msg: "Timeout waiting for response from backend server."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &fakeBroker{lost: 1, lostErr: tt.err}
+			setupTrader(t, broker)
+			ctx := context.Background()
+
+			// A compra chegou à corretora, mas a resposta se perdeu
+			order, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1")
+			if err != nil || order == nil || order.OrderID != 1 {
+				t.Fatalf("NewOrder() = %+v, %v; esperada a ordem confirmada pela consulta", order, err)
+			}
+			if len(broker.orders) != 1 {
+				t.Errorf("%d ordens enviadas, esperado 1", len(broker.orders))
+			}
+
+			record, err := store.GetOrderByClientID(ctx, "BTCUSDT", config.ModePaper, "cb_BTCUSDT_b_1")
+			if err != nil || record == nil || record.Status != "FILLED" || record.ExchangeOrderID != 1 || !record.ExecutedQty.Equal(dec("0.001")) {
+				t.Errorf("ordem registrada incorretamente: %+v, erro: %v", record, err)
+			}
+			if opened, _ := store.GetPosition(ctx, "BTCUSDT", config.ModePaper); !opened {
+				t.Error("posição não aberta pela ordem confirmada")
+			}
+
+			// O mesmo identificador não é enviado novamente
+			if _, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1"); err == nil || len(broker.orders) != 1 {
+				t.Errorf("ordem já executada reenviada: erro %v, %d ordens enviadas", err, len(broker.orders))
+			}
+		})
+	}
+}
+
+func TestNewOrder_UncertainOrder(t *testing.T) {
+	broker := &fakeBroker{lost: 1, lookupErr: errTimeout}
+	setupTrader(t, broker)
+	ctx := context.Background()
+
+	_, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1")
+	if !errors.Is(err, ErrUncertainOrder) {
+		t.Fatalf("NewOrder() erro = %v, esperado %v", err, ErrUncertainOrder)
+	}
+	record, err := store.GetOrderByClientID(ctx, "BTCUSDT", config.ModePaper, "cb_BTCUSDT_b_1")
+	if err != nil || record == nil || record.Status != database.OrderStatusPending {
+		t.Fatalf("ordem sem resultado registrada incorretamente: %+v, erro: %v", record, err)
+	}
+
+	// Enquanto o resultado for desconhecido, nenhuma ordem do par é enviada
+	if _, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_2"); !errors.Is(err, ErrUncertainOrder) {
+		t.Errorf("NewOrder() erro = %v, esperado %v", err, ErrUncertainOrder)
+	}
+	if len(broker.orders) != 1 {
+		t.Fatalf("%d ordens enviadas, esperado 1", len(broker.orders))
+	}
+
+	// Com a consulta disponível, a ordem pendente é registrada e a nova ordem não é enviada
+	broker.lookupErr = nil
+	if _, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_2"); err == nil {
+		t.Error("nova ordem aceita após a execução da ordem pendente")
+	}
+	if len(broker.orders) != 1 {
+		t.Errorf("%d ordens enviadas, esperado 1", len(broker.orders))
+	}
+	record, err = store.GetOrderByClientID(ctx, "BTCUSDT", config.ModePaper, "cb_BTCUSDT_b_1")
+	if err != nil || record == nil || record.Status != "FILLED" || record.ExchangeOrderID != 1 {
+		t.Errorf("ordem pendente registrada incorretamente: %+v, erro: %v", record, err)
+	}
+	if opened, _ := store.GetPosition(ctx, "BTCUSDT", config.ModePaper); !opened {
+		t.Error("posição não aberta pela ordem pendente executada")
+	}
+}
+
+func TestNewOrder_RejectedOrder(t *testing.T) {
+	broker := &fakeBroker{rejects: []error{&exchange.APIError{StatusCode: 400, Code: -2010, Msg: "Account has insufficient balance for requested action."}}}
+	setupTrader(t, broker)
+	ctx := context.Background()
+
+	_, err := NewOrder(ctx, "BTCUSDT", dec("0.001"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1")
+	if !errors.Is(err, exchange.ErrInsufficientBalance) {
+		t.Fatalf("NewOrder() erro = %v, esperado %v", err, exchange.ErrInsufficientBalance)
+	}
+	record, err := store.GetOrderByClientID(ctx, "BTCUSDT", config.ModePaper, "cb_BTCUSDT_b_1")
+	if err != nil || record == nil || record.Status != database.OrderStatusRejected {
+		t.Fatalf("ordem rejeitada registrada incorretamente: %+v, erro: %v", record, err)
+	}
+	if last, _ := store.GetLastOrder(ctx, "BTCUSDT", exchange.SideBuy, config.ModePaper); last != nil {
+		t.Errorf("ordem rejeitada retornada como a última ordem: %+v", last)
+	}
+
+	// O reenvio com o mesmo identificador reaproveita o registro
+	if _, err := NewOrder(ctx, "BTCUSDT", dec("0.0009"), exchange.SideBuy, dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1"); err != nil {
+		t.Fatal(err)
+	}
+	last, err := store.GetLastOrder(ctx, "BTCUSDT", exchange.SideBuy, config.ModePaper)
+	if err != nil || last == nil || last.ID != record.ID || last.Status != "FILLED" || !last.Quantity.Equal(dec("0.0009")) {
+		t.Errorf("reenvio registrado incorretamente: %+v, erro: %v", last, err)
+	}
+}
+
+func TestHandleUserEvents_PendingOrder(t *testing.T) {
+	setupUserEvents(t)
+	ctx := context.Background()
+
+	// Compra do bot registrada antes do envio, sem resposta da corretora
+	if err := store.SaveOrder(ctx, database.Order{
+		Symbol: "BTCUSDT", Side: exchange.SideBuy, Quantity: dec("0.002"), Price: dec("62000"), Mode: config.ModeLive,
+		Reason: "strategy", ClientOrderID: "web_1", Status: database.OrderStatusPending,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	handle(
+		report(5, exchange.SideBuy, exchange.ExecutionNew, "NEW", "0", "0", "0", "0", "0"),
+		report(5, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "124", "0.002", "62000", "0.124"),
+	)
+
+	order, err := store.GetOrderByClientID(ctx, "BTCUSDT", config.ModeLive, "web_1")
+	if err != nil || order == nil {
+		t.Fatalf("ordem pendente não encontrada: %+v, erro: %v", order, err)
+	}
+	if order.Reason != "strategy" || order.ExchangeOrderID != 5 || order.Status != "FILLED" || !order.ExecutedQty.Equal(dec("0.002")) {
+		t.Errorf("ordem pendente atualizada incorretamente: %+v", order)
+	}
+	if external, _ := store.GetLastOrder(ctx, "BTCUSDT", exchange.SideBuy, config.ModeLive); external == nil || external.ID != order.ID {
+		t.Errorf("ordem pendente registrada novamente como externa: %+v", external)
+	}
+	if pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive); err != nil || pos == nil || !pos.Quantity.Equal(dec("0.002")) {
+		t.Errorf("posição não aberta pela ordem pendente: %+v, erro: %v", pos, err)
+	}
+}
@@ -0,0 +1,105 @@
+package trading
+
+import (
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+// hundred é o multiplicador das porcentagens.
+var hundred = decimal.NewFromInt(100)
+
+// feeInQuote converte a taxa de uma execução para o ativo de cotação do par
+// Taxas no ativo base são convertidas pelo preço da execução. Taxas cobradas em
+// outros ativos (ex: desconto em BNB) não podem ser convertidas sem uma cotação
+// e são ignoradas.
+func feeInQuote(commission decimal.Decimal, asset string, price decimal.Decimal, info *exchange.SymbolInfo) decimal.Decimal {
+	switch asset {
+	case info.QuoteAsset:
+		return commission
+	case info.BaseAsset:
+		return commission.Mul(price)
+	default:
+		return decimal.Zero
+	}
+}
+
+// orderFees retorna as taxas de todas as execuções da ordem no ativo de cotação.
+func orderFees(order *exchange.Order, info *exchange.SymbolInfo) decimal.Decimal {
+	fees := decimal.Zero
+	for _, f := range order.Fills {
+		fees = fees.Add(feeInQuote(f.Commission, f.CommissionAsset, f.Price, info))
+	}
+	return fees
+}
+
+// netQuantity retorna a quantidade executada descontada das taxas cobradas no ativo base,
+// ou seja, a quantidade efetivamente recebida em uma compra.
+func netQuantity(order *exchange.Order, info *exchange.SymbolInfo) decimal.Decimal {
+	quantity := order.ExecutedQty
+	for _, f := range order.Fills {
+		if f.CommissionAsset == info.BaseAsset {
+			quantity = quantity.Sub(f.Commission)
+		}
+	}
+	return quantity
+}
+
+// positionFromBuy monta a posição aberta por uma compra executada.
+// ref é o preço usado quando a corretora não informa as execuções.
+func positionFromBuy(symbol string, order *exchange.Order, info *exchange.SymbolInfo, ref decimal.Decimal) database.Position {
+	return database.Position{
+		Symbol:     symbol,
+		Mode:       cfg.Mode,
+		IsOpened:   true,
+		EntryPrice: fillPrice(order, ref),
+		Quantity:   netQuantity(order, info),
+		Fees:       orderFees(order, info),
+	}
+}
+
+// closeTrade calcula o resultado realizado da venda de quantity a exitPrice, com
+// taxas de saída exitFees, sobre a posição aberta pos
+// O custo de entrada é o preço de entrada vezes a quantidade vendida somado à
+// parcela proporcional das taxas da entrada.
+//
+// Retorna nil se a posição não tiver preço de entrada ou quantidade registrados
+// (ex: posições abertas antes do registro do preço de entrada).
+// O horário de encerramento é definido pelo banco ao registrar a operação.
+func closeTrade(pos *database.Position, exitPrice, quantity, exitFees decimal.Decimal, reason string) *database.ClosedTrade {
+	if pos == nil || !pos.EntryPrice.IsPositive() || !pos.Quantity.IsPositive() || !quantity.IsPositive() {
+		return nil
+	}
+
+	entryFees := pos.Fees
+	if quantity.LessThan(pos.Quantity) {
+		entryFees = pos.Fees.Mul(quantity).Div(pos.Quantity)
+	}
+	cost := pos.EntryPrice.Mul(quantity).Add(entryFees)
+	pnl := exitPrice.Mul(quantity).Sub(exitFees).Sub(cost)
+
+	return &database.ClosedTrade{
+		Symbol:     pos.Symbol,
+		Mode:       pos.Mode,
+		EntryPrice: pos.EntryPrice,
+		ExitPrice:  exitPrice,
+		Quantity:   quantity,
+		Fees:       entryFees.Add(exitFees),
+		PnL:        pnl,
+		PnLPercent: pnl.Mul(hundred).Div(cost),
+		Reason:     reason,
+		OpenedAt:   pos.OpenedAt,
+	}
+}
+
+// unrealizedPnL retorna o resultado da posição aberta avaliada a price, já
+// descontadas as taxas da entrada, no ativo de cotação e em porcentagem do custo.
+// As taxas da futura venda não são consideradas.
+func unrealizedPnL(pos *database.Position, price decimal.Decimal) (pnl, percent decimal.Decimal) {
+	cost := pos.EntryPrice.Mul(pos.Quantity).Add(pos.Fees)
+	if !cost.IsPositive() {
+		return decimal.Zero, decimal.Zero
+	}
+	pnl = price.Mul(pos.Quantity).Sub(cost)
+	return pnl, pnl.Mul(hundred).Div(cost)
+}
@@ -0,0 +1,77 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+// dec converte um literal de teste em decimal.Decimal.
+func dec(s string) decimal.Decimal {
+	return decimal.RequireFromString(s)
+}
+
+func TestOrderFeesAndNetQuantity(t *testing.T) {
+	info := &exchange.SymbolInfo{BaseAsset: "BTC", QuoteAsset: "USDT"}
+	order := &exchange.Order{
+		ExecutedQty: dec("1"),
+		Fills: []exchange.Fill{
+			{Price: dec("100"), Qty: dec("0.5"), Commission: dec("0.001"), CommissionAsset: "BTC"},
+			{Price: dec("102"), Qty: dec("0.5"), Commission: dec("0.05"), CommissionAsset: "USDT"},
+			{Price: dec("102"), Qty: dec("0"), Commission: dec("0.01"), CommissionAsset: "BNB"},
+		},
+	}
+
+	// 0.001 BTC a 100 + 0.05 USDT; a taxa em BNB não é convertida
+	if got := orderFees(order, info); !got.Equal(dec("0.15")) {
+		t.Errorf("orderFees() = %v, esperado 0.15", got)
+	}
+	if got := netQuantity(order, info); !got.Equal(dec("0.999")) {
+		t.Errorf("netQuantity() = %v, esperado 0.999", got)
+	}
+}
+
+func TestCloseTrade(t *testing.T) {
+	pos := &database.Position{Symbol: "BTCUSDT", Mode: "paper", EntryPrice: dec("100"), Quantity: dec("2"), Fees: dec("0.2")}
+
+	trade := closeTrade(pos, dec("110"), dec("2"), dec("0.22"), "take_profit")
+	if trade == nil {
+		t.Fatal("esperada operação encerrada")
+	}
+	// 220 - 0.22 - (200 + 0.2)
+	if !trade.PnL.Equal(dec("19.58")) || !trade.Fees.Equal(dec("0.42")) {
+		t.Errorf("resultado calculado incorretamente: %+v", trade)
+	}
+	if !trade.PnLPercent.Equal(dec("19.58").Mul(hundred).Div(dec("200.2"))) || trade.Reason != "take_profit" {
+		t.Errorf("resultado calculado incorretamente: %+v", trade)
+	}
+
+	// Venda parcial usa a parcela proporcional das taxas da entrada
+	partial := closeTrade(pos, dec("90"), dec("1"), decimal.Zero, "stop_loss")
+	if partial == nil || !partial.PnL.Equal(dec("-10.1")) {
+		t.Errorf("venda parcial calculada incorretamente: %+v", partial)
+	}
+
+	if closeTrade(&database.Position{Quantity: dec("1")}, dec("110"), dec("1"), decimal.Zero, "strategy") != nil {
+		t.Error("posição sem preço de entrada não deveria gerar operação")
+	}
+}
+
+func TestUnrealizedPnL(t *testing.T) {
+	pos := &database.Position{EntryPrice: dec("100"), Quantity: dec("2"), Fees: dec("2")}
+
+	pnl, percent := unrealizedPnL(pos, dec("105"))
+	if !pnl.Equal(dec("8")) || !percent.Equal(dec("800").Div(dec("202"))) {
+		t.Errorf("unrealizedPnL() = %v, %v%%", pnl, percent)
+	}
+}
+
+func TestFillPrice_KeepsPrecision(t *testing.T) {
+	// Preço e quantidade que perdem dígitos em float32 (REAL) e float64
+	order := &exchange.Order{ExecutedQty: dec("0.00012345"), CummulativeQuoteQty: dec("8.02462035")}
+	if got := fillPrice(order, decimal.Zero); !got.Equal(dec("65003.0")) {
+		t.Errorf("fillPrice() = %v, esperado 65003", got)
+	}
+}
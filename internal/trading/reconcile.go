@@ -0,0 +1,320 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// reconcileTradesLimit é a quantidade de execuções recentes consultadas em myTrades.
+	reconcileTradesLimit = 50
+	// reconcileReason identifica no banco as ordens registradas pela reconciliação.
+	reconcileReason = "reconcile"
+)
+
+// accountState é o retrato do banco e da conta na corretora comparado pela reconciliação.
+type accountState struct {
+	isOpened    bool             // Posição registrada no banco
+	hasOrders   bool             // Se há ordens registradas para o par no modo atual
+	recorded    map[int64]bool   // Ordens das execuções recentes já registradas, pelo identificador da corretora
+	baseBalance decimal.Decimal  // Saldo total (livre + bloqueado) do ativo base
+	minQty      decimal.Decimal  // Quantidade mínima negociável do par (LOT_SIZE)
+	trades      []exchange.Trade // Execuções recentes, da mais antiga para a mais recente
+}
+
+// executedOrder agrega as execuções de uma mesma ordem na corretora.
+type executedOrder struct {
+	orderID  int64
+	side     string
+	quantity decimal.Decimal
+	quoteQty decimal.Decimal // Valor total executado no ativo de cotação
+	time     time.Time       // Horário da última execução
+
+	commission      decimal.Decimal // Taxa total no ativo da primeira execução
+	commissionAsset string          // Ativo em que a taxa foi cobrada
+}
+
+// price retorna o preço médio da ordem ponderado pela quantidade de cada execução.
+func (o executedOrder) price() decimal.Decimal {
+	if !o.quantity.IsPositive() {
+		return decimal.Zero
+	}
+	return o.quoteQty.Div(o.quantity)
+}
+
+// discrepancy descreve uma divergência entre o banco e a corretora e a correção que a resolve.
+type discrepancy struct {
+	description string
+	// missingOrder é a ordem executada na corretora a ser registrada no banco, se houver.
+	missingOrder *executedOrder
+	// position é o estado da posição a ser gravado no banco, se houver.
+	position *bool
+	// entry é a compra que abriu a posição, quando a divergência abre a posição.
+	entry *executedOrder
+}
+
+// reconcileDue indica se a reconciliação do par deve ser executada em now:
+// sempre na primeira avaliação, após uma alteração de saldo informada pelo user
+// data stream e, com RECONCILE_INTERVAL, periodicamente.
+func (t *Trader) reconcileDue(now time.Time) bool {
+	if cfg.Reconcile == config.ReconcileOff || t.halted {
+		return false
+	}
+	requested := t.reconcileRequested.Swap(false)
+	if t.lastReconcile.IsZero() || requested {
+		return true
+	}
+	return cfg.ReconcileInterval > 0 && now.Sub(t.lastReconcile) >= cfg.ReconcileInterval
+}
+
+// reconcile compara a posição e as ordens registradas no banco com o saldo e as
+// execuções recentes da conta, escrevendo as divergências em out
+// Conforme RECONCILE, as divergências são corrigidas no banco (auto) ou a negociação
+// do par é suspensa (halt) até o bot ser reiniciado com o par em RECONCILE_ACK.
+//
+// Retorna erro se os dados não puderem ser obtidos ou se uma correção falhar.
+func (t *Trader) reconcile(ctx context.Context, out io.Writer, now time.Time) error {
+	state, info, err := t.loadAccountState(ctx)
+	if err != nil {
+		return err
+	}
+	t.lastReconcile = now
+
+	found := compareAccount(info.BaseAsset, state)
+	if len(found) == 0 {
+		return nil
+	}
+
+	policy := cfg.Reconcile
+	if t.acknowledged {
+		policy = config.ReconcileAuto
+	}
+	t.acknowledged = false
+
+	for _, d := range found {
+		fmt.Fprintln(out, "Divergência:", d.description)
+	}
+
+	if policy == config.ReconcileHalt {
+		t.halted = true
+		for _, d := range found {
+			t.discrepancies = append(t.discrepancies, d.description)
+		}
+		alert(out, "%s suspenso: %d divergência(s) entre o banco e a corretora. Confira e reinicie com RECONCILE_ACK=%s para corrigi-las",
+			t.Symbol, len(found), t.Symbol)
+		return nil
+	}
+
+	for _, d := range found {
+		if err := t.applyFix(ctx, d, info); err != nil {
+			return fmt.Errorf("erro ao corrigir divergência (%s): %w", d.description, err)
+		}
+		// O acompanhamento das regras de risco só é descartado se a posição mudou
+		if d.position != nil {
+			t.riskPosition = nil
+		}
+	}
+
+	isOpened, err := store.GetPosition(ctx, t.Symbol, cfg.Mode)
+	if err != nil {
+		return err
+	}
+	t.IsOpened = isOpened
+	alert(out, "%s: %d divergência(s) corrigida(s) a partir da corretora", t.Symbol, len(found))
+	return nil
+}
+
+// loadAccountState obtém da corretora e do banco os dados comparados pela reconciliação.
+// Retorna também as regras de negociação do par.
+func (t *Trader) loadAccountState(ctx context.Context) (accountState, *exchange.SymbolInfo, error) {
+	var state accountState
+
+	info, err := client.GetExchangeInfo(ctx, t.Symbol)
+	if err != nil {
+		return state, nil, err
+	}
+	balances, err := client.GetBalances(ctx)
+	if err != nil {
+		return state, nil, err
+	}
+	state.trades, err = client.GetMyTrades(ctx, t.Symbol, reconcileTradesLimit)
+	if err != nil {
+		return state, nil, err
+	}
+	for _, b := range balances {
+		if b.Asset == info.BaseAsset {
+			state.baseBalance = b.Free.Add(b.Locked)
+		}
+	}
+	state.minQty = info.LotSize.MinQty
+
+	state.isOpened, err = store.GetPosition(ctx, t.Symbol, cfg.Mode)
+	if err != nil {
+		return state, nil, err
+	}
+	for _, side := range []string{exchange.SideBuy, exchange.SideSell} {
+		order, err := store.GetLastOrder(ctx, t.Symbol, side, cfg.Mode)
+		if err != nil {
+			return state, nil, err
+		}
+		if order == nil {
+			continue
+		}
+		state.hasOrders = true
+	}
+
+	state.recorded = make(map[int64]bool)
+	for _, trade := range state.trades {
+		if _, ok := state.recorded[trade.OrderID]; ok {
+			continue
+		}
+		order, err := store.GetOrderByExchangeID(ctx, t.Symbol, cfg.Mode, trade.OrderID)
+		if err != nil {
+			return state, nil, err
+		}
+		state.recorded[trade.OrderID] = order != nil
+	}
+	return state, info, nil
+}
+
+// applyFix corrige o banco conforme a divergência encontrada
+// Uma posição aberta pela reconciliação usa o preço e as taxas da compra que a abriu;
+// uma posição encerrada pela reconciliação não gera registro no histórico de trades,
+// pois o preço de saída é desconhecido.
+func (t *Trader) applyFix(ctx context.Context, d discrepancy, info *exchange.SymbolInfo) error {
+	if o := d.missingOrder; o != nil {
+		if err := store.SaveOrder(ctx, database.Order{
+			Symbol:              t.Symbol,
+			Side:                o.side,
+			Quantity:            o.quantity,
+			Price:               o.price(),
+			Mode:                cfg.Mode,
+			Reason:              reconcileReason,
+			ExchangeOrderID:     o.orderID,
+			Status:              "FILLED",
+			ExecutedQty:         o.quantity,
+			CummulativeQuoteQty: o.quoteQty,
+			Commission:          o.commission,
+			CommissionAsset:     o.commissionAsset,
+		}); err != nil {
+			return err
+		}
+	}
+	if d.position == nil {
+		return nil
+	}
+	if !*d.position {
+		return store.ClosePosition(ctx, t.Symbol, cfg.Mode, nil)
+	}
+
+	pos := database.Position{Symbol: t.Symbol, Mode: cfg.Mode, IsOpened: true}
+	if o := d.entry; o != nil {
+		pos.EntryPrice = o.price()
+		pos.Quantity = o.quantity
+		if o.commissionAsset == info.BaseAsset {
+			pos.Quantity = pos.Quantity.Sub(o.commission)
+		}
+		pos.Fees = feeInQuote(o.commission, o.commissionAsset, o.price(), info)
+	}
+	return store.OpenPosition(ctx, pos)
+}
+
+// compareAccount retorna as divergências entre o banco e a conta
+// São verificados:
+//   - Ordens executadas na corretora sem registro no banco com o mesmo identificador
+//     (ex: ordens manuais ou registros perdidos), posteriores à mais antiga das
+//     execuções recentes já registrada; sem ordens registradas, o histórico
+//     anterior ao bot é ignorado
+//   - Posição aberta no banco sem saldo negociável do ativo base
+//   - Posição fechada no banco com saldo negociável do ativo base, quando a
+//     última execução do par foi uma compra; um saldo sem execuções recentes é
+//     tratado como saldo do operador e não como posição do bot
+func compareAccount(baseAsset string, s accountState) []discrepancy {
+	orders := groupTrades(s.trades)
+	var found []discrepancy
+
+	if s.hasOrders {
+		// As execuções anteriores à primeira ordem registrada são do histórico anterior ao bot
+		start := 0
+		for i, o := range orders {
+			if s.recorded[o.orderID] {
+				start = i
+				break
+			}
+		}
+		for _, o := range orders[start:] {
+			if !s.recorded[o.orderID] {
+				found = append(found, discrepancy{
+					description: fmt.Sprintf("ordem %d (%s %s a %s em %s) executada na corretora e não registrada",
+						o.orderID, o.side, o.quantity, o.price().StringFixed(2), o.time.Format(time.DateTime)),
+					missingOrder: &o,
+				})
+			}
+		}
+	}
+
+	holding := s.baseBalance.IsPositive() && s.baseBalance.GreaterThanOrEqual(s.minQty)
+	lastIsBuy := len(orders) > 0 && orders[len(orders)-1].side == exchange.SideBuy
+
+	switch {
+	case s.isOpened && !holding:
+		closed := false
+		found = append(found, discrepancy{
+			description: fmt.Sprintf("posição aberta no banco, mas o saldo de %s (%s) está abaixo da quantidade mínima negociável",
+				baseAsset, s.baseBalance),
+			position: &closed,
+		})
+	case !s.isOpened && holding && lastIsBuy:
+		opened := true
+		d := discrepancy{
+			description: fmt.Sprintf("posição fechada no banco, mas há %s %s na conta e a última execução foi uma compra",
+				s.baseBalance, baseAsset),
+			position: &opened,
+		}
+		last := orders[len(orders)-1]
+		d.entry = &last
+		// Sem ordens registradas, a compra também é registrada no histórico de ordens
+		if !s.hasOrders {
+			d.missingOrder = &last
+		}
+		found = append(found, d)
+	}
+
+	return found
+}
+
+// groupTrades agrega as execuções por ordem, mantendo a ordem cronológica.
+func groupTrades(trades []exchange.Trade) []executedOrder {
+	var orders []executedOrder
+	index := make(map[int64]int)
+	for _, trade := range trades {
+		i, ok := index[trade.OrderID]
+		if !ok {
+			side := exchange.SideSell
+			if trade.IsBuyer {
+				side = exchange.SideBuy
+			}
+			index[trade.OrderID] = len(orders)
+			orders = append(orders, executedOrder{orderID: trade.OrderID, side: side, commissionAsset: trade.CommissionAsset})
+			i = len(orders) - 1
+		}
+
+		o := &orders[i]
+		o.quantity = o.quantity.Add(trade.Qty)
+		o.quoteQty = o.quoteQty.Add(trade.Price.Mul(trade.Qty))
+		if trade.CommissionAsset == o.commissionAsset {
+			o.commission = o.commission.Add(trade.Commission)
+		}
+		if t := time.UnixMilli(trade.Time); t.After(o.time) {
+			o.time = t
+		}
+	}
+	return orders
+}
@@ -0,0 +1,114 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+func TestGroupTrades(t *testing.T) {
+	orders := groupTrades([]exchange.Trade{
+		{OrderID: 1, Price: dec("100"), Qty: dec("1"), Time: 1000, IsBuyer: true},
+		{OrderID: 1, Price: dec("110"), Qty: dec("3"), Time: 2000, IsBuyer: true},
+		{OrderID: 2, Price: dec("120"), Qty: dec("4"), Time: 3000},
+	})
+	if len(orders) != 2 {
+		t.Fatalf("esperadas 2 ordens, obteve %d", len(orders))
+	}
+	if orders[0].side != exchange.SideBuy || !orders[0].quantity.Equal(dec("4")) || !orders[0].price().Equal(dec("107.5")) {
+		t.Errorf("execuções agregadas incorretamente: %+v", orders[0])
+	}
+	if !orders[0].time.Equal(time.UnixMilli(2000)) {
+		t.Errorf("horário da ordem = %v, esperado o da última execução", orders[0].time)
+	}
+	if orders[1].side != exchange.SideSell {
+		t.Errorf("lado da ordem 2 = %s, esperado SELL", orders[1].side)
+	}
+}
+
+func TestCompareAccount(t *testing.T) {
+	recorded := time.UnixMilli(10_000_000)
+	buy := exchange.Trade{OrderID: 1, Price: dec("100"), Qty: dec("0.5"), Time: recorded.UnixMilli(), IsBuyer: true}
+	lateSell := exchange.Trade{OrderID: 2, Price: dec("110"), Qty: dec("0.5"), Time: recorded.Add(time.Hour).UnixMilli()}
+	earlySell := exchange.Trade{OrderID: 3, Price: dec("90"), Qty: dec("0.2"), Time: recorded.Add(-time.Hour).UnixMilli()}
+
+	tests := []struct {
+		name         string
+		state        accountState
+		wantCount    int
+		wantPosition *bool
+		wantMissing  int64
+	}{
+		{
+			name: "Should find nothing when database matches account",
+			state: accountState{isOpened: true, hasOrders: true, recorded: map[int64]bool{1: true},
+				baseBalance: dec("0.5"), minQty: dec("0.001"), trades: []exchange.Trade{buy}},
+		},
+		{
+			// O horário do registro no banco pode divergir do da corretora (ex: fuso do servidor)
+			name: "Should match recorded orders by their exchange ID regardless of time",
+			state: accountState{isOpened: false, hasOrders: true, recorded: map[int64]bool{1: true, 2: true},
+				baseBalance: dec("0"), minQty: dec("0.001"), trades: []exchange.Trade{buy, lateSell}},
+		},
+		{
+			name: "Should ignore trades before the first recorded order",
+			state: accountState{isOpened: true, hasOrders: true, recorded: map[int64]bool{1: true},
+				baseBalance: dec("0.5"), minQty: dec("0.001"), trades: []exchange.Trade{earlySell, buy}},
+		},
+		{
+			name: "Should close position sold outside the bot",
+			state: accountState{isOpened: true, hasOrders: true, recorded: map[int64]bool{1: true},
+				baseBalance: dec("0"), minQty: dec("0.001"), trades: []exchange.Trade{buy, lateSell}},
+			wantCount:    2,
+			wantPosition: boolPtr(false),
+			wantMissing:  2,
+		},
+		{
+			name: "Should open position bought outside the bot",
+			state: accountState{isOpened: false, baseBalance: dec("0.5"), minQty: dec("0.001"),
+				trades: []exchange.Trade{buy}},
+			wantCount:    1,
+			wantPosition: boolPtr(true),
+			wantMissing:  1,
+		},
+		{
+			name:  "Should ignore balance without recent trades",
+			state: accountState{isOpened: false, baseBalance: dec("0.5"), minQty: dec("0.001")},
+		},
+		{
+			name:  "Should ignore dust below the minimum quantity",
+			state: accountState{isOpened: false, baseBalance: dec("0.0001"), minQty: dec("0.001"), trades: []exchange.Trade{buy}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found := compareAccount("BTC", tt.state)
+			if len(found) != tt.wantCount {
+				t.Fatalf("esperadas %d divergências, obteve %d: %+v", tt.wantCount, len(found), found)
+			}
+
+			var position *bool
+			var missing int64
+			for _, d := range found {
+				if d.position != nil {
+					position = d.position
+				}
+				if d.missingOrder != nil {
+					missing = d.missingOrder.orderID
+				}
+			}
+			if (position == nil) != (tt.wantPosition == nil) || (position != nil && *position != *tt.wantPosition) {
+				t.Errorf("correção da posição = %v, esperado %v", position, tt.wantPosition)
+			}
+			if missing != tt.wantMissing {
+				t.Errorf("ordem não registrada = %d, esperado %d", missing, tt.wantMissing)
+			}
+		})
+	}
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
@@ -0,0 +1,96 @@
+package trading
+
+import (
+	"context"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/marketdata"
+)
+
+// streams são os streams de klines de cada par, iniciados por StartStreams.
+// Sem streams, GetCandlesticks obtém os candles pela API REST.
+var streams map[string]*marketdata.KlineStream
+
+// closeWait é a espera máxima, após o primeiro par receber o fechamento de um
+// candle, pelos fechamentos dos demais pares antes de iniciar a avaliação.
+const closeWait = 2 * time.Second
+
+// StartStreams conecta o stream de klines de cada par configurado em SYMBOLS no
+// endereço url, mantendo em memória os candles usados nas avaliações, e retorna um
+// canal que recebe o horário de fechamento de cada candle. O sinal é enviado quando
+// todos os pares receberam o fechamento, ou closeWait após o primeiro deles.
+// Os streams são encerrados quando ctx é cancelado.
+// Deve ser chamado após Initialize e antes de StartTrading.
+func StartStreams(ctx context.Context, url string) <-chan time.Time {
+	streams = make(map[string]*marketdata.KlineStream, len(traders))
+	closes := make(chan int64)
+	for _, t := range traders {
+		s := marketdata.NewKlineStream(url, client, t.Symbol, cfg.Interval, cfg.Lookback+1)
+		streams[t.Symbol] = s
+		go s.Run(ctx)
+		go func() {
+			for c := range s.Closed() {
+				select {
+				case closes <- c.CloseTime + 1:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+
+	signals := make(chan time.Time, 1)
+	go coalesceCloses(ctx, closes, len(streams), closeWait, signals)
+	return signals
+}
+
+// coalesceCloses agrupa os fechamentos recebidos de cada um dos n pares em closes,
+// em milissegundos, enviando a out um único sinal por candle: quando os n pares
+// o receberam ou após wait. Fechamentos de candles já sinalizados são ignorados,
+// e um sinal não é enviado se o anterior ainda não foi consumido.
+func coalesceCloses(ctx context.Context, closes <-chan int64, n int, wait time.Duration, out chan<- time.Time) {
+	var (
+		current, last int64
+		received      int
+		timeout       <-chan time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case closeTime := <-closes:
+			if closeTime <= last || closeTime < current {
+				continue
+			}
+			if closeTime > current {
+				current = closeTime
+				received = 0
+				timeout = time.After(wait)
+			}
+			if received++; received < n {
+				continue
+			}
+		case <-timeout:
+		}
+
+		timeout = nil
+		last = current
+		select {
+		case out <- time.UnixMilli(current).UTC():
+		default:
+		}
+	}
+}
+
+// streamCandles retorna os últimos limit candles do stream do par, ou nil se não
+// houver stream conectado para o intervalo ou se ele mantiver menos de limit candles.
+func streamCandles(symbol string, interval string, limit int) []Candlestick {
+	s, ok := streams[symbol]
+	if !ok || s.Interval != interval || !s.Live() {
+		return nil
+	}
+	candles := s.Candles()
+	if len(candles) < limit {
+		return nil
+	}
+	return candles[len(candles)-limit:]
+}
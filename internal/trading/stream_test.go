@@ -0,0 +1,63 @@
+package trading
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCoalesceCloses(t *testing.T) {
+	const minute = int64(time.Minute / time.Millisecond)
+
+	tests := []struct {
+		name   string
+		closes []int64
+		want   []int64
+	}{
+		{
+			name:   "Should signal once all pairs received the close",
+			closes: []int64{minute, minute},
+			want:   []int64{minute},
+		},
+		{
+			name:   "Should signal after the wait when a pair is missing",
+			closes: []int64{minute},
+			want:   []int64{minute},
+		},
+		{
+			name:   "Should ignore closes of candles already signaled",
+			closes: []int64{minute, minute, minute, 2 * minute, minute, 2 * minute},
+			want:   []int64{minute, 2 * minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			closes := make(chan int64)
+			out := make(chan time.Time, len(tt.closes))
+			go coalesceCloses(ctx, closes, 2, 50*time.Millisecond, out)
+			for _, c := range tt.closes {
+				closes <- c
+			}
+
+			for _, want := range tt.want {
+				select {
+				case got := <-out:
+					if got.UnixMilli() != want {
+						t.Errorf("sinal do fechamento %d, esperado %d", got.UnixMilli(), want)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("sinal do fechamento %d não recebido", want)
+				}
+			}
+			select {
+			case got := <-out:
+				t.Errorf("sinal inesperado do fechamento %d", got.UnixMilli())
+			case <-time.After(100 * time.Millisecond):
+			}
+		})
+	}
+}
@@ -0,0 +1,583 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sync/atomic"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/risk"
+	"github.com/brunossouza/crypto_bot/internal/sizing"
+	"github.com/brunossouza/crypto_bot/internal/strategy"
+	"github.com/shopspring/decimal"
+)
+
+// Trader mantém o estado de negociação de um único par: a instância da
+// estratégia, o dimensionamento e a posição aberta. Cada Trader é avaliado
+// por uma única goroutine por vez em StartTrading.
+type Trader struct {
+	// Symbol é o par negociado (ex: BTCUSDT).
+	Symbol string
+	// IsOpened indica se há posição aberta no par.
+	IsOpened bool
+
+	strategy     strategy.Strategy
+	sizer        sizing.Sizer
+	riskPosition *risk.Position
+
+	// failures é a quantidade de avaliações consecutivas que falharam.
+	failures int
+	// pausedUntil é o instante até o qual o par fica sem ser avaliado
+	// após atingir MAX_CONSECUTIVE_FAILURES.
+	pausedUntil time.Time
+
+	// lastReconcile é o instante da última reconciliação com a corretora.
+	lastReconcile time.Time
+	// acknowledged indica que o par está em RECONCILE_ACK; vale apenas para a primeira reconciliação.
+	acknowledged bool
+	// halted indica que a negociação foi suspensa por divergências com a corretora.
+	halted bool
+	// discrepancies são as divergências que motivaram a suspensão.
+	discrepancies []string
+	// rejected é o erro da corretora que rejeitou as credenciais da API e suspendeu
+	// a negociação do par (ver recordFailure).
+	rejected error
+	// reconcileRequested indica que o saldo do ativo base mudou (ver HandleUserEvents)
+	// e o par deve ser reconciliado na próxima avaliação.
+	reconcileRequested atomic.Bool
+}
+
+// newTrader cria o Trader do par informado com sua própria instância da
+// estratégia e do dimensionamento, carregando o estado da posição do banco.
+func newTrader(ctx context.Context, symbol string, params strategy.Params) (*Trader, error) {
+	s, err := strategy.New(cfg.Strategy, params)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar estratégia: %w", err)
+	}
+	sizer, err := sizing.New(cfg.SizingMethod, cfg.SizingValue)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao configurar dimensionamento de posição: %w", err)
+	}
+
+	// Carrega o último estado da posição do banco
+	status, err := store.GetPosition(ctx, symbol, cfg.Mode)
+	if err != nil {
+		log.Printf("Erro ao carregar status da posição de %s: %v", symbol, err)
+	}
+
+	return &Trader{
+		Symbol:       symbol,
+		IsOpened:     status,
+		strategy:     s,
+		sizer:        sizer,
+		acknowledged: slices.Contains(cfg.ReconcileAck, symbol),
+	}, nil
+}
+
+// Tick executa uma avaliação do par, escrevendo o relatório em out
+// Uma avaliação que falha (ex: resposta malformada da corretora ou candles
+// insuficientes) é ignorada e registrada, sem interromper o bot. Após
+// MAX_CONSECUTIVE_FAILURES falhas seguidas o par é pausado por FAILURE_PAUSE
+// e um alerta é emitido; a contagem só é zerada por uma avaliação bem-sucedida,
+// então uma nova falha logo após a pausa volta a pausar o par. Uma avaliação
+// interrompida pelo cancelamento de ctx não conta como falha.
+//
+// Antes da primeira avaliação (e a cada RECONCILE_INTERVAL) o estado do par é
+// reconciliado com a conta na corretora (ver reconcile); um par suspenso por
+// divergências não é mais avaliado. Um par cujas credenciais foram rejeitadas
+// pela corretora também é suspenso até o reinício do bot.
+//
+// Toda chamada, avaliada ou não, é registrada no diário de decisões (ver recordDecision).
+func (t *Trader) Tick(ctx context.Context, out io.Writer) {
+	logger := log.New(out, "", log.LstdFlags)
+	now := time.Now()
+	decision := database.Decision{
+		Symbol:      t.Symbol,
+		Mode:        cfg.Mode,
+		Strategy:    t.strategy.Name(),
+		EvaluatedAt: now,
+		IsOpened:    t.IsOpened,
+		Action:      database.ActionSkip,
+	}
+	defer func() { t.recordDecision(ctx, logger, decision) }()
+
+	if now.Before(t.pausedUntil) {
+		fmt.Fprintln(out, "Ativo:", t.Symbol)
+		fmt.Fprintf(out, "Pausado até %s após %d falhas consecutivas\n", t.pausedUntil.Format(time.DateTime), t.failures)
+		decision.Reason = fmt.Sprintf("pausado até %s após %d falhas consecutivas", t.pausedUntil.Format(time.DateTime), t.failures)
+		return
+	}
+
+	if t.rejected != nil {
+		fmt.Fprintln(out, "Ativo:", t.Symbol)
+		fmt.Fprintf(out, "Negociação suspensa: credenciais rejeitadas pela corretora (%v); corrija BINANCE_API_KEY e BINANCE_API_SECRET e reinicie\n", t.rejected)
+		decision.Reason = "suspenso por credenciais rejeitadas"
+		return
+	}
+
+	if t.reconcileDue(now) {
+		if err := t.reconcile(ctx, out, now); err != nil {
+			err = fmt.Errorf("erro na reconciliação: %w", err)
+			decision.Reason, decision.Error = "falha na reconciliação", err.Error()
+			t.recordFailure(ctx, out, logger, now, err)
+			return
+		}
+	}
+
+	if t.halted {
+		fmt.Fprintln(out, "Ativo:", t.Symbol)
+		fmt.Fprintf(out, "Negociação suspensa por divergências com a corretora; confira e reinicie com RECONCILE_ACK=%s\n", t.Symbol)
+		for _, d := range t.discrepancies {
+			fmt.Fprintln(out, "Divergência:", d)
+		}
+		decision.Reason = "suspenso por divergências com a corretora"
+		return
+	}
+
+	if err := t.evaluate(ctx, out, logger, now, &decision); err != nil {
+		if decision.Reason == "" {
+			decision.Reason = "falha na avaliação"
+		}
+		decision.Error = err.Error()
+		t.recordFailure(ctx, out, logger, now, err)
+		return
+	}
+	t.failures = 0
+}
+
+// recordDecision grava a decisão no diário. O registro é feito mesmo após o
+// cancelamento de ctx, para que a avaliação interrompida pelo encerramento
+// também fique registrada; uma falha na gravação é apenas registrada no log.
+func (t *Trader) recordDecision(ctx context.Context, logger *log.Logger, d database.Decision) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	if err := store.SaveDecision(ctx, d); err != nil {
+		logger.Printf("Erro ao registrar a decisão de %s: %v", t.Symbol, err)
+	}
+}
+
+// recordFailure registra uma avaliação que falhou, pausando o par e emitindo um
+// alerta ao atingir MAX_CONSECUTIVE_FAILURES. Falhas causadas pelo cancelamento
+// de ctx são apenas registradas. A rejeição das credenciais (assinatura inválida
+// ou chave sem permissão), que não se resolve com novas tentativas, suspende o
+// par imediatamente com um alerta.
+func (t *Trader) recordFailure(ctx context.Context, out io.Writer, logger *log.Logger, now time.Time, err error) {
+	if ctx.Err() != nil {
+		logger.Printf("Avaliação de %s interrompida pelo encerramento: %v", t.Symbol, err)
+		return
+	}
+	if errors.Is(err, exchange.ErrInvalidSignature) || errors.Is(err, exchange.ErrUnauthorized) {
+		t.rejected = err
+		alert(out, "Negociação de %s suspensa: credenciais rejeitadas pela corretora: %v", t.Symbol, err)
+		return
+	}
+	t.failures++
+	logger.Printf("Avaliação de %s ignorada (%d falha(s) consecutiva(s)): %v", t.Symbol, t.failures, err)
+	if cfg.MaxFailures > 0 && t.failures >= cfg.MaxFailures {
+		t.pausedUntil = now.Add(cfg.FailurePause)
+		alert(out, "%s pausado até %s após %d falhas consecutivas: %v",
+			t.Symbol, t.pausedUntil.Format(time.DateTime), t.failures, err)
+	}
+}
+
+// evaluate executa a lógica de trading do par sobre os candles fechados até now
+// O método:
+// 1. Obtém os candles fechados mais recentes (LOOKBACK), descartando o candle em formação
+// 2. Extrai o fechamento do último candle e o histórico de preços
+// 3. Calcula os indicadores da estratégia configurada
+// 4. Com a posição aberta, avalia as regras de stop-loss, take-profit e trailing stop
+// 5. Executa a lógica de trading baseada na estratégia:
+//   - Compra quando ShouldEnter indica entrada e não há posição aberta
+//   - Vende quando ShouldExit indica saída e há posição aberta
+//
+// Os dados considerados, os sinais e a ação decidida são preenchidos em decision.
+// Retorna erro se algum passo falhar; nenhuma ordem é enviada após um erro de leitura.
+func (t *Trader) evaluate(ctx context.Context, out io.Writer, logger *log.Logger, now time.Time, decision *database.Decision) error {
+	// Obtém os candles; um a mais é solicitado para compensar o candle em formação
+	candlesticks, err := GetCandlesticks(ctx, t.Symbol, cfg.Interval, cfg.Lookback+1)
+	if err != nil {
+		return err
+	}
+	candlesticks = closedCandles(candlesticks, now, cfg.Lookback)
+	if len(candlesticks) < t.strategy.MinBars() {
+		return fmt.Errorf("candles fechados insuficientes: %d de %d necessários", len(candlesticks), t.strategy.MinBars())
+	}
+
+	// Obtém o preço de fechamento do último candle fechado; os indicadores e as
+	// regras de risco usam float64, as ordens e o PnL usam o valor decimal
+	lastPrice := candlesticks[len(candlesticks)-1].Close
+	price := decimal.NewFromFloat(lastPrice)
+	decision.CandleTime = candlesticks[len(candlesticks)-1].OpenTime
+	decision.Price = price
+
+	var prices []float64
+	for _, c := range candlesticks {
+		prices = append(prices, c.Close)
+	}
+
+	// Calcula os indicadores da estratégia para exibição
+	snapshot, err := t.strategy.Indicators(prices)
+	if err != nil {
+		return fmt.Errorf("erro ao calcular indicadores: %w", err)
+	}
+
+	fmt.Fprintln(out, "Ativo:", t.Symbol)
+	fmt.Fprintln(out, "Estratégia:", t.strategy.Name())
+	fmt.Fprintf(out, "Último preço: %.2f\n", lastPrice)
+	decision.Indicators = make(map[string]float64, len(snapshot))
+	for _, ind := range snapshot {
+		fmt.Fprintf(out, "%s: %.2f\n", ind.Name, ind.Value)
+		decision.Indicators[ind.Key] = ind.Value
+	}
+	fmt.Fprintln(out, "Aberto:", t.IsOpened)
+
+	// Obtém a posição aberta do banco
+	position, err := store.GetOpenPosition(ctx, t.Symbol, cfg.Mode)
+	if err != nil {
+		return fmt.Errorf("erro ao obter posição: %w", err)
+	}
+	isOpened := position != nil
+	decision.IsOpened = isOpened
+	// A posição pode ter sido encerrada fora da avaliação (ex: pelo user data stream)
+	if !isOpened {
+		t.riskPosition = nil
+	}
+	if err := t.printPnL(ctx, out, position, price); err != nil {
+		return err
+	}
+
+	// Avalia as saídas gerenciadas pelo bot antes das decisões da estratégia
+	if isOpened && riskRules.Enabled() {
+		if pos := t.loadRiskPosition(ctx, logger, position); pos != nil {
+			highest := pos.HighestPrice
+			reason, ok := riskRules.Check(pos, lastPrice)
+			if pos.HighestPrice > highest {
+				t.saveHighestPrice(ctx, logger, pos)
+			}
+			if ok {
+				fmt.Fprintf(out, "Saída por %s, momento de vender\n", reason)
+				decision.Action, decision.Reason = database.ActionSell, string(reason)
+				return t.closePosition(ctx, logger, price, reason, decision.CandleTime)
+			}
+			printRiskLevels(out, pos)
+		}
+	}
+
+	if !isOpened {
+		enter, err := t.strategy.ShouldEnter(prices)
+		if err != nil {
+			return fmt.Errorf("erro ao avaliar entrada: %w", err)
+		}
+		decision.ShouldEnter = &enter
+		if enter {
+			fmt.Fprintln(out, "sobrevendido, momento de comprar")
+			decision.Action, decision.Reason = database.ActionBuy, string(risk.ReasonStrategy)
+			return t.openPosition(ctx, logger, price, decision.CandleTime)
+		}
+		decision.Reason = "sem sinal de entrada"
+	} else {
+		exit, err := t.strategy.ShouldExit(prices)
+		if err != nil {
+			return fmt.Errorf("erro ao avaliar saída: %w", err)
+		}
+		decision.ShouldExit = &exit
+		if exit {
+			fmt.Fprintln(out, "sobrecomprado, momento de vender")
+			decision.Action, decision.Reason = database.ActionSell, string(risk.ReasonStrategy)
+			return t.closePosition(ctx, logger, price, risk.ReasonStrategy, decision.CandleTime)
+		}
+		decision.Reason = "sem sinal de saída"
+	}
+
+	fmt.Fprintln(out, "Aguardando oportunidades...")
+	decision.Action = database.ActionHold
+	return nil
+}
+
+// openPosition dimensiona e envia uma compra ao preço informado, decidida na
+// avaliação do candle aberto em candleTime, atualizando IsOpened conforme o resultado.
+// Retorna erro se a compra não for enviada ou não for registrada no banco.
+func (t *Trader) openPosition(ctx context.Context, logger *log.Logger, price decimal.Decimal, candleTime int64) error {
+	quantity, err := t.buyQuantity(ctx, price)
+	if err != nil {
+		t.IsOpened = false
+		return fmt.Errorf("erro ao calcular quantidade da compra: %w", err)
+	}
+
+	// Uma compra reenviada por falta de saldo é recalculada com o preço acrescido de
+	// resizeMargin, cobrindo a alta do preço desde o cálculo da quantidade
+	resize := func(ctx context.Context) (decimal.Decimal, error) {
+		return t.buyQuantity(ctx, price.Mul(decimal.NewFromInt(1).Add(resizeMargin)))
+	}
+
+	// Se a ordem foi aceita mas não registrada, a posição está aberta na corretora
+	id := clientOrderID(t.Symbol, exchange.SideBuy, candleTime)
+	order, err := t.placeOrder(ctx, logger, exchange.SideBuy, quantity, price, risk.ReasonStrategy, id, resize)
+	if order == nil || errors.Is(err, ErrNotFilled) {
+		t.IsOpened = false
+		return err
+	}
+	t.IsOpened = true
+	t.riskPosition = risk.NewPosition(fillPrice(order, price).InexactFloat64())
+	if err != nil {
+		return err
+	}
+	logger.Printf("Ordem criada com sucesso: id=%d status=%s", order.OrderID, order.Status)
+	return nil
+}
+
+// closePosition vende a posição aberta ao preço informado, registrando a regra
+// que motivou a saída decidida na avaliação do candle aberto em candleTime, e
+// atualiza IsOpened conforme o resultado.
+// Retorna erro se a venda não for enviada ou não for registrada no banco.
+func (t *Trader) closePosition(ctx context.Context, logger *log.Logger, price decimal.Decimal, reason risk.Reason, candleTime int64) error {
+	quantity, err := t.sellQuantity(ctx)
+	if err != nil {
+		t.IsOpened = true
+		return fmt.Errorf("erro ao calcular quantidade da venda: %w", err)
+	}
+
+	id := clientOrderID(t.Symbol, exchange.SideSell, candleTime)
+	order, err := t.placeOrder(ctx, logger, exchange.SideSell, quantity, price, reason, id, t.sellQuantity)
+	if order == nil || errors.Is(err, ErrNotFilled) {
+		t.IsOpened = true
+		return err
+	}
+	t.IsOpened = false
+	t.riskPosition = nil
+	if err != nil {
+		return err
+	}
+	logger.Printf("Ordem criada com sucesso: id=%d status=%s", order.OrderID, order.Status)
+	return nil
+}
+
+// resizeMargin é a parcela do preço acrescida no recálculo de uma compra rejeitada
+// por saldo insuficiente (ver openPosition).
+var resizeMargin = decimal.RequireFromString("0.01")
+
+// placeOrder envia a ordem com o identificador do cliente id pelo NewOrder e, se
+// ela não for aceita por um motivo que um novo envio pode resolver, a reenvia uma
+// única vez com o mesmo identificador:
+//   - Timestamp fora da recvWindow (exchange.ErrInvalidTimestamp): com a mesma
+//     quantidade, já que o cliente da Binance sincroniza o relógio após a rejeição
+//   - Falha no envio de uma ordem que a consulta confirmou não ter chegado à
+//     corretora (ErrNotSent): com a mesma quantidade
+//   - Saldo insuficiente (exchange.ErrInsufficientBalance) ou filtros do par violados
+//     (exchange.ErrFilterFailure): com a quantidade recalculada por resize, com o
+//     saldo e as regras do par atualizados; se a quantidade não diminuir, a
+//     rejeição é retornada sem novo envio
+//
+// As demais falhas são retornadas sem novo envio; uma ordem com resultado desconhecido
+// (ErrUncertainOrder) bloqueia os envios do par até ser confirmada na corretora.
+func (t *Trader) placeOrder(ctx context.Context, logger *log.Logger, side string, quantity, price decimal.Decimal,
+	reason risk.Reason, id string, resize func(context.Context) (decimal.Decimal, error)) (*exchange.Order, error) {
+	order, err := NewOrder(ctx, t.Symbol, quantity, side, price, reason, id)
+	if err == nil || order != nil {
+		return order, err
+	}
+
+	switch {
+	case errors.Is(err, exchange.ErrInvalidTimestamp):
+		logger.Printf("Ordem de %s rejeitada pelo timestamp; reenviando com o relógio sincronizado", t.Symbol)
+	case errors.Is(err, ErrNotSent):
+		logger.Printf("Ordem %s não recebida pela corretora (%v); reenviando", id, err)
+	case errors.Is(err, exchange.ErrInsufficientBalance), errors.Is(err, exchange.ErrFilterFailure):
+		resized, resizeErr := resize(ctx)
+		if resizeErr != nil {
+			return nil, fmt.Errorf("%w (quantidade não recalculada: %v)", err, resizeErr)
+		}
+		if !resized.LessThan(quantity) {
+			return nil, err
+		}
+		logger.Printf("Ordem de %s rejeitada (%v); reenviando com a quantidade %s em vez de %s", t.Symbol, err, resized, quantity)
+		quantity = resized
+	default:
+		return nil, err
+	}
+	return NewOrder(ctx, t.Symbol, quantity, side, price, reason, id)
+}
+
+// buyQuantity calcula a quantidade da compra a partir do saldo livre do ativo
+// de cotação e do dimensionamento configurado, arredondada para o lot size do par.
+//
+// Retorna um *exchange.FilterError se a quantidade ou o valor resultante
+// violarem os filtros do par.
+func (t *Trader) buyQuantity(ctx context.Context, price decimal.Decimal) (decimal.Decimal, error) {
+	info, err := client.GetExchangeInfo(ctx, t.Symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	balances, err := client.GetBalances(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	quantity, err := t.sizer.Size(sizing.Input{
+		Price:           price,
+		FreeQuote:       freeBalance(balances, info.QuoteAsset),
+		StopLossPercent: cfg.StopLossPercent,
+	})
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	quantity, err = info.NormalizeQuantity(quantity)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if err := info.ValidateNotional(quantity, price, exchange.OrderTypeMarket); err != nil {
+		return decimal.Zero, err
+	}
+	return quantity, nil
+}
+
+// sellQuantity calcula a quantidade da venda: a quantidade da posição aberta
+// (ou, em posições abertas antes do registro da quantidade, a recebida na última
+// compra registrada), limitada ao saldo livre do ativo base e arredondada para o lot size.
+// Sem compra registrada, todo o saldo livre do ativo base é vendido.
+func (t *Trader) sellQuantity(ctx context.Context) (decimal.Decimal, error) {
+	info, err := client.GetExchangeInfo(ctx, t.Symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	balances, err := client.GetBalances(ctx)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	quantity := freeBalance(balances, info.BaseAsset)
+	position, err := store.GetOpenPosition(ctx, t.Symbol, cfg.Mode)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	if position != nil && position.Quantity.IsPositive() {
+		quantity = decimal.Min(quantity, position.Quantity)
+	} else {
+		order, err := store.GetLastOrder(ctx, t.Symbol, exchange.SideBuy, cfg.Mode)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		if order != nil {
+			quantity = decimal.Min(quantity, boughtQuantity(order, info.BaseAsset))
+		}
+	}
+
+	quantity, err = info.NormalizeQuantity(quantity)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("saldo de %s insuficiente para vender: %w", info.BaseAsset, err)
+	}
+	return quantity, nil
+}
+
+// loadRiskPosition retorna a posição acompanhada pelas regras de risco
+// Se o bot foi reiniciado com a posição aberta, o preço de entrada e o maior preço
+// desde a abertura são recuperados da posição registrada no banco ou, em posições
+// abertas antes do registro do preço de entrada, o preço da última ordem de compra.
+//
+// O acompanhamento é refeito se o preço de entrada registrado mudou desde que foi
+// carregado (ex: uma compra que aumentou a posição pelo user data stream), para que as
+// regras sejam medidas a partir do preço médio de entrada.
+//
+// Retorna nil se não houver preço de entrada registrado.
+func (t *Trader) loadRiskPosition(ctx context.Context, logger *log.Logger, position *database.Position) *risk.Position {
+	if t.riskPosition != nil && position != nil && position.EntryPrice.IsPositive() &&
+		position.EntryPrice.InexactFloat64() != t.riskPosition.EntryPrice {
+		t.riskPosition = nil
+	}
+	if t.riskPosition != nil {
+		return t.riskPosition
+	}
+	if position != nil && position.EntryPrice.IsPositive() {
+		t.riskPosition = risk.NewPosition(position.EntryPrice.InexactFloat64())
+		if highest := position.HighestPrice.InexactFloat64(); highest > t.riskPosition.HighestPrice {
+			t.riskPosition.HighestPrice = highest
+		}
+		return t.riskPosition
+	}
+
+	order, err := store.GetLastOrder(ctx, t.Symbol, exchange.SideBuy, cfg.Mode)
+	if err != nil {
+		logger.Printf("Erro ao obter preço de entrada: %v", err)
+		return nil
+	}
+	if order == nil {
+		logger.Println("Posição aberta sem ordem de compra registrada, regras de saída ignoradas")
+		return nil
+	}
+
+	t.riskPosition = risk.NewPosition(order.Price.InexactFloat64())
+	return t.riskPosition
+}
+
+// saveHighestPrice grava no banco o maior preço da posição acompanhada pelo
+// trailing stop, para que ele seja mantido após o reinício do bot.
+// Uma falha na gravação é apenas registrada no log: a máxima continua em memória.
+func (t *Trader) saveHighestPrice(ctx context.Context, logger *log.Logger, pos *risk.Position) {
+	if err := store.UpdateHighestPrice(ctx, t.Symbol, cfg.Mode, decimal.NewFromFloat(pos.HighestPrice)); err != nil {
+		logger.Printf("Erro ao registrar o maior preço da posição de %s: %v", t.Symbol, err)
+	}
+}
+
+// printPnL exibe o resultado não realizado da posição aberta avaliada a price
+// e o resultado realizado acumulado das operações encerradas do par.
+func (t *Trader) printPnL(ctx context.Context, out io.Writer, position *database.Position, price decimal.Decimal) error {
+	if position != nil && position.EntryPrice.IsPositive() {
+		pnl, percent := unrealizedPnL(position, price)
+		fmt.Fprintln(out, "Quantidade:", position.Quantity)
+		fmt.Fprintf(out, "PnL não realizado: %s (%s%%)\n", pnl.StringFixed(2), percent.StringFixed(2))
+	}
+
+	realized, count, err := store.GetRealizedPnL(ctx, t.Symbol, cfg.Mode)
+	if err != nil {
+		return fmt.Errorf("erro ao obter PnL realizado: %w", err)
+	}
+	if count > 0 {
+		fmt.Fprintf(out, "PnL realizado: %s em %d operação(ões)\n", realized.StringFixed(2), count)
+	}
+	return nil
+}
+
+// boughtQuantity retorna a quantidade do ativo base recebida na compra: a
+// quantidade executada descontada da taxa cobrada no próprio ativo base.
+// Ordens registradas antes do registro das execuções usam a quantidade solicitada.
+func boughtQuantity(order *database.Order, baseAsset string) decimal.Decimal {
+	if order.Status == "" {
+		return order.Quantity
+	}
+	quantity := order.ExecutedQty
+	if order.CommissionAsset == baseAsset {
+		quantity = quantity.Sub(order.Commission)
+	}
+	return quantity
+}
+
+// freeBalance retorna o saldo livre do ativo informado, ou zero se não houver saldo.
+func freeBalance(balances []exchange.Balance, asset string) decimal.Decimal {
+	for _, b := range balances {
+		if b.Asset == asset {
+			return b.Free
+		}
+	}
+	return decimal.Zero
+}
+
+// printRiskLevels exibe o preço de entrada e os níveis de saída ativos.
+func printRiskLevels(out io.Writer, pos *risk.Position) {
+	fmt.Fprintf(out, "Entrada: %.2f\n", pos.EntryPrice)
+	if p := riskRules.StopLossPrice(pos); p > 0 {
+		fmt.Fprintf(out, "Stop-loss: %.2f\n", p)
+	}
+	if p := riskRules.TrailingStopPrice(pos); p > 0 {
+		fmt.Fprintf(out, "Trailing stop: %.2f (máxima %.2f)\n", p, pos.HighestPrice)
+	}
+	if p := riskRules.TakeProfitPrice(pos); p > 0 {
+		fmt.Fprintf(out, "Take-profit: %.2f\n", p)
+	}
+}
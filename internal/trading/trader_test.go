@@ -0,0 +1,318 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/risk"
+	"github.com/brunossouza/crypto_bot/internal/strategy"
+	"github.com/shopspring/decimal"
+)
+
+// fakeMarket retorna candles de 1 minuto fechados com preços em alta, ou err se definido.
+type fakeMarket struct {
+	exchange.Exchange
+	err error
+}
+
+func (f *fakeMarket) GetKlines(ctx context.Context, symbol string, interval string, limit int) ([]exchange.Candlestick, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	last := time.Now().Truncate(time.Minute).Add(-time.Minute)
+	candles := make([]exchange.Candlestick, limit)
+	for i := range candles {
+		open := last.Add(time.Duration(i-limit+1) * time.Minute)
+		candles[i] = exchange.Candlestick{
+			OpenTime:  open.UnixMilli(),
+			CloseTime: open.Add(time.Minute).UnixMilli() - 1,
+			Close:     100 + float64(i),
+		}
+	}
+	return candles, nil
+}
+
+// setupTrader configura o pacote com a corretora ex e um banco em memória e cria o Trader de BTCUSDT.
+func setupTrader(t *testing.T, ex exchange.Exchange) *Trader {
+	t.Helper()
+	cfg = &config.Config{
+		Interval:     "1m",
+		Lookback:     20,
+		Strategy:     "rsi",
+		SizingMethod: "fixed_quote",
+		SizingValue:  50,
+		Mode:         config.ModePaper,
+		Reconcile:    config.ReconcileOff,
+		MaxFailures:  5,
+		FailurePause: time.Hour,
+	}
+	client = ex
+	store = database.NewMemoryStore()
+
+	tr, err := newTrader(context.Background(), "BTCUSDT", strategy.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tr
+}
+
+// lastDecision retorna a decisão mais recente registrada para BTCUSDT.
+func lastDecision(t *testing.T) database.Decision {
+	t.Helper()
+	decisions, err := store.GetDecisions(context.Background(), "BTCUSDT", config.ModePaper, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decisions) != 1 {
+		t.Fatal("nenhuma decisão registrada")
+	}
+	return decisions[0]
+}
+
+func TestTick_RecordsDecision(t *testing.T) {
+	tr := setupTrader(t, &fakeMarket{})
+	tr.Tick(context.Background(), io.Discard)
+
+	d := lastDecision(t)
+	if d.Action != database.ActionHold || d.Reason != "sem sinal de entrada" || d.Error != "" {
+		t.Errorf("ação registrada incorretamente: %+v", d)
+	}
+	if d.ShouldEnter == nil || *d.ShouldEnter || d.ShouldExit != nil {
+		t.Errorf("sinais registrados incorretamente: enter=%v exit=%v", d.ShouldEnter, d.ShouldExit)
+	}
+	if _, ok := d.Indicators[strategy.IndicatorRSI]; !ok || !d.Price.IsPositive() || d.CandleTime == 0 {
+		t.Errorf("dados da avaliação não registrados: %+v", d)
+	}
+	if d.Strategy != "rsi" || d.IsOpened {
+		t.Errorf("contexto da avaliação incorreto: %+v", d)
+	}
+}
+
+func TestTick_RecordsSkippedEvaluations(t *testing.T) {
+	tr := setupTrader(t, &fakeMarket{err: errors.New("timeout")})
+	tr.Tick(context.Background(), io.Discard)
+
+	d := lastDecision(t)
+	if d.Action != database.ActionSkip || d.Reason != "falha na avaliação" || d.Error == "" {
+		t.Errorf("falha registrada incorretamente: %+v", d)
+	}
+
+	tr.pausedUntil = time.Now().Add(time.Hour)
+	tr.Tick(context.Background(), io.Discard)
+
+	d = lastDecision(t)
+	if d.Action != database.ActionSkip || d.Reason == "" || d.Error != "" {
+		t.Errorf("pausa registrada incorretamente: %+v", d)
+	}
+}
+
+// fakeBroker executa as ordens a 50000, após rejeitar as primeiras com os erros de rejects.
+// As primeiras lost ordens executadas têm a resposta perdida (lostErr, ou timeout se
+// nil), e as consultas pelo identificador do cliente falham com lookupErr se definido.
+type fakeBroker struct {
+	fakeAccount
+	rejects   []error
+	lost      int
+	lostErr   error
+	lookupErr error
+	orders    []decimal.Decimal          // Quantidades das ordens recebidas
+	placed    map[string]*exchange.Order // Ordens executadas pelo identificador do cliente
+}
+
+// errTimeout simula uma requisição sem resposta, que pode ter chegado à corretora.
+var errTimeout = &url.Error{Op: "Post", URL: "https://api.binance.com/api/v3/order", Err: context.DeadlineExceeded}
+
+func (f *fakeBroker) PlaceOrder(ctx context.Context, req exchange.OrderRequest) (*exchange.Order, error) {
+	f.orders = append(f.orders, req.Quantity)
+	if len(f.orders) <= len(f.rejects) {
+		return nil, f.rejects[len(f.orders)-1]
+	}
+	order := &exchange.Order{
+		Symbol:              req.Symbol,
+		OrderID:             int64(len(f.orders)),
+		ClientOrderID:       req.ClientOrderID,
+		Side:                req.Side,
+		Status:              "FILLED",
+		ExecutedQty:         req.Quantity,
+		CummulativeQuoteQty: req.Quantity.Mul(dec("50000")),
+	}
+	if f.placed == nil {
+		f.placed = make(map[string]*exchange.Order)
+	}
+	f.placed[req.ClientOrderID] = order
+	if len(f.placed) <= f.lost {
+		if f.lostErr != nil {
+			return nil, f.lostErr
+		}
+		return nil, errTimeout
+	}
+	return order, nil
+}
+
+func (f *fakeBroker) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*exchange.Order, error) {
+	if f.lookupErr != nil {
+		return nil, f.lookupErr
+	}
+	if order, ok := f.placed[clientOrderID]; ok {
+		return order, nil
+	}
+	return nil, &exchange.APIError{StatusCode: 400, Code: -2013, Msg: "Order does not exist."}
+}
+
+func TestTrader_PlaceOrder(t *testing.T) {
+	var (
+		timestamp    = &exchange.APIError{StatusCode: 400, Code: -1021, Msg: "Timestamp for this request is outside of the recvWindow."}
+		insufficient = &exchange.APIError{StatusCode: 400, Code: -2010, Msg: "Account has insufficient balance for requested action."}
+		filter       = &exchange.APIError{StatusCode: 400, Code: -1013, Msg: "Filter failure: NOTIONAL"}
+		signature    = &exchange.APIError{StatusCode: 400, Code: -1022, Msg: "Signature for this request is not valid."}
+		unknown      = &exchange.APIError{StatusCode: 400, Code: -1007, Msg: "Timeout waiting for response from backend server. Send status unknown; execution status unknown."}
+	)
+
+	tests := []struct {
+		name       string
+		rejects    []error
+		resized    string // Quantidade recalculada após a rejeição
+		wantOrders []string
+		wantErr    error
+	}{
+		{"Should resend after a rejected timestamp", []error{timestamp}, "0.001", []string{"0.001", "0.001"}, nil},
+		{"Should resend a smaller order after insufficient balance", []error{insufficient}, "0.0009", []string{"0.001", "0.0009"}, nil},
+		{"Should resend a smaller order after a filter failure", []error{filter}, "0.0008", []string{"0.001", "0.0008"}, nil},
+		{"Should not resend when the quantity does not shrink", []error{insufficient}, "0.001", []string{"0.001"}, exchange.ErrInsufficientBalance},
+		{"Should not resend after an invalid signature", []error{signature}, "0.0009", []string{"0.001"}, exchange.ErrInvalidSignature},
+		{"Should resend only once", []error{timestamp, timestamp}, "0.001", []string{"0.001", "0.001"}, exchange.ErrInvalidTimestamp},
+		{"Should resend after a timeout when the order did not arrive", []error{errTimeout}, "0.0009", []string{"0.001", "0.001"}, nil},
+		{"Should resend after an unknown status when the order did not arrive", []error{unknown}, "0.0009", []string{"0.001", "0.001"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := &fakeBroker{rejects: tt.rejects}
+			tr := setupTrader(t, broker)
+			resize := func(context.Context) (decimal.Decimal, error) { return dec(tt.resized), nil }
+
+			order, err := tr.placeOrder(context.Background(), log.New(io.Discard, "", 0), exchange.SideBuy, dec("0.001"), dec("50000"), risk.ReasonStrategy, "cb_BTCUSDT_b_1", resize)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || order != nil {
+					t.Errorf("placeOrder() = %+v, %v; esperado erro %v", order, err, tt.wantErr)
+				}
+			} else if err != nil || order == nil {
+				t.Errorf("placeOrder() = %+v, %v; esperada ordem executada", order, err)
+			}
+
+			if len(broker.orders) != len(tt.wantOrders) {
+				t.Fatalf("%d ordens enviadas, esperado %d", len(broker.orders), len(tt.wantOrders))
+			}
+			for i, want := range tt.wantOrders {
+				if !broker.orders[i].Equal(dec(want)) {
+					t.Errorf("ordem %d com quantidade %s, esperado %s", i+1, broker.orders[i], want)
+				}
+			}
+		})
+	}
+}
+
+// fakeWallet acrescenta a fakeAccount o saldo de 0.002 BTC.
+type fakeWallet struct {
+	fakeAccount
+}
+
+func (f *fakeWallet) GetBalances(ctx context.Context) ([]exchange.Balance, error) {
+	return []exchange.Balance{{Asset: "BTC", Free: dec("0.002")}}, nil
+}
+
+func TestTrader_IgnoresPendingBuy(t *testing.T) {
+	tr := setupTrader(t, &fakeWallet{})
+	ctx := context.Background()
+
+	// Posição registrada antes da quantidade e do preço de entrada, com uma compra
+	// posterior ainda sem resultado da corretora
+	for _, o := range []database.Order{
+		{Symbol: "BTCUSDT", Side: exchange.SideBuy, Quantity: dec("0.002"), Price: dec("60000"), Mode: config.ModePaper,
+			Reason: "strategy", ExchangeOrderID: 1, Status: "FILLED", ExecutedQty: dec("0.002"), CummulativeQuoteQty: dec("120")},
+		{Symbol: "BTCUSDT", Side: exchange.SideBuy, Quantity: dec("0.003"), Price: dec("70000"), Mode: config.ModePaper,
+			Reason: "strategy", ClientOrderID: "cb_BTCUSDT_b_1", Status: database.OrderStatusPending},
+	} {
+		if err := store.SaveOrder(ctx, o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.OpenPosition(ctx, database.Position{Symbol: "BTCUSDT", Mode: config.ModePaper}); err != nil {
+		t.Fatal(err)
+	}
+	position, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModePaper)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if quantity, err := tr.sellQuantity(ctx); err != nil || !quantity.Equal(dec("0.002")) {
+		t.Errorf("sellQuantity() = %s, %v; esperado 0.002 da compra executada", quantity, err)
+	}
+	if pos := tr.loadRiskPosition(ctx, log.New(io.Discard, "", 0), position); pos == nil || pos.EntryPrice != 60000 {
+		t.Errorf("loadRiskPosition() = %+v, esperado o preço de entrada da compra executada", pos)
+	}
+}
+
+// holdStrategy mantém a posição aberta: nunca indica a saída.
+type holdStrategy struct {
+	strategy.Strategy
+}
+
+func (holdStrategy) ShouldExit(prices []float64) (bool, error) {
+	return false, nil
+}
+
+func TestTick_KeepsTrailingStopHigh(t *testing.T) {
+	tr := setupTrader(t, &fakeMarket{})
+	tr.strategy = holdStrategy{tr.strategy}
+	riskRules = risk.Rules{TrailingStopPercent: 10}
+	t.Cleanup(func() { riskRules = risk.Rules{} })
+	ctx := context.Background()
+
+	if err := store.OpenPosition(ctx, database.Position{Symbol: "BTCUSDT", Mode: config.ModePaper, EntryPrice: dec("100"), Quantity: dec("0.5")}); err != nil {
+		t.Fatal(err)
+	}
+	tr.IsOpened = true
+
+	// O último candle fecha a 120, a máxima desde a entrada
+	tr.Tick(ctx, io.Discard)
+	if d := lastDecision(t); d.Action != database.ActionHold {
+		t.Fatalf("avaliação registrada incorretamente: %+v", d)
+	}
+	position, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModePaper)
+	if err != nil || position == nil || !position.HighestPrice.Equal(dec("120")) {
+		t.Fatalf("maior preço não registrado: %+v, erro: %v", position, err)
+	}
+
+	// Após o reinício, o trailing stop continua medido a partir da máxima
+	restarted, err := newTrader(ctx, "BTCUSDT", strategy.Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pos := restarted.loadRiskPosition(ctx, log.New(io.Discard, "", 0), position); pos == nil || pos.EntryPrice != 100 || pos.HighestPrice != 120 {
+		t.Errorf("loadRiskPosition() = %+v, esperada a máxima registrada", pos)
+	}
+}
+
+func TestTick_HaltsOnRejectedCredentials(t *testing.T) {
+	tr := setupTrader(t, &fakeMarket{err: &exchange.APIError{StatusCode: 401, Code: -2015, Msg: "Invalid API-key, IP, or permissions for action."}})
+	tr.Tick(context.Background(), io.Discard)
+	if tr.rejected == nil || tr.failures != 0 {
+		t.Fatalf("par não suspenso pelas credenciais rejeitadas: rejected=%v failures=%d", tr.rejected, tr.failures)
+	}
+
+	// O par suspenso não é mais avaliado, mesmo que a corretora volte a responder
+	client = &fakeMarket{}
+	tr.Tick(context.Background(), io.Discard)
+	if d := lastDecision(t); d.Action != database.ActionSkip || d.Reason != "suspenso por credenciais rejeitadas" {
+		t.Errorf("suspensão registrada incorretamente: %+v", d)
+	}
+}
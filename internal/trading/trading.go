@@ -1,131 +1,113 @@
 package trading
 
 import (
-	"crypto/hmac"
-	"crypto/sha256"
-	"encoding/hex"
-	"encoding/json"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log"
-	"net/http"
-	"net/url"
-	"strings"
+	"os"
+	"sync"
 	"time"
 
 	"github.com/brunossouza/crypto_bot/internal/config"
 	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/brunossouza/crypto_bot/internal/marketdata"
+	"github.com/brunossouza/crypto_bot/internal/risk"
+	"github.com/brunossouza/crypto_bot/internal/sizing"
 	"github.com/brunossouza/crypto_bot/internal/strategy"
-	"github.com/brunossouza/crypto_bot/internal/utils"
+	"github.com/shopspring/decimal"
 )
 
 var (
-	cfg              *config.Config
-	IsOpened         bool = false
-	combinedStrategy *strategy.CombinedStrategy
+	cfg       *config.Config
+	client    exchange.Exchange
+	store     database.Store
+	riskRules risk.Rules
+	traders   []*Trader
 )
 
 // Initialize define as configurações para o pacote de trading
 // Parâmetros:
 // - c: ponteiro para a estrutura de configuração contendo as credenciais da API e parâmetros do bot
-// O método armazena a configuração em uma variável global para uso em todo o pacote
-func Initialize(c *config.Config) {
+// - ex: corretora usada para obter dados de mercado e enviar ordens
+// - s: armazenamento das ordens, posições e operações encerradas
+// - ctx: contexto usado na carga das posições
+// O método armazena a configuração, a corretora e o armazenamento em variáveis globais para uso
+// em todo o pacote e cria um Trader independente para cada par configurado em SYMBOLS.
+func Initialize(ctx context.Context, c *config.Config, ex exchange.Exchange, s database.Store) {
 	cfg = c
-	if err := database.Initialize(); err != nil {
-		log.Fatal("Erro ao inicializar banco de dados:", err)
-	}
-	// Load last status from the database
-	status, err := database.GetPosition(cfg.Symbol)
+	client = ex
+	store = s
+
+	params, err := strategy.ParseParams(cfg.StrategyParams)
 	if err != nil {
-		log.Println("Erro ao carregar status da posição:", err)
+		log.Fatal("Erro ao ler STRATEGY_PARAMS:", err)
+	}
+	if cfg.SizingMethod == sizing.MethodFixedRisk && cfg.StopLossPercent <= 0 {
+		log.Fatal("O dimensionamento fixed_risk exige STOP_LOSS_PERCENT maior que zero")
+	}
+
+	riskRules = risk.Rules{
+		StopLossPercent:     cfg.StopLossPercent,
+		TakeProfitPercent:   cfg.TakeProfitPercent,
+		TrailingStopPercent: cfg.TrailingStopPercent,
+	}
+
+	traders = nil
+	for _, symbol := range cfg.Symbols {
+		t, err := newTrader(ctx, symbol, params)
+		if err != nil {
+			log.Fatalf("Erro ao inicializar %s: %v", symbol, err)
+		}
+		traders = append(traders, t)
 	}
-	IsOpened = status
-
-	// Initialize Combined strategy
-	combinedStrategy = strategy.NewCombinedStrategy(
-		14,  // RSI period
-		20,  // SMA period
-		70,  // Overbought level
-		30,  // Oversold level
-		1.0, // Trend strength threshold (1%)
-	)
-}
 
-type Candlestick struct {
-	OpenTime                 int64   // Horário de abertura do candle em milissegundos
-	Open                     float64 // Preço de abertura do candle
-	High                     float64 // Preço mais alto durante o período do candle
-	Low                      float64 // Preço mais baixo durante o período do candle
-	Close                    float64 // Preço de fechamento (ou último preço) do candle
-	Volume                   float64 // Volume total negociado durante o período do candle
-	CloseTime                int64   // Horário de fechamento do candle em milissegundos
-	QuoteAssetVolume         float64 // Volume total do ativo de cotação durante o período
-	NumberOfTrades           int64   // Número de negociações durante o período
-	TakerBuyBaseAssetVolume  float64 // Volume de compra do ativo base pelos takers
-	TakerBuyQuoteAssetVolume float64 // Volume de compra do ativo de cotação pelos takers
-	Ignore                   float64 // Campo não utilizado, ignorar
+	// A estratégia precisa de candles suficientes para calcular seus indicadores
+	if len(traders) > 0 {
+		if minBars := traders[0].strategy.MinBars(); cfg.Lookback < minBars {
+			log.Fatalf("LOOKBACK=%d é menor que o mínimo de %d candles exigido pela estratégia %s",
+				cfg.Lookback, minBars, cfg.Strategy)
+		}
+	}
 }
 
+// Candlestick representa um candle retornado pela corretora.
+// Mantido como alias para que o pacote de trading continue expondo o tipo.
+type Candlestick = exchange.Candlestick
+
 // GetCandlesticks obtém os dados históricos de preços do par de moedas especificado
 // Parâmetros:
 // - symbol: par de moedas para obter dados (ex: BTCUSDT)
 // - interval: intervalo de tempo entre cada candle (ex: "15m", "1h", "4h")
 // - limit: quantidade máxima de candles a serem retornados
 //
-// O método:
-// 1. Faz uma requisição GET para a API da Binance
-// 2. Processa a resposta JSON
-// 3. Converte os dados para a estrutura Candlestick
+// Com o stream do par conectado (ver StartStreams), os candles são lidos da memória;
+// caso contrário, são obtidos através da corretora configurada em Initialize e
+// a requisição é abortada se ctx for cancelado. Os candles já fechados são
+// gravados no histórico local; uma falha na gravação não impede o retorno.
 //
 // Retorna:
 // - []Candlestick: slice contendo os dados históricos formatados
-func GetCandlesticks(symbol string, interval string, limit int) []Candlestick {
-	// Cria uma nova requisição
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/api/v3/klines?symbol=%s&interval=%s&limit=%d", cfg.ApiURL, symbol, interval, limit), nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Envia a requisição
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatal(err)
-	}
-	defer resp.Body.Close()
-
-	// Lê o corpo da resposta
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// Processa a resposta JSON
-	var rawData [][]interface{}
-	err = json.Unmarshal(body, &rawData)
-	if err != nil {
-		log.Fatal(err)
+// - error: erro na requisição ou em uma resposta malformada
+func GetCandlesticks(ctx context.Context, symbol string, interval string, limit int) ([]Candlestick, error) {
+	candlesticks := streamCandles(symbol, interval, limit)
+	if candlesticks == nil {
+		var err error
+		candlesticks, err = client.GetKlines(ctx, symbol, interval, limit)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao obter candles de %s: %w", symbol, err)
+		}
 	}
 
-	candlesticks := make([]Candlestick, len(rawData))
-	for i, raw := range rawData {
-		candlesticks[i] = Candlestick{
-			OpenTime:                 int64(raw[0].(float64)),
-			Open:                     utils.ParseFloat(raw[1].(string)),
-			High:                     utils.ParseFloat(raw[2].(string)),
-			Low:                      utils.ParseFloat(raw[3].(string)),
-			Close:                    utils.ParseFloat(raw[4].(string)),
-			Volume:                   utils.ParseFloat(raw[5].(string)),
-			CloseTime:                int64(raw[6].(float64)),
-			QuoteAssetVolume:         utils.ParseFloat(raw[7].(string)),
-			NumberOfTrades:           int64(raw[8].(float64)),
-			TakerBuyBaseAssetVolume:  utils.ParseFloat(raw[9].(string)),
-			TakerBuyQuoteAssetVolume: utils.ParseFloat(raw[10].(string)),
-			Ignore:                   utils.ParseFloat(raw[11].(string)),
-		}
+	closed := closedCandles(candlesticks, time.Now(), len(candlesticks))
+	if err := store.SaveCandles(ctx, marketdata.CandleRecords(symbol, interval, closed)); err != nil {
+		log.Printf("Erro ao gravar o histórico de candles de %s: %v", symbol, err)
 	}
 
-	return candlesticks
+	return candlesticks, nil
 }
 
 // NewOrder cria uma nova ordem de compra ou venda no mercado
@@ -134,132 +116,213 @@ func GetCandlesticks(symbol string, interval string, limit int) []Candlestick {
 // - quantity: quantidade do ativo a ser negociada
 // - side: direção da ordem ("BUY" para compra, "SELL" para venda)
 // - price: preço atual do ativo no momento da ordem
+// - reason: regra que motivou a ordem, registrada junto com ela no banco
+// - clientOrderID: identificador da ordem pretendida (ver clientOrderID), enviado como newClientOrderId
+//
+// Nenhuma ordem é enviada se ctx já estiver cancelado. Depois do envio, o
+// cancelamento de ctx é ignorado: a resposta da corretora e o registro no
+// banco são sempre aguardados, para que uma ordem aceita nunca fique sem registro.
+//
+// A ordem é registrada com o estado PENDING antes do envio. Se o envio falhar sem
+// uma rejeição definitiva da corretora (ex: timeout), a ordem é consultada pelo
+// clientOrderID para descobrir se foi aceita; enquanto o resultado for desconhecido,
+// nenhuma nova ordem do par é enviada (ver resolvePending).
+//
+// A ordem é registrada com o preço médio, a quantidade executada, as taxas e as
+// execuções informadas pela corretora; price é usado apenas como referência para
+// os filtros do par e quando a corretora não informa as execuções.
 //
 // Retorna:
-// - error: nil em caso de sucesso, ou erro em caso de falha
-func NewOrder(symbol string, quantity float64, side string, price float64) error {
-	// Prepara os parâmetros da ordem
-	params := url.Values{}
-	params.Add("symbol", symbol)
-	params.Add("quantity", fmt.Sprintf("%f", quantity))
-	params.Add("side", side)
-	params.Add("type", "MARKET")
-	params.Add("timestamp", fmt.Sprintf("%d", time.Now().UnixMilli()))
-
-	// Gera a assinatura HMAC SHA256
-	mac := hmac.New(sha256.New, []byte(cfg.ApiSecret))
-	mac.Write([]byte(params.Encode()))
-	signature := hex.EncodeToString(mac.Sum(nil))
-	params.Add("signature", signature)
-
-	// Cria a requisição
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/api/v3/order", cfg.ApiURL), strings.NewReader(params.Encode()))
-	if err != nil {
-		return err
+// - *exchange.Order: estado da ordem informado pela corretora
+// - error: nil em caso de sucesso, ErrNotFilled se nada foi executado, ErrNotSent
+// se a ordem não chegou à corretora, ErrUncertainOrder se o resultado é desconhecido,
+// ou erro em caso de falha
+func NewOrder(ctx context.Context, symbol string, quantity decimal.Decimal, side string, price decimal.Decimal, reason risk.Reason, clientOrderID string) (*exchange.Order, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("ordem não enviada: %w", err)
 	}
+	ctx = context.WithoutCancel(ctx)
 
-	// Adiciona os headers necessários
-	req.Header.Add("X-MBX-APIKEY", cfg.ApiKey)
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	// Os eventos do user data stream sobre esta ordem aguardam o seu registro
+	defer lockOrders(symbol)()
 
-	// Envia a requisição
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	if err := resolvePending(ctx, symbol); err != nil {
+		return nil, err
+	}
+	record, err := savePending(ctx, database.Order{
+		Symbol:        symbol,
+		Side:          side,
+		Quantity:      quantity,
+		Price:         price,
+		Mode:          cfg.Mode,
+		Reason:        string(reason),
+		ClientOrderID: clientOrderID,
+	})
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	// Lê a resposta
-	body, err := io.ReadAll(resp.Body)
+	order, err := client.PlaceOrder(ctx, exchange.OrderRequest{
+		Symbol:        symbol,
+		Side:          side,
+		Type:          exchange.OrderTypeMarket,
+		Quantity:      quantity,
+		Price:         price,
+		ClientOrderID: clientOrderID,
+	})
 	if err != nil {
-		return err
+		if exchange.IsDefiniteRejection(err) {
+			return nil, markNotSent(ctx, record, err)
+		}
+		// A ordem pode ter sido aceita apesar da falha
+		if order, err = resolveOrder(ctx, record, err); order == nil {
+			return nil, err
+		}
 	}
 
-	// Se o status não for 200, retorna erro
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("erro na criação da ordem: %s", string(body))
-	}
+	return order, recordOrder(ctx, record, order, price)
+}
 
-	// Se a ordem foi criada com sucesso, salva no banco
-	if err := database.SaveOrder(symbol, side, quantity, price); err != nil {
+// recordOrder atualiza o registro da ordem com o resultado informado pela corretora
+// e, se algo foi executado, a posição do par.
+// Retorna ErrNotFilled se nada foi executado.
+func recordOrder(ctx context.Context, record *database.Order, order *exchange.Order, price decimal.Decimal) error {
+	commission, commissionAsset := order.Commission()
+	record.Price = fillPrice(order, price)
+	record.ExchangeOrderID = order.OrderID
+	record.Status = order.Status
+	record.ExecutedQty = order.ExecutedQty
+	record.CummulativeQuoteQty = order.CummulativeQuoteQty
+	record.Commission = commission
+	record.CommissionAsset = commissionAsset
+	record.Fills = nil
+	for _, f := range order.Fills {
+		record.Fills = append(record.Fills, database.Fill{
+			TradeID:         f.TradeID,
+			Price:           f.Price,
+			Quantity:        f.Qty,
+			Commission:      f.Commission,
+			CommissionAsset: f.CommissionAsset,
+		})
+	}
+	if err := store.UpdateOrder(ctx, *record); err != nil {
 		return fmt.Errorf("erro ao salvar ordem: %v", err)
 	}
 
-	// Atualiza a posição no banco
-	isOpened := side == "BUY"
-	if err := database.UpdatePosition(symbol, isOpened); err != nil {
-		return fmt.Errorf("erro ao atualizar posição: %v", err)
+	// Uma ordem aceita sem nenhuma execução (ex: EXPIRED) não altera a posição
+	if !order.ExecutedQty.IsPositive() {
+		return fmt.Errorf("%w: ordem %d com status %s", ErrNotFilled, order.OrderID, order.Status)
 	}
 
-	fmt.Printf("Ordem criada com sucesso: %s\n", string(body))
+	// Atualiza a posição no banco: a compra registra a entrada e a venda
+	// encerra a posição, registrando o resultado realizado no histórico de trades
+	if err := updatePosition(ctx, record.Symbol, record.Side, order, price, risk.Reason(record.Reason)); err != nil {
+		return fmt.Errorf("erro ao atualizar posição: %v", err)
+	}
 	return nil
 }
 
+// updatePosition abre ou encerra a posição do par conforme a ordem executada.
+func updatePosition(ctx context.Context, symbol, side string, order *exchange.Order, price decimal.Decimal, reason risk.Reason) error {
+	info, err := client.GetExchangeInfo(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	if side == exchange.SideBuy {
+		return store.OpenPosition(ctx, positionFromBuy(symbol, order, info, price))
+	}
+
+	pos, err := store.GetOpenPosition(ctx, symbol, cfg.Mode)
+	if err != nil {
+		return err
+	}
+	trade := closeTrade(pos, fillPrice(order, price), order.ExecutedQty, orderFees(order, info), string(reason))
+	return store.ClosePosition(ctx, symbol, cfg.Mode, trade)
+}
+
+// ErrNotFilled indica que a ordem foi aceita pela corretora, mas nada foi executado.
+var ErrNotFilled = errors.New("ordem não executada")
+
+// ErrNotSent indica que a ordem certamente não foi aceita pela corretora e pode ser reenviada.
+var ErrNotSent = errors.New("ordem não recebida pela corretora")
+
+// ErrUncertainOrder indica uma ordem cujo resultado não pôde ser confirmado na corretora.
+var ErrUncertainOrder = errors.New("resultado da ordem desconhecido")
+
+// fillPrice retorna o preço médio de execução da ordem, ou o preço de
+// referência ref se a corretora não informou nenhuma execução.
+func fillPrice(order *exchange.Order, ref decimal.Decimal) decimal.Decimal {
+	if p := order.AvgPrice(); p.IsPositive() {
+		return p
+	}
+	return ref
+}
+
 // StartTrading executa a lógica principal de trading do bot
-// O método:
-// 1. Obtém os dados mais recentes dos candles
-// 2. Extrai o último preço e histórico de preços
-// 3. Calcula o RSI atual
-// 4. Atualiza a interface com informações do mercado
-// 5. Executa a lógica de trading baseada no RSI:
-//   - Compra quando RSI < 30 (sobrevenda)
-//   - Vende quando RSI > 70 (sobrecompra)
+// Deve ser chamado logo após o fechamento de cada candle de INTERVAL (ver exchange.NextCandleClose),
+// para que as decisões sejam tomadas uma vez por candle fechado.
+// Cada par configurado é avaliado concorrentemente pelo seu Trader (ver Trader.Tick).
+// Se ctx for cancelado durante a avaliação, as consultas pendentes são abortadas,
+// mas ordens já enviadas terminam de ser registradas antes do retorno.
+// Ao final, a tela é limpa e o relatório de cada par é exibido na ordem de SYMBOLS.
 //
 // Comportamento:
-// - Mantém controle do estado da posição através da variável IsOpened
-// - Executa ordens de mercado com quantidade fixa de 0.001
+// - Mantém o estado da posição de cada par de forma independente
+// - Reconcilia a posição de cada par com a conta na corretora antes da primeira avaliação
+// - Ignora a avaliação de um par que falhar, sem interromper os demais
+// - Pausa o par e emite um alerta após MAX_CONSECUTIVE_FAILURES falhas seguidas
+// - Reenvia uma vez as ordens rejeitadas pelo timestamp, ou por saldo e filtros com a quantidade recalculada
+// - Suspende o par com um alerta se a corretora rejeitar as credenciais da API
+// - Consulta pelo identificador do cliente as ordens enviadas sem resposta antes de um novo envio
+// - Executa ordens de mercado com quantidade definida pelo dimensionamento configurado
 // - Exibe mensagens de status no console
-func StartTrading() {
-	// Obtém os dados dos candles
-	candlesticks := GetCandlesticks(cfg.Symbol, "15m", 100)
-
-	// Obtém o último preço
-	lastPrice := candlesticks[len(candlesticks)-1].Close
+func StartTrading(ctx context.Context) {
+	reports := make([]bytes.Buffer, len(traders))
 
-	var prices []float64
-	for _, c := range candlesticks {
-		prices = append(prices, c.Close)
+	var wg sync.WaitGroup
+	for i, t := range traders {
+		wg.Add(1)
+		go func(i int, t *Trader) {
+			defer wg.Done()
+			t.Tick(ctx, &reports[i])
+		}(i, t)
 	}
-
-	// Calcula o RSI e SMA
-	rsi, sma := combinedStrategy.GetIndicators(prices)
+	wg.Wait()
 
 	// Limpa a tela
 	fmt.Print("\033[H\033[2J")
 	fmt.Println("API URL:", cfg.ApiURL)
-	fmt.Println("Ativo:", cfg.Symbol)
-	fmt.Printf("Último preço: %.2f\n", lastPrice)
-	fmt.Printf("RSI: %.2f\n", rsi)
-	fmt.Printf("SMA: %.2f\n", sma)
+	fmt.Println("Modo:", cfg.Mode)
 	fmt.Println("Período:", cfg.Period)
-	fmt.Println("Aberto:", IsOpened)
+	fmt.Println("Intervalo:", cfg.Interval)
 	fmt.Println("")
-
-	// Obtém o estado da posição do banco
-	isOpened, err := database.GetPosition(cfg.Symbol)
-	if err != nil {
-		log.Printf("Erro ao obter posição: %v", err)
-		return
+	for i := range reports {
+		fmt.Print(reports[i].String())
+		fmt.Println("")
 	}
+}
 
-	if combinedStrategy.ShouldEnter(prices) && !isOpened {
-		fmt.Println("sobrevendido, momento de comprar")
-		if err := NewOrder(cfg.Symbol, 0.001, "BUY", lastPrice); err != nil {
-			log.Println(err)
-			IsOpened = false
-		} else {
-			IsOpened = true
-		}
-	} else if combinedStrategy.ShouldExit(prices) && isOpened {
-		fmt.Println("sobrecomprado, momento de vender")
-		if err := NewOrder(cfg.Symbol, 0.001, "SELL", lastPrice); err != nil {
-			log.Println(err)
-			IsOpened = true
-		} else {
-			IsOpened = false
-		}
-	} else {
-		fmt.Println("Aguardando oportunidades...")
+// alertLogger registra os alertas também na saída de erro, que não é
+// apagada junto com a tela e pode ser redirecionada para um arquivo.
+var alertLogger = log.New(os.Stderr, "ALERTA: ", log.LstdFlags)
+
+// alert emite um alerta no relatório do par e na saída de erro.
+func alert(out io.Writer, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	fmt.Fprintln(out, "ALERTA:", msg)
+	alertLogger.Println(msg)
+}
+
+// closedCandles remove do final da lista os candles ainda em formação no instante now,
+// mantendo no máximo limit candles fechados.
+func closedCandles(candles []Candlestick, now time.Time, limit int) []Candlestick {
+	nowMs := now.UnixMilli()
+	for len(candles) > 0 && candles[len(candles)-1].CloseTime >= nowMs {
+		candles = candles[:len(candles)-1]
+	}
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
 	}
+	return candles
 }
@@ -0,0 +1,262 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+	"github.com/shopspring/decimal"
+)
+
+// externalReason identifica no banco as ordens enviadas fora do bot e registradas
+// a partir do user data stream.
+const externalReason = "external"
+
+// orderLocks serializa, por par, o envio e o registro das ordens (NewOrder) com o
+// processamento dos eventos do user data stream, para que o evento de uma ordem
+// enviada pelo bot só seja tratado depois que ela estiver registrada no banco.
+var orderLocks sync.Map
+
+// lockOrders bloqueia o registro de ordens do par e retorna a função que o libera.
+func lockOrders(symbol string) func() {
+	mu, _ := orderLocks.LoadOrStore(symbol, &sync.Mutex{})
+	m := mu.(*sync.Mutex)
+	m.Lock()
+	return m.Unlock
+}
+
+// HandleUserEvents aplica ao banco os eventos do user data stream recebidos em
+// events (ver exchange.UserStream), até o canal ser fechado
+// Comportamento:
+//   - Cada nova execução de uma ordem dos pares de SYMBOLS atualiza o registro da
+//     ordem e a posição: compras abrem ou aumentam a posição e vendas a encerram,
+//     registrando o resultado no histórico de trades
+//   - Ordens enviadas fora do bot (ex: pelo site da corretora) são registradas com
+//     o motivo "external" e alteram a posição da mesma forma
+//   - Ordens do bot cujo envio ficou sem resposta (registradas com o estado
+//     PENDING) são identificadas pelo clientOrderId e atualizadas com o resultado
+//   - Cancelamentos e expirações atualizam apenas o estado da ordem
+//   - Alterações de saldo do ativo base de um par agendam a reconciliação do par
+//     na próxima avaliação
+//
+// Falhas são registradas no log sem interromper o processamento dos eventos seguintes.
+func HandleUserEvents(ctx context.Context, events <-chan exchange.UserEvent) {
+	for ev := range events {
+		if r := ev.Execution; r != nil {
+			if err := applyExecution(ctx, r); err != nil {
+				log.Printf("Erro ao aplicar a atualização da ordem %d de %s: %v", r.OrderID, r.Symbol, err)
+			}
+		}
+		if ev.Account != nil {
+			requestReconcile(ctx, ev.Account)
+		}
+	}
+}
+
+// applyExecution atualiza a ordem e a posição do par conforme o executionReport r.
+// Apenas a parte ainda não registrada da execução acumulada é aplicada, então eventos
+// de ordens já registradas pelo bot com o resultado final não alteram o banco.
+func applyExecution(ctx context.Context, r *exchange.ExecutionReport) error {
+	if findTrader(r.Symbol) == nil {
+		return nil
+	}
+	defer lockOrders(r.Symbol)()
+
+	order, err := store.GetOrderByExchangeID(ctx, r.Symbol, cfg.Mode, r.OrderID)
+	if err != nil {
+		return err
+	}
+	if order == nil {
+		if order, err = adoptPendingOrder(ctx, r); err != nil {
+			return err
+		}
+	}
+	if order == nil {
+		if r.ExecutionType == exchange.ExecutionRejected {
+			return nil
+		}
+		if order, err = saveExternalOrder(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	quantity := r.ExecutedQty.Sub(order.ExecutedQty)
+	if !quantity.IsPositive() {
+		// Sem nova execução, apenas o encerramento da ordem é registrado; eventos
+		// anteriores ao resultado já registrado (ex: NEW) são ignorados
+		if r.Status == order.Status || !r.ExecutedQty.Equal(order.ExecutedQty) || r.ExecutionType == exchange.ExecutionNew {
+			return nil
+		}
+		order.Status = r.Status
+		return store.UpdateOrder(ctx, *order)
+	}
+
+	quoteQty := r.CummulativeQuoteQty.Sub(order.CummulativeQuoteQty)
+	order.Status = r.Status
+	order.ExecutedQty = r.ExecutedQty
+	order.CummulativeQuoteQty = r.CummulativeQuoteQty
+	order.Price = r.CummulativeQuoteQty.Div(r.ExecutedQty)
+	if r.CommissionAsset != "" && (order.CommissionAsset == "" || order.CommissionAsset == r.CommissionAsset) {
+		order.Commission = order.Commission.Add(r.Commission)
+		order.CommissionAsset = r.CommissionAsset
+	}
+	if r.ExecutionType == exchange.ExecutionTrade {
+		order.Fills = []database.Fill{{
+			TradeID:         r.TradeID,
+			Price:           r.LastPrice,
+			Quantity:        r.LastQty,
+			Commission:      r.Commission,
+			CommissionAsset: r.CommissionAsset,
+		}}
+	}
+	if err := store.UpdateOrder(ctx, *order); err != nil {
+		return err
+	}
+
+	return applyFill(ctx, r, order, quantity, quoteQty)
+}
+
+// adoptPendingOrder associa a ordem do executionReport r ao registro de uma ordem do
+// bot cujo envio ficou sem resposta (ver NewOrder), identificado pelo clientOrderId,
+// e retorna o registro atualizado, ou nil se não houver ordem pendente com o identificador.
+func adoptPendingOrder(ctx context.Context, r *exchange.ExecutionReport) (*database.Order, error) {
+	order, err := store.GetOrderByClientID(ctx, r.Symbol, cfg.Mode, r.ClientOrderID)
+	if err != nil || order == nil || order.Status != database.OrderStatusPending {
+		return nil, err
+	}
+	order.ExchangeOrderID = r.OrderID
+	order.Status = exchange.ExecutionNew
+	if r.ExecutionType == exchange.ExecutionRejected {
+		order.Status = database.OrderStatusRejected
+	}
+	if err := store.UpdateOrder(ctx, *order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// saveExternalOrder registra uma ordem enviada fora do bot, ainda sem execuções,
+// e retorna o registro gravado.
+func saveExternalOrder(ctx context.Context, r *exchange.ExecutionReport) (*database.Order, error) {
+	err := store.SaveOrder(ctx, database.Order{
+		Symbol:          r.Symbol,
+		Side:            r.Side,
+		Quantity:        r.OrigQty,
+		Price:           r.LastPrice,
+		Mode:            cfg.Mode,
+		Reason:          externalReason,
+		ExchangeOrderID: r.OrderID,
+		ClientOrderID:   r.ClientOrderID,
+		Status:          exchange.ExecutionNew,
+	})
+	if err != nil {
+		return nil, err
+	}
+	order, err := store.GetOrderByExchangeID(ctx, r.Symbol, cfg.Mode, r.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil {
+		return nil, fmt.Errorf("ordem %d não encontrada após o registro", r.OrderID)
+	}
+	return order, nil
+}
+
+// applyFill altera a posição do par pela execução de quantity (valor quoteQty) da ordem
+// registrada order, já atualizada com o executionReport r
+// Uma compra abre a posição ou a aumenta, com o preço de entrada médio ponderado; como
+// em positionFromBuy, o preço de entrada é o preço bruto das execuções e as taxas ficam
+// em Fees. Como OpenPosition, o horário de abertura passa a ser o da última compra.
+// Uma venda reduz a posição aberta e, quando nada resta ou a ordem é totalmente
+// executada, a encerra, registrando o resultado da ordem inteira com o seu motivo.
+func applyFill(ctx context.Context, r *exchange.ExecutionReport, order *database.Order, quantity, quoteQty decimal.Decimal) error {
+	info, err := client.GetExchangeInfo(ctx, r.Symbol)
+	if err != nil {
+		return err
+	}
+	pos, err := store.GetOpenPosition(ctx, r.Symbol, cfg.Mode)
+	if err != nil {
+		return err
+	}
+
+	if r.Side == exchange.SideBuy {
+		price := quoteQty.Div(quantity)
+		fee := feeInQuote(r.Commission, r.CommissionAsset, r.LastPrice, info)
+		received := quantity
+		if r.CommissionAsset == info.BaseAsset {
+			received = received.Sub(r.Commission)
+		}
+		next := database.Position{
+			Symbol:     r.Symbol,
+			Mode:       cfg.Mode,
+			IsOpened:   true,
+			EntryPrice: price,
+			Quantity:   received,
+			Fees:       fee,
+		}
+		if pos != nil {
+			next.Quantity = pos.Quantity.Add(received)
+			next.Fees = pos.Fees.Add(fee)
+			if pos.EntryPrice.IsPositive() && next.Quantity.IsPositive() {
+				next.EntryPrice = pos.EntryPrice.Mul(pos.Quantity).Add(price.Mul(received)).Div(next.Quantity)
+			}
+		}
+		return store.OpenPosition(ctx, next)
+	}
+
+	if pos == nil {
+		return nil
+	}
+	remaining := pos.Quantity.Sub(quantity)
+	if remaining.IsPositive() && r.Status != "FILLED" {
+		// As taxas da entrada são reduzidas na mesma proporção da quantidade
+		next := *pos
+		next.Quantity = remaining
+		next.Fees = pos.Fees.Mul(remaining).Div(pos.Quantity)
+		return store.UpdatePosition(ctx, next)
+	}
+
+	// O resultado considera a ordem inteira: a posição antes das execuções
+	// anteriores da ordem, que reduziram a quantidade e as taxas proporcionalmente
+	sold := order.ExecutedQty
+	entry := *pos
+	if previous := sold.Sub(quantity); previous.IsPositive() && pos.Quantity.IsPositive() {
+		entry.Quantity = pos.Quantity.Add(previous)
+		entry.Fees = pos.Fees.Mul(entry.Quantity).Div(pos.Quantity)
+	}
+	exitPrice := order.CummulativeQuoteQty.Div(sold)
+	exitFees := feeInQuote(order.Commission, order.CommissionAsset, exitPrice, info)
+	return store.ClosePosition(ctx, r.Symbol, cfg.Mode, closeTrade(&entry, exitPrice, sold, exitFees, order.Reason))
+}
+
+// requestReconcile agenda a reconciliação dos pares cujo ativo base teve o saldo
+// alterado, para que depósitos, saques e ordens não informadas sejam refletidos na posição.
+func requestReconcile(ctx context.Context, update *exchange.AccountUpdate) {
+	changed := make(map[string]bool, len(update.Balances))
+	for _, b := range update.Balances {
+		changed[b.Asset] = true
+	}
+	for _, t := range traders {
+		info, err := client.GetExchangeInfo(ctx, t.Symbol)
+		if err != nil {
+			log.Printf("Erro ao obter as regras de %s: %v", t.Symbol, err)
+			continue
+		}
+		if changed[info.BaseAsset] {
+			t.reconcileRequested.Store(true)
+		}
+	}
+}
+
+// findTrader retorna o Trader do par, ou nil se o par não estiver em SYMBOLS.
+func findTrader(symbol string) *Trader {
+	for _, t := range traders {
+		if t.Symbol == symbol {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package trading
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/brunossouza/crypto_bot/internal/config"
+	"github.com/brunossouza/crypto_bot/internal/database"
+	"github.com/brunossouza/crypto_bot/internal/exchange"
+)
+
+// fakeAccount acrescenta a fakeMarket as regras do par BTCUSDT.
+type fakeAccount struct {
+	fakeMarket
+}
+
+func (f *fakeAccount) GetExchangeInfo(ctx context.Context, symbol string) (*exchange.SymbolInfo, error) {
+	return &exchange.SymbolInfo{Symbol: symbol, BaseAsset: "BTC", QuoteAsset: "USDT"}, nil
+}
+
+// setupUserEvents configura o pacote em modo live com o Trader de BTCUSDT.
+func setupUserEvents(t *testing.T) *Trader {
+	t.Helper()
+	tr := setupTrader(t, &fakeAccount{})
+	cfg.Mode = config.ModeLive
+	traders = []*Trader{tr}
+	return tr
+}
+
+// handle processa os eventos informados como se recebidos do user data stream.
+func handle(events ...exchange.UserEvent) {
+	ch := make(chan exchange.UserEvent, len(events))
+	for _, ev := range events {
+		ch <- ev
+	}
+	close(ch)
+	HandleUserEvents(context.Background(), ch)
+}
+
+// report monta um executionReport da ordem orderID com a execução acumulada executed (valor quote).
+func report(orderID int64, side, execType, status, executed, quote, last, price, commission string) exchange.UserEvent {
+	r := &exchange.ExecutionReport{
+		Symbol:              "BTCUSDT",
+		OrderID:             orderID,
+		ClientOrderID:       "web_1",
+		Side:                side,
+		Type:                exchange.OrderTypeMarket,
+		ExecutionType:       execType,
+		Status:              status,
+		OrigQty:             dec("0.002"),
+		ExecutedQty:         dec(executed),
+		CummulativeQuoteQty: dec(quote),
+		LastQty:             dec(last),
+		LastPrice:           dec(price),
+		Commission:          dec(commission),
+		TradeID:             -1,
+	}
+	if execType == exchange.ExecutionTrade {
+		r.CommissionAsset = "USDT"
+		r.TradeID = orderID * 10
+	}
+	return exchange.UserEvent{Execution: r}
+}
+
+func TestHandleUserEvents_ExternalOrders(t *testing.T) {
+	setupUserEvents(t)
+	ctx := context.Background()
+
+	// Compra manual executada em duas partes
+	handle(
+		report(1, exchange.SideBuy, exchange.ExecutionNew, "NEW", "0", "0", "0", "0", "0"),
+		report(1, exchange.SideBuy, exchange.ExecutionTrade, "PARTIALLY_FILLED", "0.001", "60", "0.001", "60000", "0.06"),
+		report(1, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "124", "0.001", "64000", "0.064"),
+	)
+
+	order, err := store.GetOrderByExchangeID(ctx, "BTCUSDT", config.ModeLive, 1)
+	if err != nil || order == nil {
+		t.Fatalf("compra manual não registrada: %+v, erro: %v", order, err)
+	}
+	if order.Reason != externalReason || order.Status != "FILLED" || !order.ExecutedQty.Equal(dec("0.002")) ||
+		!order.Price.Equal(dec("62000")) || !order.Commission.Equal(dec("0.124")) {
+		t.Errorf("compra manual registrada incorretamente: %+v", order)
+	}
+	pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil {
+		t.Fatalf("posição não aberta pela compra manual: %+v, erro: %v", pos, err)
+	}
+	if !pos.Quantity.Equal(dec("0.002")) || !pos.EntryPrice.Equal(dec("62000")) || !pos.Fees.Equal(dec("0.124")) {
+		t.Errorf("posição aberta incorretamente: %+v", pos)
+	}
+
+	// Venda manual encerra a posição e registra o resultado
+	handle(
+		report(2, exchange.SideSell, exchange.ExecutionNew, "NEW", "0", "0", "0", "0", "0"),
+		report(2, exchange.SideSell, exchange.ExecutionTrade, "FILLED", "0.002", "130", "0.002", "65000", "0.13"),
+	)
+	if opened, _ := store.GetPosition(ctx, "BTCUSDT", config.ModeLive); opened {
+		t.Error("posição continua aberta após a venda manual")
+	}
+	pnl, count, err := store.GetRealizedPnL(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || count != 1 || !pnl.Equal(dec("5.746")) {
+		t.Errorf("resultado realizado = %s em %d operações, esperado 5.746 em 1 (erro: %v)", pnl, count, err)
+	}
+}
+
+func TestHandleUserEvents_RecordedOrders(t *testing.T) {
+	tr := setupUserEvents(t)
+	ctx := context.Background()
+
+	// Compra do bot já registrada com o resultado final pela resposta da corretora
+	if err := store.SaveOrder(ctx, database.Order{
+		Symbol: "BTCUSDT", Side: exchange.SideBuy, Quantity: dec("0.002"), Price: dec("62000"), Mode: config.ModeLive,
+		Reason: "strategy", ExchangeOrderID: 3, Status: "FILLED", ExecutedQty: dec("0.002"), CummulativeQuoteQty: dec("124"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.OpenPosition(ctx, database.Position{Symbol: "BTCUSDT", Mode: config.ModeLive, EntryPrice: dec("62000"), Quantity: dec("0.002")}); err != nil {
+		t.Fatal(err)
+	}
+
+	handle(
+		report(3, exchange.SideBuy, exchange.ExecutionNew, "NEW", "0", "0", "0", "0", "0"),
+		report(3, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "124", "0.002", "62000", "0.124"),
+		// Ordem limitada do bot cancelada sem execução
+		report(4, exchange.SideSell, exchange.ExecutionCanceled, "CANCELED", "0", "0", "0", "0", "0"),
+		exchange.UserEvent{Account: &exchange.AccountUpdate{Balances: []exchange.Balance{{Asset: "BTC", Free: dec("0.002")}}}},
+	)
+
+	order, err := store.GetOrderByExchangeID(ctx, "BTCUSDT", config.ModeLive, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order.Reason != "strategy" || order.Status != "FILLED" || !order.ExecutedQty.Equal(dec("0.002")) {
+		t.Errorf("ordem registrada alterada por eventos já refletidos: %+v", order)
+	}
+	pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil || !pos.Quantity.Equal(dec("0.002")) {
+		t.Errorf("posição alterada por eventos já refletidos: %+v, erro: %v", pos, err)
+	}
+	canceled, err := store.GetOrderByExchangeID(ctx, "BTCUSDT", config.ModeLive, 4)
+	if err != nil || canceled == nil || canceled.Status != "CANCELED" || !canceled.ExecutedQty.IsZero() {
+		t.Errorf("cancelamento registrado incorretamente: %+v, erro: %v", canceled, err)
+	}
+	if !tr.reconcileRequested.Load() {
+		t.Error("alteração do saldo de BTC não agendou a reconciliação")
+	}
+}
+
+func TestHandleUserEvents_PartialSell(t *testing.T) {
+	setupUserEvents(t)
+	ctx := context.Background()
+
+	if err := store.OpenPosition(ctx, database.Position{
+		Symbol: "BTCUSDT", Mode: config.ModeLive, EntryPrice: dec("60000"), Quantity: dec("0.002"), Fees: dec("0.12"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Primeira execução de uma venda manual reduz a posição sem encerrá-la
+	handle(report(6, exchange.SideSell, exchange.ExecutionTrade, "PARTIALLY_FILLED", "0.001", "65", "0.001", "65000", "0.065"))
+	pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil {
+		t.Fatalf("posição encerrada pela venda parcial: %+v, erro: %v", pos, err)
+	}
+	if !pos.Quantity.Equal(dec("0.001")) || !pos.Fees.Equal(dec("0.06")) || !pos.EntryPrice.Equal(dec("60000")) {
+		t.Errorf("posição reduzida incorretamente: %+v", pos)
+	}
+	if _, count, _ := store.GetRealizedPnL(ctx, "BTCUSDT", config.ModeLive); count != 0 {
+		t.Errorf("%d operações registradas pela venda parcial, esperado 0", count)
+	}
+
+	// A última execução encerra a posição com o resultado da venda inteira:
+	// 131 - 0.131 de taxas da saída - (120 + 0.12 da entrada)
+	handle(report(6, exchange.SideSell, exchange.ExecutionTrade, "FILLED", "0.002", "131", "0.001", "66000", "0.066"))
+	if opened, _ := store.GetPosition(ctx, "BTCUSDT", config.ModeLive); opened {
+		t.Error("posição continua aberta após a venda total")
+	}
+	pnl, count, err := store.GetRealizedPnL(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || count != 1 || !pnl.Equal(dec("10.749")) {
+		t.Errorf("resultado realizado = %s em %d operações, esperado 10.749 em 1 (erro: %v)", pnl, count, err)
+	}
+}
+
+func TestHandleUserEvents_BuyWithBaseFee(t *testing.T) {
+	setupUserEvents(t)
+	ctx := context.Background()
+
+	// Taxa cobrada no ativo base: o preço de entrada é o bruto, e a taxa fica em Fees
+	buy := func(orderID int64) exchange.UserEvent {
+		ev := report(orderID, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "120", "0.002", "60000", "0.000002")
+		ev.Execution.CommissionAsset = "BTC"
+		return ev
+	}
+
+	handle(buy(7))
+	pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil || !pos.EntryPrice.Equal(dec("60000")) || !pos.Quantity.Equal(dec("0.001998")) || !pos.Fees.Equal(dec("0.12")) {
+		t.Fatalf("posição aberta incorretamente: %+v, erro: %v", pos, err)
+	}
+
+	// A compra que aumenta a posição usa a mesma convenção
+	handle(buy(8))
+	pos, err = store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil || !pos.EntryPrice.Equal(dec("60000")) || !pos.Quantity.Equal(dec("0.003996")) || !pos.Fees.Equal(dec("0.24")) {
+		t.Errorf("posição aumentada incorretamente: %+v, erro: %v", pos, err)
+	}
+}
+
+func TestHandleUserEvents_BuyUpdatesRiskPosition(t *testing.T) {
+	tr := setupUserEvents(t)
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+
+	handle(report(7, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "120", "0.002", "60000", "0"))
+	pos, err := store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil {
+		t.Fatalf("posição não aberta: %+v, erro: %v", pos, err)
+	}
+	if risk := tr.loadRiskPosition(ctx, logger, pos); risk == nil || risk.EntryPrice != 60000 {
+		t.Fatalf("loadRiskPosition() = %+v, esperado o preço de entrada 60000", risk)
+	}
+
+	// A compra que aumenta a posição muda o preço médio de entrada das regras de risco
+	handle(report(8, exchange.SideBuy, exchange.ExecutionTrade, "FILLED", "0.002", "124", "0.002", "62000", "0"))
+	pos, err = store.GetOpenPosition(ctx, "BTCUSDT", config.ModeLive)
+	if err != nil || pos == nil || !pos.EntryPrice.Equal(dec("61000")) {
+		t.Fatalf("posição aumentada incorretamente: %+v, erro: %v", pos, err)
+	}
+	if risk := tr.loadRiskPosition(ctx, logger, pos); risk == nil || risk.EntryPrice != 61000 {
+		t.Errorf("loadRiskPosition() = %+v, esperado o preço médio de entrada 61000", risk)
+	}
+}
@@ -1,24 +1,40 @@
 package utils
 
 import (
-	"log"
+	"fmt"
 	"strconv"
+
+	"github.com/shopspring/decimal"
 )
 
-// parseFloat converte uma string para float64
+// ParseFloat converte uma string para float64
 // Parâmetros:
 // - str: string contendo um número decimal
 //
-// O método:
-// 1. Tenta converter a string para float64
-// 2. Em caso de erro, finaliza o programa
-//
 // Retorna:
 // - float64: valor numérico convertido da string
-func ParseFloat(str string) float64 {
+// - error: erro se a string não representar um número válido
+func ParseFloat(str string) (float64, error) {
 	val, err := strconv.ParseFloat(str, 64)
 	if err != nil {
-		log.Fatal(err)
+		return 0, fmt.Errorf("valor numérico inválido %q: %w", str, err)
+	}
+	return val, nil
+}
+
+// ParseDecimal converte uma string para decimal.Decimal sem perda de precisão
+// Deve ser usada para preços, quantidades e saldos; ParseFloat fica restrita a
+// dados de mercado usados apenas no cálculo de indicadores.
+// Parâmetros:
+// - str: string contendo um número decimal
+//
+// Retorna:
+// - decimal.Decimal: valor exato representado pela string
+// - error: erro se a string não representar um número válido
+func ParseDecimal(str string) (decimal.Decimal, error) {
+	val, err := decimal.NewFromString(str)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("valor numérico inválido %q: %w", str, err)
 	}
-	return val
+	return val, nil
 }
@@ -1,39 +1,38 @@
 package utils
 
 import (
-	"os"
-	"os/exec"
 	"testing"
 )
 
 // TestParseFloat valida a conversão correta de string para float64.
 func TestParseFloat(t *testing.T) {
-	// ...existing code...
-	val := ParseFloat("1.23")
+	val, err := ParseFloat("1.23")
+	if err != nil {
+		t.Fatalf("Erro inesperado: %v", err)
+	}
 	if val != 1.23 {
 		t.Errorf("Esperado 1.23, obteve %f", val)
 	}
 }
 
-// TestParseFloat_Invalid valida que ParseFloat encerra o programa com um input inválido.
+// TestParseFloat_Invalid valida que ParseFloat retorna erro com um input inválido.
 func TestParseFloat_Invalid(t *testing.T) {
-	if os.Getenv("BE_CRASHER") == "1" {
-		// Chama ParseFloat com string inválida para acionar log.Fatal
-		ParseFloat("abc")
-		return
+	if _, err := ParseFloat("abc"); err == nil {
+		t.Fatalf("Esperava-se erro ao converter string inválida")
 	}
-	// Executa este teste em um subprocesso.
-	cmd := exec.Command(os.Args[0], "-test.run=TestParseFloat_Invalid")
-	cmd.Env = append(os.Environ(), "BE_CRASHER=1")
-	err := cmd.Run()
-	if err == nil {
-		t.Fatalf("Esperava-se que o processo terminasse com erro ao converter string inválida")
-	}
-	if exitError, ok := err.(*exec.ExitError); ok {
-		if exitError.ExitCode() == 0 {
-			t.Fatalf("O código de saída não pode ser zero para input inválido")
-		}
-	} else {
+}
+
+// TestParseDecimal valida que ParseDecimal preserva todas as casas decimais.
+func TestParseDecimal(t *testing.T) {
+	val, err := ParseDecimal("0.00012345678901234567")
+	if err != nil {
 		t.Fatalf("Erro inesperado: %v", err)
 	}
+	if val.String() != "0.00012345678901234567" {
+		t.Errorf("Esperado 0.00012345678901234567, obteve %s", val)
+	}
+
+	if _, err := ParseDecimal("abc"); err == nil {
+		t.Fatalf("Esperava-se erro ao converter string inválida")
+	}
 }